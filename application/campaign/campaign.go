@@ -0,0 +1,180 @@
+package campaign
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/campaign"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+const (
+	maxNameLength        = 128
+	maxDescriptionLength = 1024
+)
+
+type CampaignAppImpl struct {
+	CampaignRepository campaign.CampaignRepository
+}
+
+type CampaignApp interface {
+	CreateCampaign(ctx context.Context, req *model.UpsertCampaignRequest) (*model.GetCampaignResponse, error)
+	ListCampaigns(ctx context.Context) ([]*model.GetCampaignResponse, error)
+	GetCampaign(ctx context.Context, id uint64) (*model.GetCampaignResponse, error)
+	UpdateCampaign(ctx context.Context, id uint64, req *model.UpsertCampaignRequest) (*model.GetCampaignResponse, error)
+	DeleteCampaign(ctx context.Context, id uint64) error
+	GetCampaignStats(ctx context.Context, id uint64) (*model.GetCampaignStatsResponse, error)
+}
+
+func NewCampaignApplication(CampaignRepository campaign.CampaignRepository) CampaignApp {
+	return &CampaignAppImpl{
+		CampaignRepository: CampaignRepository,
+	}
+}
+
+func (c *CampaignAppImpl) CreateCampaign(ctx context.Context, req *model.UpsertCampaignRequest) (*model.GetCampaignResponse, error) {
+	if err := c.validateCampaign(ctx, 0, req); err != nil {
+		return nil, err
+	}
+
+	createdCampaign, err := c.CampaignRepository.Create(ctx, &model.CampaignEntity{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		log.Println("[CreateCampaign] err Create", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return toGetCampaignResponse(createdCampaign), nil
+}
+
+func (c *CampaignAppImpl) ListCampaigns(ctx context.Context) ([]*model.GetCampaignResponse, error) {
+	campaigns, err := c.CampaignRepository.List(ctx)
+	if err != nil {
+		log.Println("[ListCampaigns] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := make([]*model.GetCampaignResponse, 0, len(campaigns))
+	for _, campaignEntity := range campaigns {
+		resp = append(resp, toGetCampaignResponse(campaignEntity))
+	}
+
+	return resp, nil
+}
+
+func (c *CampaignAppImpl) GetCampaign(ctx context.Context, id uint64) (*model.GetCampaignResponse, error) {
+	campaignEntity, err := c.getCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGetCampaignResponse(campaignEntity), nil
+}
+
+func (c *CampaignAppImpl) UpdateCampaign(ctx context.Context, id uint64, req *model.UpsertCampaignRequest) (*model.GetCampaignResponse, error) {
+	campaignEntity, err := c.getCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.validateCampaign(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	campaignEntity.Name = req.Name
+	campaignEntity.Description = req.Description
+
+	updatedCampaign, err := c.CampaignRepository.Update(ctx, campaignEntity)
+	if err != nil {
+		log.Println("[UpdateCampaign] err Update", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return toGetCampaignResponse(updatedCampaign), nil
+}
+
+// DeleteCampaign deletes the campaign, its links are kept without a campaign
+func (c *CampaignAppImpl) DeleteCampaign(ctx context.Context, id uint64) error {
+	campaignEntity, err := c.getCampaign(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.CampaignRepository.Delete(ctx, campaignEntity.ID); err != nil {
+		log.Println("[DeleteCampaign] err Delete", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return nil
+}
+
+// GetCampaignStats aggregates the links and clicks of every link of the campaign
+func (c *CampaignAppImpl) GetCampaignStats(ctx context.Context, id uint64) (*model.GetCampaignStatsResponse, error) {
+	campaignEntity, err := c.getCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := c.CampaignRepository.Stats(ctx, campaignEntity.ID)
+	if err != nil {
+		log.Println("[GetCampaignStats] err Stats", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return &model.GetCampaignStatsResponse{
+		ID:          campaignEntity.ID,
+		Name:        campaignEntity.Name,
+		Links:       stats.Links,
+		TotalClicks: stats.TotalClicks,
+	}, nil
+}
+
+func (c *CampaignAppImpl) getCampaign(ctx context.Context, id uint64) (*model.CampaignEntity, error) {
+	campaignEntity, err := c.CampaignRepository.Get(ctx, &model.CampaignFilter{ID: id})
+	if err != nil {
+		log.Println("[getCampaign] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if campaignEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	return campaignEntity, nil
+}
+
+// validateCampaign trims the request and checks the name is not used by another campaign
+func (c *CampaignAppImpl) validateCampaign(ctx context.Context, id uint64, req *model.UpsertCampaignRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+
+	if req.Name == "" || len(req.Name) > maxNameLength || len(req.Description) > maxDescriptionLength {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	existing, err := c.CampaignRepository.Get(ctx, &model.CampaignFilter{Name: req.Name})
+	if err != nil {
+		log.Println("[validateCampaign] err Get", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+	if existing != nil && existing.ID != id {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	return nil
+}
+
+func toGetCampaignResponse(entity *model.CampaignEntity) *model.GetCampaignResponse {
+	return &model.GetCampaignResponse{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Description: entity.Description,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	neturl "net/url"
+
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/domain"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/utils/domainverify"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+type DomainAppImpl struct {
+	DomainRepository domain.DomainRepository
+	URLRepository    url.URLRepository
+	Verifier         domainverify.Verifier
+	Config           *config.Config
+}
+
+type DomainApp interface {
+	CreateDomain(ctx context.Context, req *model.CreateDomainRequest) (*model.GetDomainResponse, error)
+	ListDomains(ctx context.Context) ([]*model.GetDomainResponse, error)
+	GetDomain(ctx context.Context, id uint64) (*model.GetDomainResponse, error)
+	VerifyDomain(ctx context.Context, id uint64) (*model.GetDomainResponse, error)
+	DeleteDomain(ctx context.Context, id uint64) error
+}
+
+func NewDomainApplication(DomainRepository domain.DomainRepository, URLRepository url.URLRepository, cfg *config.Config) DomainApp {
+	return &DomainAppImpl{
+		DomainRepository: DomainRepository,
+		URLRepository:    URLRepository,
+		Verifier:         domainverify.NewVerifier(cfg.Server.DomainVerifyScheme, cfg.Server.DomainVerifyTimeout, cfg.Server.AllowPrivateDestinations),
+		Config:           cfg,
+	}
+}
+
+func (d *DomainAppImpl) CreateDomain(ctx context.Context, req *model.CreateDomainRequest) (*model.GetDomainResponse, error) {
+	host := domainverify.NormalizeHost(req.Host)
+	if parsed, err := neturl.Parse("//" + host); err != nil || parsed.Host != host || parsed.Hostname() == "" {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	existing, err := d.DomainRepository.Get(ctx, &model.DomainFilter{Host: host})
+	if err != nil {
+		log.Println("[CreateDomain] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+	if existing != nil {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		log.Println("[CreateDomain] err Read", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	createdDomain, err := d.DomainRepository.Create(ctx, &model.DomainEntity{
+		UserID:            0, // You might want to get this from context or auth
+		Host:              host,
+		VerificationToken: hex.EncodeToString(token),
+	})
+	if err != nil {
+		log.Println("[CreateDomain] err Create", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return d.toGetDomainResponse(createdDomain), nil
+}
+
+func (d *DomainAppImpl) ListDomains(ctx context.Context) ([]*model.GetDomainResponse, error) {
+	domains, err := d.DomainRepository.List(ctx)
+	if err != nil {
+		log.Println("[ListDomains] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := make([]*model.GetDomainResponse, 0, len(domains))
+	for _, domain := range domains {
+		resp = append(resp, d.toGetDomainResponse(domain))
+	}
+
+	return resp, nil
+}
+
+func (d *DomainAppImpl) GetDomain(ctx context.Context, id uint64) (*model.GetDomainResponse, error) {
+	domainEntity, err := d.getDomain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.toGetDomainResponse(domainEntity), nil
+}
+
+// VerifyDomain fetches the token served on the domain, links are only served on verified domains
+func (d *DomainAppImpl) VerifyDomain(ctx context.Context, id uint64) (*model.GetDomainResponse, error) {
+	domainEntity, err := d.getDomain(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if domainEntity.VerifiedAt != nil {
+		return d.toGetDomainResponse(domainEntity), nil
+	}
+
+	if err := d.Verifier.Verify(ctx, domainEntity.Host, domainEntity.VerificationToken); err != nil {
+		log.Println("[VerifyDomain] err Verify", domainEntity.Host, err)
+		return nil, errors.SetCustomError(constant.ErrVerificationFailed)
+	}
+
+	if err := d.DomainRepository.Verify(ctx, domainEntity.ID); err != nil {
+		log.Println("[VerifyDomain] err Verify", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return d.GetDomain(ctx, domainEntity.ID)
+}
+
+// DeleteDomain deletes a domain without links, links keep their domain so it can't go away under them
+func (d *DomainAppImpl) DeleteDomain(ctx context.Context, id uint64) error {
+	domainEntity, err := d.getDomain(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	link, err := d.URLRepository.Get(ctx, &model.URLFilter{DomainID: &domainEntity.ID})
+	if err != nil {
+		log.Println("[DeleteDomain] err Get", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+	if link != nil {
+		return errors.SetCustomError(constant.ErrConflict)
+	}
+
+	if err := d.DomainRepository.Delete(ctx, domainEntity.ID); err != nil {
+		if errors.Is(err, constant.ErrConflict) {
+			return err
+		}
+		log.Println("[DeleteDomain] err Delete", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return nil
+}
+
+func (d *DomainAppImpl) getDomain(ctx context.Context, id uint64) (*model.DomainEntity, error) {
+	domainEntity, err := d.DomainRepository.Get(ctx, &model.DomainFilter{
+		ID: id,
+	})
+	if err != nil {
+		log.Println("[getDomain] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if domainEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	return domainEntity, nil
+}
+
+// toGetDomainResponse only shows the verification token until the domain is verified
+func (d *DomainAppImpl) toGetDomainResponse(entity *model.DomainEntity) *model.GetDomainResponse {
+	resp := &model.GetDomainResponse{
+		ID:         entity.ID,
+		Host:       entity.Host,
+		Verified:   entity.VerifiedAt != nil,
+		VerifiedAt: entity.VerifiedAt,
+		CreatedAt:  entity.CreatedAt,
+	}
+	if !resp.Verified {
+		resp.VerificationToken = entity.VerificationToken
+		resp.VerificationURL = domainverify.VerificationURL(d.Config.Server.DomainVerifyScheme, entity.Host)
+	}
+	return resp
+}
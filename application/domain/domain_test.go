@@ -0,0 +1,72 @@
+package domain_test
+
+import (
+	"context"
+	"testing"
+
+	appdomain "github.com/muhammadheryan/url-shortner-base62/application/domain"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	domainmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/domain"
+	urlmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	cerr "github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDomainApp_DeleteDomain(t *testing.T) {
+	tests := []struct {
+		name        string
+		link        *model.URLEntity
+		deleteErr   error
+		wantDelete  bool
+		wantErr     bool
+		wantErrType constant.ErrorType
+	}{
+		{
+			name:       "success: domain without links is deleted",
+			wantDelete: true,
+		},
+		{
+			name:        "error: domain still has links -> ErrConflict",
+			link:        &model.URLEntity{ID: 12, ShortURL: "0000C"},
+			wantErr:     true,
+			wantErrType: constant.ErrConflict,
+		},
+		{
+			name:        "error: link created on the domain meanwhile -> ErrConflict",
+			deleteErr:   cerr.SetCustomError(constant.ErrConflict),
+			wantDelete:  true,
+			wantErr:     true,
+			wantErrType: constant.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domainRepo := domainmocks.NewDomainRepository(t)
+			domainRepo.
+				On("Get", mock.Anything, &model.DomainFilter{ID: 3}).
+				Return(&model.DomainEntity{ID: 3, Host: "go.example.com"}, nil)
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.
+				On("Get", mock.Anything, mock.MatchedBy(func(f *model.URLFilter) bool {
+					return f.DomainID != nil && *f.DomainID == 3 && f.ShortURL == "" && f.ID == 0
+				})).
+				Return(tt.link, nil)
+			if tt.wantDelete {
+				domainRepo.On("Delete", mock.Anything, uint64(3)).Return(tt.deleteErr)
+			}
+
+			app := appdomain.NewDomainApplication(domainRepo, urlRepo, &config.Config{})
+			err := app.DeleteDomain(context.Background(), 3)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteDomain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !cerr.Is(err, tt.wantErrType) {
+				t.Fatalf("DeleteDomain() error = %v, want %v", err, tt.wantErrType)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/nats"
+)
+
+// LogPublisher writes every event to the service log
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, payload *model.EventPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	log.Println("[outbox]", payload.Event, string(body))
+	return nil
+}
+
+// NATSPublisher sends every event on the subject {SubjectPrefix}.{event}, such as
+// url-shortner.link.clicked, with the same JSON body as the webhooks
+type NATSPublisher struct {
+	Client        nats.Publisher
+	SubjectPrefix string
+}
+
+func NewNATSPublisher(Client nats.Publisher, SubjectPrefix string) *NATSPublisher {
+	return &NATSPublisher{
+		Client:        Client,
+		SubjectPrefix: SubjectPrefix,
+	}
+}
+
+// Publish uses the event id as message id so JetStream can drop an event published twice
+func (p *NATSPublisher) Publish(ctx context.Context, payload *model.EventPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return p.Client.Publish(ctx, p.SubjectPrefix+"."+payload.Event, payload.ID, body)
+}
@@ -0,0 +1,175 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/outbox"
+)
+
+// maxRetryDelay caps the delay before a failed event is published again
+const maxRetryDelay = time.Hour
+
+// maxErrorLength keeps the last error of an event short
+const maxErrorLength = 1024
+
+// Publisher hands the events read from the outbox to a consumer outside of the service
+type Publisher interface {
+	Publish(ctx context.Context, payload *model.EventPayload) error
+}
+
+// EventFilter is implemented by the publishers only taking some of the events, the
+// other publishers take every event
+type EventFilter interface {
+	Wants(ctx context.Context, event string) bool
+}
+
+// Relay publishes the events written to the outbox, oldest first. An event is removed
+// once every publisher accepted it, so publishers get each event at least once even
+// when the service stops between a change and its publication. A failing event is
+// retried with backoff without holding back the others, then left aside once it used
+// its OutboxMaxAttempts.
+type Relay struct {
+	OutboxRepository outbox.OutboxRepository
+	Publishers       []Publisher
+	Config           *config.Config
+	Now              func() time.Time
+}
+
+func NewRelay(OutboxRepository outbox.OutboxRepository, Publishers []Publisher, cfg *config.Config) *Relay {
+	return &Relay{
+		OutboxRepository: OutboxRepository,
+		Publishers:       Publishers,
+		Config:           cfg,
+		Now:              time.Now,
+	}
+}
+
+// Run publishes the pending events until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Config.Server.OutboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// keep going while full batches are claimed so a backlog drains quickly
+		for {
+			claimed, err := r.PublishPending(ctx)
+			if err != nil {
+				log.Println("[Run] err PublishPending", err)
+				break
+			}
+			if claimed < r.Config.Server.OutboxBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// Wants tells if any publisher takes the event, the changes nobody takes don't need to write it
+func (r *Relay) Wants(ctx context.Context, event string) bool {
+	for _, publisher := range r.Publishers {
+		filter, ok := publisher.(EventFilter)
+		if !ok || filter.Wants(ctx, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishPending publishes the oldest pending events in order and returns how many were claimed.
+// An event failing is published again after its retry delay while the batch goes on, the
+// errors returned are the ones of the outbox itself.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	now := r.Now()
+	events, err := r.OutboxRepository.Claim(ctx, now, now.Add(r.Config.Server.OutboxRetryDelay), r.Config.Server.OutboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := r.publish(ctx, event); err != nil {
+			log.Println("[PublishPending] err publish", event.EventID, err)
+			if err := r.retry(ctx, event, err, now); err != nil {
+				return len(events), err
+			}
+			continue
+		}
+
+		if err := r.OutboxRepository.Delete(ctx, event.ID); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+// publish hands the event to every publisher, stopping at the first failing
+func (r *Relay) publish(ctx context.Context, event *model.OutboxEntity) error {
+	payload, err := r.toPayload(event)
+	if err != nil {
+		return err
+	}
+
+	for _, publisher := range r.Publishers {
+		if err := publisher.Publish(ctx, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retry records the failed attempt, the event is published again after the retry delay
+// doubled on every attempt or left aside once it used its attempts
+func (r *Relay) retry(ctx context.Context, event *model.OutboxEntity, cause error, now time.Time) error {
+	event.Attempts++
+	event.LastError = cause.Error()
+	if len(event.LastError) > maxErrorLength {
+		event.LastError = event.LastError[:maxErrorLength]
+	}
+
+	if event.Attempts >= r.Config.Server.OutboxMaxAttempts {
+		log.Println("[retry] giving up on event", event.EventID, "after", event.Attempts, "attempts")
+		return r.OutboxRepository.Fail(ctx, event)
+	}
+	return r.OutboxRepository.Retry(ctx, event, now.Add(r.retryDelay(event.Attempts)))
+}
+
+// retryDelay is OutboxRetryDelay doubled on every attempt after the first, up to maxRetryDelay
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	delay := r.Config.Server.OutboxRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// toPayload completes the stored link data with the absolute short link
+func (r *Relay) toPayload(event *model.OutboxEntity) (*model.EventPayload, error) {
+	var data model.LinkEventData
+	if err := json.Unmarshal([]byte(event.Payload), &data); err != nil {
+		return nil, err
+	}
+	if base := r.Config.BaseURL(data.Domain); base != "" {
+		data.ShortLink = base + "/url/" + data.ShortURL
+	}
+
+	return &model.EventPayload{
+		ID:        event.EventID,
+		Event:     event.Event,
+		CreatedAt: event.CreatedAt.UTC(),
+		Data:      &data,
+	}, nil
+}
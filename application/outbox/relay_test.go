@@ -0,0 +1,270 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	appoutbox "github.com/muhammadheryan/url-shortner-base62/application/outbox"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	outboxmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/outbox"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/nats"
+	"github.com/nats-io/nats-server/v2/server"
+	gonats "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/mock"
+)
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			PublicBaseURL:      "https://sho.rt",
+			OutboxPollInterval: time.Second,
+			OutboxBatchSize:    10,
+			OutboxRetryDelay:   30 * time.Second,
+			OutboxMaxAttempts:  3,
+		},
+	}
+}
+
+func newRelay(outboxRepo *outboxmocks.OutboxRepository, publishers ...appoutbox.Publisher) *appoutbox.Relay {
+	relay := appoutbox.NewRelay(outboxRepo, publishers, testConfig())
+	relay.Now = func() time.Time { return now }
+	return relay
+}
+
+// recordingPublisher keeps the published events and fails on the ids in fail
+type recordingPublisher struct {
+	payloads []*model.EventPayload
+	fail     map[string]bool
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, payload *model.EventPayload) error {
+	if p.fail[payload.ID] {
+		return errors.New("broker down")
+	}
+	p.payloads = append(p.payloads, payload)
+	return nil
+}
+
+func (p *recordingPublisher) ids() []string {
+	ids := make([]string, 0, len(p.payloads))
+	for _, payload := range p.payloads {
+		ids = append(ids, payload.ID)
+	}
+	return ids
+}
+
+func outboxEvents() []*model.OutboxEntity {
+	return []*model.OutboxEntity{
+		{ID: 1, EventID: "evt-1", Event: constant.EventLinkCreated, URLID: 7, Payload: `{"short_url":"00007","original_url":"https://example.com","click_count":0}`, CreatedAt: now},
+		{ID: 2, EventID: "evt-2", Event: constant.EventLinkClicked, URLID: 7, Payload: `{"short_url":"00007","original_url":"https://example.com","click_count":1,"variant_id":3}`, CreatedAt: now},
+		{ID: 3, EventID: "evt-3", Event: constant.EventLinkClicked, URLID: 8, Payload: `{"short_url":"00008","original_url":"https://example.org","domain":"go.example.com","click_count":5}`, CreatedAt: now},
+	}
+}
+
+func TestRelay_PublishPending(t *testing.T) {
+	t.Run("every publisher gets the events in order before they are removed", func(t *testing.T) {
+		outboxRepo := outboxmocks.NewOutboxRepository(t)
+		outboxRepo.On("Claim", mock.Anything, now, now.Add(30*time.Second), 10).Return(outboxEvents(), nil).Once()
+		for _, id := range []uint64{1, 2, 3} {
+			outboxRepo.On("Delete", mock.Anything, id).Return(nil).Once()
+		}
+		first, second := &recordingPublisher{}, &recordingPublisher{}
+
+		claimed, err := newRelay(outboxRepo, first, second).PublishPending(context.Background())
+		if err != nil || claimed != 3 {
+			t.Fatalf("PublishPending() = %d, %v, want 3", claimed, err)
+		}
+		want := []string{"evt-1", "evt-2", "evt-3"}
+		if !reflect.DeepEqual(first.ids(), want) || !reflect.DeepEqual(second.ids(), want) {
+			t.Fatalf("published %v and %v, want %v", first.ids(), second.ids(), want)
+		}
+
+		clicked := first.payloads[1]
+		if clicked.Event != constant.EventLinkClicked || !clicked.CreatedAt.Equal(now) || clicked.Data.ClickCount != 1 || clicked.Data.VariantID != 3 {
+			t.Fatalf("click payload = %+v %+v", clicked, clicked.Data)
+		}
+		if clicked.Data.ShortLink != "https://sho.rt/url/00007" {
+			t.Fatalf("ShortLink = %q, want the link on the default domain", clicked.Data.ShortLink)
+		}
+		if got := first.payloads[2].Data.ShortLink; got != "https://go.example.com/url/00008" {
+			t.Fatalf("ShortLink = %q, want the link on its custom domain", got)
+		}
+	})
+
+	t.Run("a failing event is retried later without holding back the others", func(t *testing.T) {
+		outboxRepo := outboxmocks.NewOutboxRepository(t)
+		outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(outboxEvents(), nil).Once()
+		// mock fails on deleting event 2
+		outboxRepo.On("Delete", mock.Anything, uint64(1)).Return(nil).Once()
+		outboxRepo.On("Delete", mock.Anything, uint64(3)).Return(nil).Once()
+		outboxRepo.On("Retry", mock.Anything, mock.MatchedBy(func(e *model.OutboxEntity) bool {
+			return e.EventID == "evt-2" && e.Attempts == 1 && e.LastError == "broker down"
+		}), now.Add(30*time.Second)).Return(nil).Once()
+		first := &recordingPublisher{}
+		second := &recordingPublisher{fail: map[string]bool{"evt-2": true}}
+
+		claimed, err := newRelay(outboxRepo, first, second).PublishPending(context.Background())
+		if err != nil || claimed != 3 {
+			t.Fatalf("PublishPending() = %d, %v, want 3", claimed, err)
+		}
+		// the first publisher gets event 2 again on the retry, so events are delivered at least once
+		if !reflect.DeepEqual(first.ids(), []string{"evt-1", "evt-2", "evt-3"}) || !reflect.DeepEqual(second.ids(), []string{"evt-1", "evt-3"}) {
+			t.Fatalf("published %v and %v", first.ids(), second.ids())
+		}
+	})
+
+	t.Run("the retry delay doubles on every attempt", func(t *testing.T) {
+		event := outboxEvents()[1]
+		event.Attempts = 1
+		outboxRepo := outboxmocks.NewOutboxRepository(t)
+		outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*model.OutboxEntity{event}, nil).Once()
+		outboxRepo.On("Retry", mock.Anything, event, now.Add(time.Minute)).Return(nil).Once()
+
+		if _, err := newRelay(outboxRepo, &recordingPublisher{fail: map[string]bool{"evt-2": true}}).PublishPending(context.Background()); err != nil {
+			t.Fatalf("PublishPending() error = %v", err)
+		}
+		if event.Attempts != 2 {
+			t.Fatalf("Attempts = %d, want 2", event.Attempts)
+		}
+	})
+
+	t.Run("an event is left aside after its last attempt", func(t *testing.T) {
+		broken := outboxEvents()[0]
+		broken.Payload = "{"
+		broken.Attempts = 2
+		outboxRepo := outboxmocks.NewOutboxRepository(t)
+		outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*model.OutboxEntity{broken}, nil).Once()
+		outboxRepo.On("Fail", mock.Anything, mock.MatchedBy(func(e *model.OutboxEntity) bool {
+			return e.ID == 1 && e.Attempts == 3 && e.LastError != ""
+		})).Return(nil).Once()
+		publisher := &recordingPublisher{}
+
+		if _, err := newRelay(outboxRepo, publisher).PublishPending(context.Background()); err != nil {
+			t.Fatalf("PublishPending() error = %v", err)
+		}
+		if len(publisher.payloads) != 0 {
+			t.Fatalf("published %v, want nothing", publisher.ids())
+		}
+	})
+
+	t.Run("an outbox error stops the batch", func(t *testing.T) {
+		outboxRepo := outboxmocks.NewOutboxRepository(t)
+		outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(outboxEvents(), nil).Once()
+		// mock fails on deleting event 3, the batch stops at the failed retry
+		outboxRepo.On("Delete", mock.Anything, uint64(1)).Return(nil).Once()
+		outboxRepo.On("Retry", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down")).Once()
+		publisher := &recordingPublisher{fail: map[string]bool{"evt-2": true}}
+
+		if _, err := newRelay(outboxRepo, publisher).PublishPending(context.Background()); err == nil {
+			t.Fatalf("PublishPending() error = nil, want the outbox error")
+		}
+		if !reflect.DeepEqual(publisher.ids(), []string{"evt-1"}) {
+			t.Fatalf("published %v", publisher.ids())
+		}
+	})
+
+	t.Run("nothing pending", func(t *testing.T) {
+		outboxRepo := outboxmocks.NewOutboxRepository(t)
+		outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+		claimed, err := newRelay(outboxRepo, &recordingPublisher{}).PublishPending(context.Background())
+		if err != nil || claimed != 0 {
+			t.Fatalf("PublishPending() = %d, %v, want 0", claimed, err)
+		}
+	})
+}
+
+// clickFilter is a publisher taking every event but clicks
+type clickFilter struct {
+	recordingPublisher
+}
+
+func (clickFilter) Wants(_ context.Context, event string) bool {
+	return event != constant.EventLinkClicked
+}
+
+func TestRelay_Wants(t *testing.T) {
+	tests := []struct {
+		name       string
+		publishers []appoutbox.Publisher
+		want       bool
+	}{
+		{name: "no publisher", publishers: nil, want: false},
+		{name: "only publishers filtering clicks out", publishers: []appoutbox.Publisher{&clickFilter{}}, want: false},
+		{name: "a publisher taking every event", publishers: []appoutbox.Publisher{&clickFilter{}, &recordingPublisher{}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRelay(outboxmocks.NewOutboxRepository(t), tt.publishers...)
+			if got := relay.Wants(context.Background(), constant.EventLinkClicked); got != tt.want {
+				t.Fatalf("Wants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatalf("NATS server not ready")
+	}
+	defer srv.Shutdown()
+
+	subscriber, err := gonats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer subscriber.Close()
+	sub, err := subscriber.SubscribeSync("url-shortner.link.*")
+	if err != nil {
+		t.Fatalf("SubscribeSync() error = %v", err)
+	}
+	if err := subscriber.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	client, err := nats.NewClient(srv.ClientURL(), time.Second)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	outboxRepo := outboxmocks.NewOutboxRepository(t)
+	outboxRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(outboxEvents()[:2], nil).Once()
+	outboxRepo.On("Delete", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	relay := newRelay(outboxRepo, appoutbox.NewNATSPublisher(client, "url-shortner"))
+	if _, err := relay.PublishPending(context.Background()); err != nil {
+		t.Fatalf("PublishPending() error = %v", err)
+	}
+
+	for _, want := range []struct{ subject, id string }{
+		{"url-shortner.link.created", "evt-1"},
+		{"url-shortner.link.clicked", "evt-2"},
+	} {
+		msg, err := sub.NextMsg(time.Second)
+		if err != nil {
+			t.Fatalf("NextMsg() error = %v", err)
+		}
+		var payload model.EventPayload
+		if err := json.Unmarshal(msg.Data, &payload); err != nil {
+			t.Fatalf("message body = %s, %v", msg.Data, err)
+		}
+		if msg.Subject != want.subject || msg.Header.Get(gonats.MsgIdHdr) != want.id || payload.ID != want.id || payload.Data.ShortURL != "00007" {
+			t.Fatalf("message %s %s = %+v, want %s %s", msg.Subject, msg.Header.Get(gonats.MsgIdHdr), payload, want.subject, want.id)
+		}
+	}
+}
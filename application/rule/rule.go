@@ -0,0 +1,239 @@
+package rule
+
+import (
+	"context"
+	"log"
+	neturl "net/url"
+	"strings"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/domain"
+	"github.com/muhammadheryan/url-shortner-base62/repository/rule"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/utils/domainverify"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/muhammadheryan/url-shortner-base62/utils/useragent"
+)
+
+type RuleAppImpl struct {
+	URLRepository    url.URLRepository
+	RuleRepository   rule.RuleRepository
+	DomainRepository domain.DomainRepository
+}
+
+type RuleApp interface {
+	CreateRule(ctx context.Context, host, shortURL string, req *model.UpsertRuleRequest) (*model.GetRuleResponse, error)
+	ListRules(ctx context.Context, host, shortURL string) ([]*model.GetRuleResponse, error)
+	UpdateRule(ctx context.Context, host, shortURL string, ruleID uint64, req *model.UpsertRuleRequest) (*model.GetRuleResponse, error)
+	DeleteRule(ctx context.Context, host, shortURL string, ruleID uint64) error
+}
+
+var deviceTypes = map[string]bool{
+	useragent.DeviceDesktop: true,
+	useragent.DeviceMobile:  true,
+	useragent.DeviceTablet:  true,
+	useragent.DeviceBot:     true,
+}
+
+var operatingSystems = map[string]bool{
+	useragent.OSIOS:      true,
+	useragent.OSAndroid:  true,
+	useragent.OSWindows:  true,
+	useragent.OSMacOS:    true,
+	useragent.OSLinux:    true,
+	useragent.OSChromeOS: true,
+}
+
+func NewRuleApplication(URLRepository url.URLRepository, RuleRepository rule.RuleRepository, DomainRepository domain.DomainRepository) RuleApp {
+	return &RuleAppImpl{
+		URLRepository:    URLRepository,
+		RuleRepository:   RuleRepository,
+		DomainRepository: DomainRepository,
+	}
+}
+
+func (r *RuleAppImpl) CreateRule(ctx context.Context, host, shortURL string, req *model.UpsertRuleRequest) (*model.GetRuleResponse, error) {
+	urlEntity, err := r.getURL(ctx, host, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateRule(req); err != nil {
+		return nil, err
+	}
+
+	createdRule, err := r.RuleRepository.Create(ctx, &model.RuleEntity{
+		URLID:          urlEntity.ID,
+		Priority:       req.Priority,
+		Country:        req.Country,
+		DeviceType:     req.DeviceType,
+		OS:             req.OS,
+		Language:       req.Language,
+		DestinationURL: req.DestinationURL,
+	})
+	if err != nil {
+		log.Println("[CreateRule] err Create", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return toGetRuleResponse(createdRule), nil
+}
+
+func (r *RuleAppImpl) ListRules(ctx context.Context, host, shortURL string) ([]*model.GetRuleResponse, error) {
+	urlEntity, err := r.getURL(ctx, host, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := r.RuleRepository.List(ctx, urlEntity.ID)
+	if err != nil {
+		log.Println("[ListRules] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := make([]*model.GetRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		resp = append(resp, toGetRuleResponse(rule))
+	}
+
+	return resp, nil
+}
+
+func (r *RuleAppImpl) UpdateRule(ctx context.Context, host, shortURL string, ruleID uint64, req *model.UpsertRuleRequest) (*model.GetRuleResponse, error) {
+	ruleEntity, err := r.getRule(ctx, host, shortURL, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateRule(req); err != nil {
+		return nil, err
+	}
+
+	ruleEntity.Priority = req.Priority
+	ruleEntity.Country = req.Country
+	ruleEntity.DeviceType = req.DeviceType
+	ruleEntity.OS = req.OS
+	ruleEntity.Language = req.Language
+	ruleEntity.DestinationURL = req.DestinationURL
+
+	updatedRule, err := r.RuleRepository.Update(ctx, ruleEntity)
+	if err != nil {
+		log.Println("[UpdateRule] err Update", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return toGetRuleResponse(updatedRule), nil
+}
+
+func (r *RuleAppImpl) DeleteRule(ctx context.Context, host, shortURL string, ruleID uint64) error {
+	ruleEntity, err := r.getRule(ctx, host, shortURL, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.RuleRepository.Delete(ctx, ruleEntity.ID); err != nil {
+		log.Println("[DeleteRule] err Delete", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return nil
+}
+
+// getURL returns the link with the short url on the verified custom domain matching host,
+// or on the default domain for any other host
+func (r *RuleAppImpl) getURL(ctx context.Context, host, shortURL string) (*model.URLEntity, error) {
+	var domainID uint64
+	if host != "" {
+		domainEntity, err := r.DomainRepository.Get(ctx, &model.DomainFilter{
+			Host: domainverify.NormalizeHost(host),
+		})
+		if err != nil {
+			log.Println("[getURL] err Get domain", err)
+			return nil, errors.SetCustomError(constant.ErrInternal)
+		}
+		if domainEntity != nil && domainEntity.VerifiedAt != nil {
+			domainID = domainEntity.ID
+		}
+	}
+
+	urlEntity, err := r.URLRepository.Get(ctx, &model.URLFilter{
+		ShortURL: shortURL,
+		DomainID: &domainID,
+	})
+	if err != nil {
+		log.Println("[getURL] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if urlEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	return urlEntity, nil
+}
+
+// getRule returns the rule only when it belongs to the short url
+func (r *RuleAppImpl) getRule(ctx context.Context, host, shortURL string, ruleID uint64) (*model.RuleEntity, error) {
+	urlEntity, err := r.getURL(ctx, host, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleEntity, err := r.RuleRepository.Get(ctx, &model.RuleFilter{
+		ID:    ruleID,
+		URLID: urlEntity.ID,
+	})
+	if err != nil {
+		log.Println("[getRule] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if ruleEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	return ruleEntity, nil
+}
+
+// validateRule normalizes the conditions and checks at least one of them is set
+func validateRule(req *model.UpsertRuleRequest) error {
+	req.Country = strings.ToUpper(strings.TrimSpace(req.Country))
+	req.DeviceType = strings.ToLower(strings.TrimSpace(req.DeviceType))
+	req.OS = strings.ToLower(strings.TrimSpace(req.OS))
+	req.Language = strings.ToLower(strings.TrimSpace(req.Language))
+
+	if req.Country == "" && req.DeviceType == "" && req.OS == "" && req.Language == "" {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+	if req.Country != "" && len(req.Country) != 2 {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+	if req.DeviceType != "" && !deviceTypes[req.DeviceType] {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+	if req.OS != "" && !operatingSystems[req.OS] {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	parsed, err := neturl.ParseRequestURI(req.DestinationURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	return nil
+}
+
+func toGetRuleResponse(entity *model.RuleEntity) *model.GetRuleResponse {
+	return &model.GetRuleResponse{
+		ID:             entity.ID,
+		Priority:       entity.Priority,
+		Country:        entity.Country,
+		DeviceType:     entity.DeviceType,
+		OS:             entity.OS,
+		Language:       entity.Language,
+		DestinationURL: entity.DestinationURL,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,187 @@
+package rule_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apprule "github.com/muhammadheryan/url-shortner-base62/application/rule"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	domainmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/domain"
+	rulemocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/rule"
+	urlmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	cerr "github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/stretchr/testify/mock"
+)
+
+// linkFilter matches the lookup of the short url on the domain
+func linkFilter(shortURL string, domainID uint64) *model.URLFilter {
+	return &model.URLFilter{ShortURL: shortURL, DomainID: &domainID}
+}
+
+func TestRuleApp_CreateRule(t *testing.T) {
+	link := &model.URLEntity{ID: 13, ShortURL: "0000D", OriginalURL: "https://example.com/app"}
+
+	tests := []struct {
+		name        string
+		req         model.UpsertRuleRequest
+		link        *model.URLEntity
+		linkErr     error
+		createErr   error
+		want        *model.RuleEntity
+		wantErr     bool
+		wantErrType constant.ErrorType
+	}{
+		{
+			name: "success: conditions are normalized",
+			req:  model.UpsertRuleRequest{Priority: 1, Country: " id ", DeviceType: "Mobile", OS: " iOS", Language: "EN", DestinationURL: "https://apps.apple.com/app/id1"},
+			link: link,
+			want: &model.RuleEntity{URLID: 13, Priority: 1, Country: "ID", DeviceType: "mobile", OS: "ios", Language: "en", DestinationURL: "https://apps.apple.com/app/id1"},
+		},
+		{
+			name: "success: a single condition is enough",
+			req:  model.UpsertRuleRequest{Language: "id", DestinationURL: "https://example.com/id"},
+			link: link,
+			want: &model.RuleEntity{URLID: 13, Language: "id", DestinationURL: "https://example.com/id"},
+		},
+		{
+			name:        "error: no condition -> ErrInvalidRequest",
+			req:         model.UpsertRuleRequest{DestinationURL: "https://example.com/all"},
+			link:        link,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
+		{
+			name:        "error: country is not a 2 letter code -> ErrInvalidRequest",
+			req:         model.UpsertRuleRequest{Country: "IDN", DestinationURL: "https://example.com/id"},
+			link:        link,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
+		{
+			name:        "error: unknown device type -> ErrInvalidRequest",
+			req:         model.UpsertRuleRequest{DeviceType: "watch", DestinationURL: "https://example.com/watch"},
+			link:        link,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
+		{
+			name:        "error: unknown os -> ErrInvalidRequest",
+			req:         model.UpsertRuleRequest{OS: "symbian", DestinationURL: "https://example.com/old"},
+			link:        link,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
+		{
+			name:        "error: destination is not an http url -> ErrInvalidRequest",
+			req:         model.UpsertRuleRequest{OS: "android", DestinationURL: "market://details?id=app"},
+			link:        link,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
+		{
+			name:        "error: unknown link -> ErrNotFound",
+			req:         model.UpsertRuleRequest{OS: "ios", DestinationURL: "https://apps.apple.com/app/id1"},
+			wantErr:     true,
+			wantErrType: constant.ErrNotFound,
+		},
+		{
+			name:        "error: link lookup fails -> ErrInternal",
+			req:         model.UpsertRuleRequest{OS: "ios", DestinationURL: "https://apps.apple.com/app/id1"},
+			linkErr:     errors.New("db down"),
+			wantErr:     true,
+			wantErrType: constant.ErrInternal,
+		},
+		{
+			name:        "error: rule is not saved -> ErrInternal",
+			req:         model.UpsertRuleRequest{OS: "ios", DestinationURL: "https://apps.apple.com/app/id1"},
+			link:        link,
+			createErr:   errors.New("db down"),
+			want:        &model.RuleEntity{URLID: 13, OS: "ios", DestinationURL: "https://apps.apple.com/app/id1"},
+			wantErr:     true,
+			wantErrType: constant.ErrInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, linkFilter("0000D", 0)).Return(tt.link, tt.linkErr).Once()
+			ruleRepo := rulemocks.NewRuleRepository(t)
+			if tt.want != nil {
+				created := *tt.want
+				created.ID = 7
+				created.CreatedAt = time.Now()
+				ruleRepo.On("Create", mock.Anything, tt.want).Return(&created, tt.createErr).Once()
+			}
+
+			app := apprule.NewRuleApplication(urlRepo, ruleRepo, domainmocks.NewDomainRepository(t))
+			req := tt.req
+			got, err := app.CreateRule(context.Background(), "", "0000D", &req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateRule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !cerr.Is(err, tt.wantErrType) {
+					t.Fatalf("CreateRule() error = %v, want %v", err, tt.wantErrType)
+				}
+				return
+			}
+			if got.ID != 7 || got.Country != tt.want.Country || got.OS != tt.want.OS || got.DestinationURL != tt.want.DestinationURL {
+				t.Fatalf("CreateRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleApp_CustomDomain(t *testing.T) {
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name         string
+		domain       *model.DomainEntity
+		wantDomainID uint64
+	}{
+		{name: "verified domain", domain: &model.DomainEntity{ID: 4, Host: "go.example.com", VerifiedAt: &verifiedAt}, wantDomainID: 4},
+		{name: "unverified domain falls back to the default domain", domain: &model.DomainEntity{ID: 4, Host: "go.example.com"}},
+		{name: "unknown host falls back to the default domain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domainRepo := domainmocks.NewDomainRepository(t)
+			domainRepo.On("Get", mock.Anything, &model.DomainFilter{Host: "go.example.com"}).Return(tt.domain, nil).Once()
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, linkFilter("0000D", tt.wantDomainID)).Return(&model.URLEntity{ID: 13}, nil).Once()
+			ruleRepo := rulemocks.NewRuleRepository(t)
+			ruleRepo.On("List", mock.Anything, uint64(13)).Return([]*model.RuleEntity{{ID: 7, URLID: 13, OS: "ios"}}, nil).Once()
+
+			app := apprule.NewRuleApplication(urlRepo, ruleRepo, domainRepo)
+			got, err := app.ListRules(context.Background(), "Go.Example.com.", "0000D")
+			if err != nil || len(got) != 1 || got[0].ID != 7 {
+				t.Fatalf("ListRules() = %+v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestRuleApp_RuleOfAnotherLink(t *testing.T) {
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, linkFilter("0000D", 0)).Return(&model.URLEntity{ID: 13}, nil)
+	ruleRepo := rulemocks.NewRuleRepository(t)
+	// rule 9 belongs to another link, so it is not found on this one
+	ruleRepo.On("Get", mock.Anything, &model.RuleFilter{ID: 9, URLID: 13}).Return(nil, nil)
+	app := apprule.NewRuleApplication(urlRepo, ruleRepo, domainmocks.NewDomainRepository(t))
+	ctx := context.Background()
+
+	_, err := app.UpdateRule(ctx, "", "0000D", 9, &model.UpsertRuleRequest{OS: "ios", DestinationURL: "https://example.com"})
+	if !cerr.Is(err, constant.ErrNotFound) {
+		t.Fatalf("UpdateRule() error = %v, want ErrNotFound", err)
+	}
+	if err := app.DeleteRule(ctx, "", "0000D", 9); !cerr.Is(err, constant.ErrNotFound) {
+		t.Fatalf("DeleteRule() error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,98 @@
+package tag
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/tag"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+const maxNameLength = 64
+
+type TagAppImpl struct {
+	TagRepository tag.TagRepository
+}
+
+type TagApp interface {
+	CreateTag(ctx context.Context, req *model.CreateTagRequest) (*model.GetTagResponse, error)
+	ListTags(ctx context.Context) ([]*model.GetTagResponse, error)
+	DeleteTag(ctx context.Context, id uint64) error
+}
+
+func NewTagApplication(TagRepository tag.TagRepository) TagApp {
+	return &TagAppImpl{
+		TagRepository: TagRepository,
+	}
+}
+
+// CreateTag creates the tag, tags are also created when first put on a link
+func (t *TagAppImpl) CreateTag(ctx context.Context, req *model.CreateTagRequest) (*model.GetTagResponse, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if name == "" || len(name) > maxNameLength {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	existing, err := t.TagRepository.Get(ctx, &model.TagFilter{Name: name})
+	if err != nil {
+		log.Println("[CreateTag] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+	if existing != nil {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	createdTag, err := t.TagRepository.Create(ctx, &model.TagEntity{Name: name})
+	if err != nil {
+		log.Println("[CreateTag] err Create", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return toGetTagResponse(createdTag), nil
+}
+
+func (t *TagAppImpl) ListTags(ctx context.Context) ([]*model.GetTagResponse, error) {
+	tags, err := t.TagRepository.List(ctx)
+	if err != nil {
+		log.Println("[ListTags] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := make([]*model.GetTagResponse, 0, len(tags))
+	for _, tagEntity := range tags {
+		resp = append(resp, toGetTagResponse(tagEntity))
+	}
+
+	return resp, nil
+}
+
+// DeleteTag deletes the tag and removes it from its links
+func (t *TagAppImpl) DeleteTag(ctx context.Context, id uint64) error {
+	tagEntity, err := t.TagRepository.Get(ctx, &model.TagFilter{ID: id})
+	if err != nil {
+		log.Println("[DeleteTag] err Get", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if tagEntity == nil {
+		return errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	if err := t.TagRepository.Delete(ctx, tagEntity.ID); err != nil {
+		log.Println("[DeleteTag] err Delete", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return nil
+}
+
+func toGetTagResponse(entity *model.TagEntity) *model.GetTagResponse {
+	return &model.GetTagResponse{
+		ID:        entity.ID,
+		Name:      entity.Name,
+		CreatedAt: entity.CreatedAt,
+	}
+}
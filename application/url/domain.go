@@ -0,0 +1,112 @@
+package url
+
+import (
+	"context"
+	"log"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/domainverify"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// resolveDomain returns the verified custom domain matching the host of a visit,
+// nil for any other host which is served as the default domain
+func (u *URLAppImpl) resolveDomain(ctx context.Context, host string) (*model.DomainEntity, error) {
+	if host == "" {
+		return nil, nil
+	}
+
+	domainEntity, err := u.DomainRepository.Get(ctx, &model.DomainFilter{
+		Host: domainverify.NormalizeHost(host),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if domainEntity == nil || domainEntity.VerifiedAt == nil {
+		return nil, nil
+	}
+
+	return domainEntity, nil
+}
+
+// findURL returns the link with the short url on the domain matching host, codes
+// of the default domain are looked up for any other host
+func (u *URLAppImpl) findURL(ctx context.Context, host, shortURL string) (*model.URLEntity, error) {
+	domainEntity, err := u.resolveDomain(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var domainID uint64
+	if domainEntity != nil {
+		domainID = domainEntity.ID
+	}
+
+	return u.URLRepository.Get(ctx, &model.URLFilter{
+		ShortURL: shortURL,
+		DomainID: &domainID,
+	})
+}
+
+// getVerifiedDomain returns the custom domain a link is created on, only verified domains are accepted
+func (u *URLAppImpl) getVerifiedDomain(ctx context.Context, host string) (*model.DomainEntity, error) {
+	domainEntity, err := u.DomainRepository.Get(ctx, &model.DomainFilter{
+		Host: domainverify.NormalizeHost(host),
+	})
+	if err != nil {
+		log.Println("[getVerifiedDomain] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if domainEntity == nil || domainEntity.VerifiedAt == nil {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	return domainEntity, nil
+}
+
+// getBatchDomain is getVerifiedDomain remembering the domains already looked up for the batch
+func (u *URLAppImpl) getBatchDomain(ctx context.Context, domains map[uint64]*model.DomainEntity, host string) (*model.DomainEntity, error) {
+	host = domainverify.NormalizeHost(host)
+	for _, domainEntity := range domains {
+		if domainEntity.Host == host {
+			return domainEntity, nil
+		}
+	}
+
+	domainEntity, err := u.getVerifiedDomain(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	domains[domainEntity.ID] = domainEntity
+
+	return domainEntity, nil
+}
+
+// getLinkDomain returns the custom domain of a link, nil for the default domain
+func (u *URLAppImpl) getLinkDomain(ctx context.Context, entity *model.URLEntity) (*model.DomainEntity, error) {
+	if entity.DomainID == 0 {
+		return nil, nil
+	}
+
+	return u.DomainRepository.Get(ctx, &model.DomainFilter{
+		ID: entity.DomainID,
+	})
+}
+
+// setLinks fills the absolute links of a response, all on the custom domain of the link
+// since its short url is only resolved there
+func (u *URLAppImpl) setLinks(resp *model.GetURLResponse, domainEntity *model.DomainEntity) {
+	var host string
+	if domainEntity != nil {
+		resp.Domain = domainEntity.Host
+		host = domainEntity.Host
+	}
+
+	if base := u.Config.BaseURL(host); base != "" {
+		resp.ShortLink = base + "/url/" + resp.ShortURL
+		resp.QRURL = resp.ShortLink + "/qr"
+		resp.InfoURL = resp.ShortLink + "/info"
+	}
+}
@@ -0,0 +1,50 @@
+package url
+
+import (
+	"context"
+	"log"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// expiredBatchSize is how many expired links are announced per call of NotifyExpiredLinks
+const expiredBatchSize = 100
+
+func (u *URLAppImpl) DeleteURL(ctx context.Context, host, shortURL string) error {
+	urlEntity, err := u.getURL(ctx, host, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if err := u.URLRepository.Delete(ctx, urlEntity.ID); err != nil {
+		log.Println("[DeleteURL] err Delete", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return nil
+}
+
+// NotifyExpiredLinks announces the links whose activation window ended, each link once
+// even with several instances running, and returns how many were announced
+func (u *URLAppImpl) NotifyExpiredLinks(ctx context.Context) (int, error) {
+	urls, err := u.URLRepository.ListExpired(ctx, u.Now(), expiredBatchSize)
+	if err != nil {
+		log.Println("[NotifyExpiredLinks] err ListExpired", err)
+		return 0, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	notified := 0
+	for _, urlEntity := range urls {
+		marked, err := u.URLRepository.MarkExpiryNotified(ctx, urlEntity.ID)
+		if err != nil {
+			log.Println("[NotifyExpiredLinks] err MarkExpiryNotified", err)
+			return notified, errors.SetCustomError(constant.ErrInternal)
+		}
+		if marked {
+			notified++
+		}
+	}
+
+	return notified, nil
+}
@@ -0,0 +1,40 @@
+package url
+
+import (
+	neturl "net/url"
+	"path"
+	"strings"
+)
+
+// isSafePath rejects control characters and backslashes, which some clients treat as a slash
+func isSafePath(rest string) bool {
+	for _, r := range rest {
+		if r < 0x20 || r == 0x7f || r == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// joinPath appends rest to the path of the destination. Dot segments are resolved
+// before joining so rest can't climb above the destination path, and only the
+// path component is changed so the host can't be escaped.
+func joinPath(destination, rest string) string {
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	cleaned := path.Clean("/" + rest)
+	if cleaned == "/" {
+		return destination
+	}
+	if strings.HasSuffix(rest, "/") {
+		cleaned += "/"
+	}
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/") + cleaned
+	parsed.RawPath = ""
+
+	return parsed.String()
+}
@@ -0,0 +1,58 @@
+package url
+
+import (
+	neturl "net/url"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// applyUTM sets the non empty UTM parameters on the destination, replacing the ones already there
+func applyUTM(destination string, utm *model.UTMParams) (string, error) {
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsed.Query()
+	for key, value := range map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
+// forwardQuery appends the incoming query to the destination, parameters
+// already on the destination are resolved according to conflict
+func forwardQuery(destination string, incoming neturl.Values, conflict string) string {
+	if len(incoming) == 0 {
+		return destination
+	}
+
+	parsed, err := neturl.Parse(destination)
+	if err != nil {
+		return destination
+	}
+
+	query := parsed.Query()
+	for key, values := range incoming {
+		switch {
+		case !query.Has(key) || conflict == constant.QueryConflictOverride:
+			query[key] = values
+		case conflict == constant.QueryConflictAppend:
+			query[key] = append(query[key], values...)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
@@ -0,0 +1,64 @@
+package url
+
+import (
+	"context"
+	"log"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/language"
+	"github.com/muhammadheryan/url-shortner-base62/utils/useragent"
+)
+
+// visitor is what the targeting rules are evaluated against
+type visitor struct {
+	country    string
+	deviceType string
+	os         string
+	languages  []string
+}
+
+// chooseDestination returns the destination of the first matching rule, or the
+// original url and false when no rule matches
+func (u *URLAppImpl) chooseDestination(ctx context.Context, entity *model.URLEntity, req *model.ResolveURLRequest) (string, bool) {
+	rules, err := u.RuleRepository.List(ctx, entity.ID)
+	if err != nil {
+		log.Println("[chooseDestination] err List", err)
+		return entity.OriginalURL, false
+	}
+	if len(rules) == 0 {
+		return entity.OriginalURL, false
+	}
+
+	agent := useragent.Parse(req.UserAgent)
+	v := visitor{
+		country:    u.GeoLocator.Country(req.ClientIP),
+		deviceType: agent.DeviceType,
+		os:         agent.OS,
+		languages:  language.ParseAcceptLanguage(req.AcceptLanguage),
+	}
+
+	for _, rule := range rules {
+		if v.matches(rule) {
+			return rule.DestinationURL, true
+		}
+	}
+
+	return entity.OriginalURL, false
+}
+
+// matches checks every non empty condition of the rule
+func (v visitor) matches(rule *model.RuleEntity) bool {
+	if rule.Country != "" && rule.Country != v.country {
+		return false
+	}
+	if rule.DeviceType != "" && rule.DeviceType != v.deviceType {
+		return false
+	}
+	if rule.OS != "" && rule.OS != v.os {
+		return false
+	}
+	if rule.Language != "" && !language.Matches(rule.Language, v.languages) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package url
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+var errInvalidSchedule = errors.New("invalid schedule")
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// validateSchedule checks the time zone, days and HH:MM bounds of a schedule
+func validateSchedule(schedule *model.URLSchedule) error {
+	if _, err := time.LoadLocation(schedule.TimeZone); err != nil {
+		return err
+	}
+	if len(schedule.Windows) == 0 {
+		return errInvalidSchedule
+	}
+
+	for _, window := range schedule.Windows {
+		for _, day := range window.Days {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				return errInvalidSchedule
+			}
+		}
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return err
+		}
+		if start == end {
+			return errInvalidSchedule
+		}
+	}
+
+	return nil
+}
+
+// isActive checks if the link redirects to its destination at now
+func isActive(entity *model.URLEntity, now time.Time) bool {
+	if entity.ActiveFrom != nil && now.Before(*entity.ActiveFrom) {
+		return false
+	}
+	if entity.ActiveUntil != nil && !now.Before(*entity.ActiveUntil) {
+		return false
+	}
+	if entity.Schedule == nil {
+		return true
+	}
+
+	location, err := time.LoadLocation(entity.Schedule.TimeZone)
+	if err != nil {
+		return false
+	}
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, window := range entity.Schedule.Windows {
+		start, errStart := parseClock(window.Start)
+		end, errEnd := parseClock(window.End)
+		if errStart != nil || errEnd != nil {
+			continue
+		}
+
+		// A window spanning midnight belongs to the day it starts on
+		day := local.Weekday()
+		if start < end {
+			if minute < start || minute >= end {
+				continue
+			}
+		} else {
+			if minute < start && minute >= end {
+				continue
+			}
+			if minute < end {
+				day = (day + 6) % 7
+			}
+		}
+
+		if windowHasDay(window, day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func windowHasDay(window model.ScheduleWindow, day time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, name := range window.Days {
+		if weekdays[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock returns the minutes since midnight of a HH:MM value
+func parseClock(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
@@ -0,0 +1,206 @@
+package url
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+const (
+	maxTags      = 20
+	maxTagLength = 64
+	maxListLimit = 100
+	defaultLimit = 20
+)
+
+// normalizeTags lowercases and deduplicates tag names, keeping their order
+func normalizeTags(names []string) ([]string, error) {
+	if len(names) > maxTags {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || len(name) > maxTagLength {
+			return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+
+	return result, nil
+}
+
+func (u *URLAppImpl) ListURLs(ctx context.Context, req *model.ListURLRequest) (*model.ListURLResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+	if req.Limit > maxListLimit {
+		req.Limit = maxListLimit
+	}
+	if req.Offset < 0 {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	urls, err := u.URLRepository.List(ctx, &model.URLFilter{
+		CampaignID: req.CampaignID,
+		Tag:        strings.ToLower(strings.TrimSpace(req.Tag)),
+		Limit:      req.Limit,
+		Offset:     req.Offset,
+	})
+	if err != nil {
+		log.Println("[ListURLs] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	tags, err := u.getURLTags(ctx, urls)
+	if err != nil {
+		log.Println("[ListURLs] err getURLTags", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := &model.ListURLResponse{
+		Items:  make([]*model.GetURLInfoResponse, 0, len(urls)),
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	domains := make(map[uint64]*model.DomainEntity)
+	for _, urlEntity := range urls {
+		domainEntity, ok := domains[urlEntity.DomainID]
+		if !ok {
+			domainEntity, err = u.getLinkDomain(ctx, urlEntity)
+			if err != nil {
+				log.Println("[ListURLs] err getLinkDomain", err)
+				return nil, errors.SetCustomError(constant.ErrInternal)
+			}
+			domains[urlEntity.DomainID] = domainEntity
+		}
+
+		item := u.toGetURLInfoResponse(urlEntity, domainEntity)
+		item.Tags = tags[urlEntity.ID]
+		resp.Items = append(resp.Items, item)
+	}
+
+	return resp, nil
+}
+
+func (u *URLAppImpl) SetURLTags(ctx context.Context, host, shortURL string, req *model.SetURLTagsRequest) ([]string, error) {
+	names, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	urlEntity, err := u.getURL(ctx, host, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.setTags(ctx, urlEntity.ID, names); err != nil {
+		log.Println("[SetURLTags] err setTags", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return names, nil
+}
+
+func (u *URLAppImpl) SetURLCampaign(ctx context.Context, host, shortURL string, req *model.SetURLCampaignRequest) error {
+	urlEntity, err := u.getURL(ctx, host, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if err := u.checkCampaign(ctx, req.CampaignID); err != nil {
+		return err
+	}
+
+	if err := u.URLRepository.SetCampaign(ctx, urlEntity.ID, req.CampaignID); err != nil {
+		log.Println("[SetURLCampaign] err SetCampaign", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return nil
+}
+
+func (u *URLAppImpl) getURL(ctx context.Context, host, shortURL string) (*model.URLEntity, error) {
+	urlEntity, err := u.findURL(ctx, host, shortURL)
+	if err != nil {
+		log.Println("[getURL] err findURL", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if urlEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	return urlEntity, nil
+}
+
+// checkCampaign makes sure a link is only put in an existing campaign, 0 means no campaign
+func (u *URLAppImpl) checkCampaign(ctx context.Context, campaignID uint64) error {
+	if campaignID == 0 {
+		return nil
+	}
+
+	campaignEntity, err := u.CampaignRepository.Get(ctx, &model.CampaignFilter{
+		ID: campaignID,
+	})
+	if err != nil {
+		log.Println("[checkCampaign] err Get", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if campaignEntity == nil {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	return nil
+}
+
+// setTags replaces the tags of a link, creating the tags seen for the first time
+func (u *URLAppImpl) setTags(ctx context.Context, urlID uint64, names []string) error {
+	tagIDs := make([]uint64, 0, len(names))
+	for _, name := range names {
+		tagEntity, err := u.TagRepository.Get(ctx, &model.TagFilter{Name: name})
+		if err != nil {
+			return err
+		}
+		if tagEntity == nil {
+			tagEntity, err = u.TagRepository.Create(ctx, &model.TagEntity{Name: name})
+			if err != nil {
+				return err
+			}
+		}
+		tagIDs = append(tagIDs, tagEntity.ID)
+	}
+
+	return u.TagRepository.SetURLTags(ctx, urlID, tagIDs)
+}
+
+// getURLTags returns the tag names of the links by link id
+func (u *URLAppImpl) getURLTags(ctx context.Context, urls []*model.URLEntity) (map[uint64][]string, error) {
+	urlIDs := make([]uint64, 0, len(urls))
+	for _, urlEntity := range urls {
+		urlIDs = append(urlIDs, urlEntity.ID)
+	}
+
+	urlTags, err := u.TagRepository.ListByURLs(ctx, urlIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[uint64][]string, len(urls))
+	for _, urlTag := range urlTags {
+		tags[urlTag.URLID] = append(tags[urlTag.URLID], urlTag.Name)
+	}
+
+	return tags, nil
+}
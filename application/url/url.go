@@ -3,68 +3,285 @@ package url
 import (
 	"context"
 	"log"
+	"math/rand"
+	neturl "net/url"
 	"strings"
+	"time"
 
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
 	"github.com/muhammadheryan/url-shortner-base62/constant"
 	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/campaign"
+	"github.com/muhammadheryan/url-shortner-base62/repository/click"
+	"github.com/muhammadheryan/url-shortner-base62/repository/domain"
+	"github.com/muhammadheryan/url-shortner-base62/repository/rule"
+	"github.com/muhammadheryan/url-shortner-base62/repository/tag"
 	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/repository/variant"
 	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/muhammadheryan/url-shortner-base62/utils/geoip"
+	"github.com/muhammadheryan/url-shortner-base62/utils/pagetitle"
+	"github.com/muhammadheryan/url-shortner-base62/utils/ratelimit"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type URLAppImpl struct {
-	URLRepository url.URLRepository
+	URLRepository      url.URLRepository
+	RuleRepository     rule.RuleRepository
+	VariantRepository  variant.VariantRepository
+	ClickRepository    click.ClickRepository
+	DomainRepository   domain.DomainRepository
+	TagRepository      tag.TagRepository
+	CampaignRepository campaign.CampaignRepository
+	GeoLocator         geoip.Locator
+	Events             EventFilter
+	Config             *config.Config
+	TitleFetcher       pagetitle.Fetcher
+	PasswordLimiter    *ratelimit.FailureLimiter
+	Now                func() time.Time
+	RandIntn           func(n int) int
+}
+
+// EventFilter tells whether anyone takes an event, so click events nobody publishes
+// are not written at all
+type EventFilter interface {
+	Wants(ctx context.Context, event string) bool
 }
 
 type URLApp interface {
 	CreateURLShortner(ctx context.Context, req *model.CreateURLShortnerRequest) (*model.GetURLResponse, error)
-	GetURLByShortURL(ctx context.Context, shortURL string) (*model.GetURLResponse, error)
+	CreateURLShortnerBatch(ctx context.Context, req *model.CreateURLShortnerBatchRequest) (*model.CreateURLShortnerBatchResponse, error)
+	GetURLByShortURL(ctx context.Context, req *model.ResolveURLRequest) (*model.GetURLResponse, error)
+	GetURLInfo(ctx context.Context, host, shortURL string) (*model.GetURLInfoResponse, error)
+	GetURLPreview(ctx context.Context, host, shortURL string, unlocked bool) (*model.GetURLPreviewResponse, error)
+	GetResolvedURLPreview(ctx context.Context, resolved *model.GetURLResponse) *model.GetURLPreviewResponse
+	UnlockURL(ctx context.Context, req *model.UnlockURLRequest) error
+	GetURLStats(ctx context.Context, host, shortURL string) (*model.GetURLStatsResponse, error)
+	ListURLs(ctx context.Context, req *model.ListURLRequest) (*model.ListURLResponse, error)
+	SetURLTags(ctx context.Context, host, shortURL string, req *model.SetURLTagsRequest) ([]string, error)
+	SetURLCampaign(ctx context.Context, host, shortURL string, req *model.SetURLCampaignRequest) error
+	DeleteURL(ctx context.Context, host, shortURL string) error
+	NotifyExpiredLinks(ctx context.Context) (int, error)
 }
 
-func NewURLApplication(URLRepository url.URLRepository) URLApp {
+func NewURLApplication(URLRepository url.URLRepository, RuleRepository rule.RuleRepository, VariantRepository variant.VariantRepository, ClickRepository click.ClickRepository, DomainRepository domain.DomainRepository, TagRepository tag.TagRepository, CampaignRepository campaign.CampaignRepository, GeoLocator geoip.Locator, Events EventFilter, cfg *config.Config) URLApp {
 	return &URLAppImpl{
-		URLRepository: URLRepository,
+		URLRepository:      URLRepository,
+		RuleRepository:     RuleRepository,
+		VariantRepository:  VariantRepository,
+		ClickRepository:    ClickRepository,
+		DomainRepository:   DomainRepository,
+		TagRepository:      TagRepository,
+		CampaignRepository: CampaignRepository,
+		GeoLocator:         GeoLocator,
+		Events:             Events,
+		Config:             cfg,
+		TitleFetcher:       pagetitle.NewFetcher(cfg.Server.PreviewFetchTimeout, cfg.Server.AllowPrivateDestinations),
+		PasswordLimiter:    ratelimit.NewFailureLimiter(cfg.Server.PasswordMaxAttempts, cfg.Server.PasswordLockout),
+		Now:                time.Now,
+		RandIntn:           rand.Intn,
 	}
 }
 
 func (u *URLAppImpl) CreateURLShortner(ctx context.Context, req *model.CreateURLShortnerRequest) (*model.GetURLResponse, error) {
-	// check http or https
-	if !strings.HasPrefix(req.OriginalURL, "http://") && !strings.HasPrefix(req.OriginalURL, "https://") {
-		req.OriginalURL = "https://" + req.OriginalURL
+	entity, err := u.buildURLEntity(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve the link on the custom domain when one is chosen
+	var domainEntity *model.DomainEntity
+	if req.Domain != "" {
+		domainEntity, err = u.getVerifiedDomain(ctx, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+		entity.DomainID = domainEntity.ID
+	}
+
+	if err := u.checkCampaign(ctx, entity.CampaignID); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create in database to get ID
-	createdURL, err := u.URLRepository.Create(ctx, &model.URLEntity{
-		UserID:      0, // You might want to get this from context or auth
-		OriginalURL: req.OriginalURL,
-	})
+	createdURL, err := u.URLRepository.Create(ctx, entity)
 	if err != nil {
 		log.Println("[CreateURLShortner] err Create", err)
 		return nil, errors.SetCustomError(constant.ErrInternal)
 	}
 
+	// Save the destinations of a split link and its tags
+	var variants []*model.VariantEntity
+	if len(req.Variants) > 0 {
+		variants, err = u.VariantRepository.CreateBatch(ctx, toVariantEntities(createdURL.ID, req.Variants))
+		if err != nil {
+			log.Println("[CreateURLShortner] err CreateBatch", err)
+			return nil, errors.SetCustomError(constant.ErrInternal)
+		}
+	}
+	if len(tags) > 0 {
+		if err := u.setTags(ctx, createdURL.ID, tags); err != nil {
+			log.Println("[CreateURLShortner] err setTags", err)
+			return nil, errors.SetCustomError(constant.ErrInternal)
+		}
+	}
+
 	// Generate short URL from ID
 	shortURL := createBase62Converter(createdURL.ID)
 
-	// Update the URL entity with short URL
+	// Update the URL entity with short URL, the link is announced as created from here
 	createdURL.ShortURL = shortURL
 	updatedURL, err := u.URLRepository.Update(ctx, createdURL)
 	if err != nil {
 		log.Println("[CreateURLShortner] err Update", err)
+		if errors.Is(err, constant.ErrConflict) {
+			return nil, err
+		}
 		return nil, errors.SetCustomError(constant.ErrInternal)
 	}
 
+	resp := u.toGetURLResponse(updatedURL)
+	u.setLinks(resp, domainEntity)
+	if len(variants) > 0 {
+		resp.Variants = toGetVariantResponses(variants)
+	}
+	if len(tags) > 0 {
+		resp.Tags = tags
+	}
+
 	// Return response
-	return &model.GetURLResponse{
-		ShortURL:    updatedURL.ShortURL,
-		OriginalURL: updatedURL.OriginalURL,
-		CreatedAt:   updatedURL.CreatedAt,
-		UpdatedAt:   updatedURL.UpdatedAt,
-	}, nil
+	return resp, nil
+}
+
+func (u *URLAppImpl) CreateURLShortnerBatch(ctx context.Context, req *model.CreateURLShortnerBatchRequest) (*model.CreateURLShortnerBatchResponse, error) {
+	if len(req.Items) == 0 || len(req.Items) > u.Config.Server.BatchMaxSize {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	resp := &model.CreateURLShortnerBatchResponse{
+		Total: len(req.Items),
+		Items: make([]model.CreateURLShortnerBatchItem, len(req.Items)),
+	}
+
+	// Validate every item, invalid ones are reported without failing the batch
+	entities := make([]*model.URLEntity, 0, len(req.Items))
+	indexes := make([]int, 0, len(req.Items))
+	domains := make(map[uint64]*model.DomainEntity)
+	campaigns := make(map[uint64]bool)
+	tags := make([][]string, len(req.Items))
+	for i := range req.Items {
+		resp.Items[i].Index = i
+		entity, err := u.buildURLEntity(&req.Items[i])
+		if err == nil && req.Items[i].Domain != "" {
+			var domainEntity *model.DomainEntity
+			domainEntity, err = u.getBatchDomain(ctx, domains, req.Items[i].Domain)
+			if err == nil {
+				entity.DomainID = domainEntity.ID
+			}
+		}
+		if err == nil && !campaigns[entity.CampaignID] {
+			if err = u.checkCampaign(ctx, entity.CampaignID); err == nil {
+				campaigns[entity.CampaignID] = true
+			}
+		}
+		if err == nil {
+			tags[i], err = normalizeTags(req.Items[i].Tags)
+		}
+		if err != nil {
+			resp.Items[i].Error = toBatchItemError(err)
+			resp.Failed++
+			continue
+		}
+
+		entities = append(entities, entity)
+		indexes = append(indexes, i)
+	}
+
+	if len(entities) == 0 {
+		return resp, nil
+	}
+
+	// Create in database to get IDs
+	createdURLs, err := u.URLRepository.CreateBatch(ctx, entities)
+	if err != nil {
+		log.Println("[CreateURLShortnerBatch] err CreateBatch", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	// Save the destinations of every split link at once
+	variants := make([]*model.VariantEntity, 0)
+	for i, createdURL := range createdURLs {
+		variants = append(variants, toVariantEntities(createdURL.ID, req.Items[indexes[i]].Variants)...)
+	}
+	if len(variants) > 0 {
+		variants, err = u.VariantRepository.CreateBatch(ctx, variants)
+		if err != nil {
+			log.Println("[CreateURLShortnerBatch] err CreateBatch", err)
+			return nil, errors.SetCustomError(constant.ErrInternal)
+		}
+	}
+	variantsByURL := make(map[uint64][]*model.VariantEntity)
+	for _, variant := range variants {
+		variantsByURL[variant.URLID] = append(variantsByURL[variant.URLID], variant)
+	}
+
+	for i, createdURL := range createdURLs {
+		if urlTags := tags[indexes[i]]; len(urlTags) > 0 {
+			if err := u.setTags(ctx, createdURL.ID, urlTags); err != nil {
+				log.Println("[CreateURLShortnerBatch] err setTags", err)
+				return nil, errors.SetCustomError(constant.ErrInternal)
+			}
+		}
+	}
+
+	// Generate short URLs from IDs and save them at once, the links are announced as created from here
+	for _, createdURL := range createdURLs {
+		createdURL.ShortURL = createBase62Converter(createdURL.ID)
+	}
+	updatedURLs, err := u.URLRepository.UpdateBatch(ctx, createdURLs)
+	if err != nil {
+		log.Println("[CreateURLShortnerBatch] err UpdateBatch", err)
+		if errors.Is(err, constant.ErrConflict) {
+			return nil, err
+		}
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	for i, updatedURL := range updatedURLs {
+		item := u.toGetURLResponse(updatedURL)
+		u.setLinks(item, domains[updatedURL.DomainID])
+		if urlVariants := variantsByURL[updatedURL.ID]; len(urlVariants) > 0 {
+			item.Variants = toGetVariantResponses(urlVariants)
+		}
+		if urlTags := tags[indexes[i]]; len(urlTags) > 0 {
+			item.Tags = urlTags
+		}
+		resp.Items[indexes[i]].GetURLResponse = item
+		resp.Created++
+	}
+
+	return resp, nil
 }
 
-func (u *URLAppImpl) GetURLByShortURL(ctx context.Context, shortURL string) (*model.GetURLResponse, error) {
+func (u *URLAppImpl) GetURLByShortURL(ctx context.Context, req *model.ResolveURLRequest) (*model.GetURLResponse, error) {
+	// Short codes are looked up on the domain the visit was sent to
+	domainEntity, err := u.resolveDomain(ctx, req.Host)
+	if err != nil {
+		log.Println("[GetURLByShortURL] err resolveDomain", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+	var domainID uint64
+	if domainEntity != nil {
+		domainID = domainEntity.ID
+	}
+
 	urlEntity, err := u.URLRepository.Get(ctx, &model.URLFilter{
-		ShortURL: shortURL,
+		ShortURL: req.ShortURL,
+		DomainID: &domainID,
 	})
 	if err != nil {
 		log.Println("[GetURLByShortURL] err Get", err)
@@ -75,15 +292,362 @@ func (u *URLAppImpl) GetURLByShortURL(ctx context.Context, shortURL string) (*mo
 		return nil, errors.SetCustomError(constant.ErrNotFound)
 	}
 
+	if urlEntity.Status == constant.URLStatusConsumed {
+		return nil, errors.SetCustomError(constant.ErrGone)
+	}
+
+	// Only links forwarding the path accept one after the short url
+	if req.Path != "" {
+		if !urlEntity.ForwardPath {
+			return nil, errors.SetCustomError(constant.ErrNotFound)
+		}
+		if !isSafePath(req.Path) {
+			return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+	}
+
+	// Outside the activation window the fallback destination is used, if any
+	if !isActive(urlEntity, u.Now()) {
+		if urlEntity.FallbackURL == "" {
+			return nil, errors.SetCustomError(constant.ErrNotAvailable)
+		}
+		resp := u.toGetURLResponse(urlEntity)
+		resp.OriginalURL = urlEntity.FallbackURL
+		return resp, nil
+	}
+
+	// Protected links are only resolved once the visitor entered the password
+	if urlEntity.PasswordHash != "" && !req.Unlocked {
+		return nil, errors.SetCustomError(constant.ErrPasswordRequired)
+	}
+
+	// Only one visit may win a single use link, concurrent visits get gone
+	if urlEntity.SingleUse {
+		consumed, err := u.URLRepository.Consume(ctx, urlEntity.ID)
+		if err != nil {
+			log.Println("[GetURLByShortURL] err Consume", err)
+			return nil, errors.SetCustomError(constant.ErrInternal)
+		}
+		if !consumed {
+			return nil, errors.SetCustomError(constant.ErrGone)
+		}
+	}
+
+	// Targeting rules come first, other visitors are split between the variants
+	resp := u.toGetURLResponse(urlEntity)
+	u.setLinks(resp, domainEntity)
+	click := &model.ClickEntity{URLID: urlEntity.ID}
+	destination, matched := u.chooseDestination(ctx, urlEntity, req)
+	resp.OriginalURL = destination
+	if !matched {
+		if variant := u.chooseVariant(ctx, urlEntity, req); variant != nil {
+			resp.OriginalURL = variant.DestinationURL
+			resp.VariantID = variant.ID
+			click.VariantID = &variant.ID
+		}
+	}
+	if urlEntity.ForwardPath && req.Path != "" {
+		resp.OriginalURL = joinPath(resp.OriginalURL, req.Path)
+	}
+	if urlEntity.ForwardQuery {
+		resp.OriginalURL = forwardQuery(resp.OriginalURL, req.Query, urlEntity.QueryConflict)
+	}
+
+	// Count the click, a failure here should not block the redirect
+	writeEvent := u.Events == nil || u.Events.Wants(ctx, constant.EventLinkClicked)
+	if err := u.URLRepository.RecordClick(ctx, click, writeEvent); err != nil {
+		log.Println("[GetURLByShortURL] err RecordClick", err)
+	}
+
+	// Return response with the destination targeted at this visitor
+	return resp, nil
+}
+
+func (u *URLAppImpl) GetURLInfo(ctx context.Context, host, shortURL string) (*model.GetURLInfoResponse, error) {
+	urlEntity, err := u.findURL(ctx, host, shortURL)
+	if err != nil {
+		log.Println("[GetURLInfo] err findURL", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if urlEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	domainEntity, err := u.getLinkDomain(ctx, urlEntity)
+	if err != nil {
+		log.Println("[GetURLInfo] err getLinkDomain", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+	tags, err := u.getURLTags(ctx, []*model.URLEntity{urlEntity})
+	if err != nil {
+		log.Println("[GetURLInfo] err getURLTags", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
 	// Return response
-	return &model.GetURLResponse{
-		ShortURL:    urlEntity.ShortURL,
-		OriginalURL: urlEntity.OriginalURL,
-		CreatedAt:   urlEntity.CreatedAt,
-		UpdatedAt:   urlEntity.UpdatedAt,
+	resp := u.toGetURLInfoResponse(urlEntity, domainEntity)
+	resp.Tags = tags[urlEntity.ID]
+	return resp, nil
+}
+
+func (u *URLAppImpl) GetURLPreview(ctx context.Context, host, shortURL string, unlocked bool) (*model.GetURLPreviewResponse, error) {
+	info, err := u.GetURLInfo(ctx, host, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The destination of a locked, single use or inactive link is not shown, so it isn't contacted either
+	if info.SingleUse || !info.Active || (info.PasswordProtected && !unlocked) {
+		return &model.GetURLPreviewResponse{GetURLInfoResponse: *info}, nil
+	}
+
+	// The title is optional, the preview is still shown when the destination can't be fetched
+	title, err := u.TitleFetcher.Fetch(ctx, info.OriginalURL)
+	if err != nil {
+		log.Println("[GetURLPreview] err Fetch title", err)
+	}
+
+	return &model.GetURLPreviewResponse{
+		GetURLInfoResponse: *info,
+		Title:              title,
 	}, nil
 }
 
+// GetResolvedURLPreview is the preview of a visit already resolved by GetURLByShortURL,
+// it shows the destination chosen for this visitor instead of the stored one
+func (u *URLAppImpl) GetResolvedURLPreview(ctx context.Context, resolved *model.GetURLResponse) *model.GetURLPreviewResponse {
+	// The title is optional, the preview is still shown when the destination can't be fetched
+	title, err := u.TitleFetcher.Fetch(ctx, resolved.OriginalURL)
+	if err != nil {
+		log.Println("[GetResolvedURLPreview] err Fetch title", err)
+	}
+
+	return &model.GetURLPreviewResponse{
+		GetURLInfoResponse: model.GetURLInfoResponse{GetURLResponse: *resolved},
+		Title:              title,
+	}
+}
+
+func (u *URLAppImpl) UnlockURL(ctx context.Context, req *model.UnlockURLRequest) error {
+	limiterKey := req.ClientKey + "|" + req.Host + "|" + req.ShortURL
+	if !u.PasswordLimiter.Allowed(limiterKey) {
+		return errors.SetCustomError(constant.ErrTooManyRequests)
+	}
+
+	urlEntity, err := u.findURL(ctx, req.Host, req.ShortURL)
+	if err != nil {
+		log.Println("[UnlockURL] err findURL", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if urlEntity == nil {
+		return errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	if urlEntity.PasswordHash == "" {
+		return nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(urlEntity.PasswordHash), []byte(req.Password)); err != nil {
+		u.PasswordLimiter.Fail(limiterKey)
+		return errors.SetCustomError(constant.ErrUnauthorize)
+	}
+
+	u.PasswordLimiter.Reset(limiterKey)
+	return nil
+}
+
+func (u *URLAppImpl) GetURLStats(ctx context.Context, host, shortURL string) (*model.GetURLStatsResponse, error) {
+	urlEntity, err := u.findURL(ctx, host, shortURL)
+	if err != nil {
+		log.Println("[GetURLStats] err findURL", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if urlEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	variants, err := u.VariantRepository.List(ctx, urlEntity.ID)
+	if err != nil {
+		log.Println("[GetURLStats] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	counts, err := u.ClickRepository.CountByVariant(ctx, urlEntity.ID)
+	if err != nil {
+		log.Println("[GetURLStats] err CountByVariant", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+	clicks := make(map[uint64]uint64, len(counts))
+	for _, count := range counts {
+		clicks[count.VariantID] = count.Clicks
+	}
+
+	resp := &model.GetURLStatsResponse{
+		ShortURL:          urlEntity.ShortURL,
+		TotalClicks:       urlEntity.ClickCount,
+		StickyVariant:     urlEntity.StickyVariant,
+		PasswordProtected: urlEntity.PasswordHash != "",
+		SingleUse:         urlEntity.SingleUse,
+		Active:            isActive(urlEntity, u.Now()),
+		Variants:          make([]model.VariantStats, 0, len(variants)),
+	}
+	for _, variant := range toGetVariantResponses(variants) {
+		resp.Variants = append(resp.Variants, model.VariantStats{
+			GetVariantResponse: variant,
+			Clicks:             clicks[variant.ID],
+		})
+	}
+
+	return resp, nil
+}
+
+// buildURLEntity normalizes and validates a create request, applying the server defaults
+func (u *URLAppImpl) buildURLEntity(req *model.CreateURLShortnerRequest) (*model.URLEntity, error) {
+	// check http or https
+	if !strings.HasPrefix(req.OriginalURL, "http://") && !strings.HasPrefix(req.OriginalURL, "https://") {
+		req.OriginalURL = "https://" + req.OriginalURL
+	}
+	if parsed, err := neturl.ParseRequestURI(req.OriginalURL); err != nil || parsed.Host == "" {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	// use server default when redirect type is not chosen
+	if req.RedirectType == 0 {
+		req.RedirectType = u.Config.Server.DefaultRedirectType
+	}
+	if !constant.IsValidRedirectType(req.RedirectType) {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	// validate the activation window
+	if req.ActiveFrom != nil && req.ActiveUntil != nil && !req.ActiveFrom.Before(*req.ActiveUntil) {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+	if req.Schedule != nil {
+		if err := validateSchedule(req.Schedule); err != nil {
+			return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+	}
+	if req.FallbackURL != "" {
+		if parsed, err := neturl.ParseRequestURI(req.FallbackURL); err != nil || parsed.Host == "" {
+			return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+	}
+
+	if err := validateVariants(req.Variants); err != nil {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	// merge the campaign parameters into every destination
+	if req.UTM != nil {
+		originalURL, err := applyUTM(req.OriginalURL, req.UTM)
+		if err != nil {
+			return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+		req.OriginalURL = originalURL
+
+		for i := range req.Variants {
+			destination, err := applyUTM(req.Variants[i].DestinationURL, req.UTM)
+			if err != nil {
+				return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+			}
+			req.Variants[i].DestinationURL = destination
+		}
+	}
+
+	// conflict resolution only applies to links forwarding the query
+	if !req.ForwardQuery {
+		req.QueryConflict = ""
+	} else if req.QueryConflict == "" {
+		req.QueryConflict = u.Config.Server.DefaultQueryConflict
+	}
+	if req.ForwardQuery && !constant.IsValidQueryConflict(req.QueryConflict) {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	entity := &model.URLEntity{
+		UserID:       0, // You might want to get this from context or auth
+		OriginalURL:  req.OriginalURL,
+		RedirectType: req.RedirectType,
+		SingleUse:    req.SingleUse,
+		ActiveFrom:   req.ActiveFrom,
+		ActiveUntil:  req.ActiveUntil,
+		Schedule:     req.Schedule,
+		FallbackURL:  req.FallbackURL,
+		// stickiness only applies to split links
+		StickyVariant: req.StickyVariant && len(req.Variants) > 0,
+		ForwardQuery:  req.ForwardQuery,
+		QueryConflict: req.QueryConflict,
+		ForwardPath:   req.ForwardPath,
+		CampaignID:    req.CampaignID,
+	}
+
+	// only the hash of the password is stored
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Println("[buildURLEntity] err GenerateFromPassword", err)
+			return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+		entity.PasswordHash = string(hash)
+	}
+
+	return entity, nil
+}
+
+func toBatchItemError(err error) *model.BatchItemError {
+	customError, ok := err.(errors.CustomError)
+	if !ok {
+		customError = errors.SetCustomError(constant.ErrInternal)
+	}
+	return &model.BatchItemError{
+		Code:    customError.ErrorCode(),
+		Message: customError.Error(),
+	}
+}
+
+func (u *URLAppImpl) toGetURLResponse(entity *model.URLEntity) *model.GetURLResponse {
+	return &model.GetURLResponse{
+		ShortURL:          entity.ShortURL,
+		CampaignID:        entity.CampaignID,
+		OriginalURL:       entity.OriginalURL,
+		RedirectType:      entity.RedirectType,
+		PreviewRequired:   u.Config.Server.ForcePreviewUntrusted && !u.Config.IsTrustedUser(entity.UserID),
+		PasswordProtected: entity.PasswordHash != "",
+		SingleUse:         entity.SingleUse,
+		ActiveFrom:        entity.ActiveFrom,
+		ActiveUntil:       entity.ActiveUntil,
+		Schedule:          entity.Schedule,
+		FallbackURL:       entity.FallbackURL,
+		StickyVariant:     entity.StickyVariant,
+		ForwardQuery:      entity.ForwardQuery,
+		QueryConflict:     entity.QueryConflict,
+		ForwardPath:       entity.ForwardPath,
+		CreatedAt:         entity.CreatedAt,
+		UpdatedAt:         entity.UpdatedAt,
+	}
+}
+
+// toGetURLInfoResponse is the link metadata with its absolute links
+func (u *URLAppImpl) toGetURLInfoResponse(entity *model.URLEntity, domainEntity *model.DomainEntity) *model.GetURLInfoResponse {
+	resp := u.toGetURLResponse(entity)
+	u.setLinks(resp, domainEntity)
+
+	now := u.Now()
+	return &model.GetURLInfoResponse{
+		GetURLResponse: *resp,
+		UserID:         entity.UserID,
+		Status:         entity.Status,
+		ClickCount:     entity.ClickCount,
+		ConsumedAt:     entity.ConsumedAt,
+		Active:         isActive(entity, now),
+		CheckedAt:      now,
+	}
+}
+
 func createBase62Converter(id uint64) (shortURL string) {
 	const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	const minLength = 5
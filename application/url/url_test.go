@@ -3,18 +3,90 @@ package url_test
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
 	"reflect"
 	"testing"
 	"time"
 
 	appurl "github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
 	"github.com/muhammadheryan/url-shortner-base62/constant"
+	campaignmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/campaign"
+	clickmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/click"
+	domainmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/domain"
+	rulemocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/rule"
+	tagmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/tag"
 	urlmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/url"
+	variantmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/variant"
 	"github.com/muhammadheryan/url-shortner-base62/model"
 	cerr "github.com/muhammadheryan/url-shortner-base62/utils/errors"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			DefaultRedirectType: http.StatusTemporaryRedirect,
+			BatchMaxSize:        3,
+			PasswordMaxAttempts: 2,
+			PasswordLockout:     time.Minute,
+		},
+	}
+}
+
+// stubLocator resolves every address to the same country
+type stubLocator string
+
+func (s stubLocator) Country(string) string {
+	return string(s)
+}
+
+// newTestApp builds the app with no targeting rules, variants nor custom domains and an unknown visitor country
+func newTestApp(t *testing.T, urlRepo *urlmocks.URLRepository, cfg *config.Config) appurl.URLApp {
+	ruleRepo := rulemocks.NewRuleRepository(t)
+	ruleRepo.On("List", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return appurl.NewURLApplication(urlRepo, ruleRepo, newVariantRepo(t, nil), newClickRepo(t), newDomainRepo(t), newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, cfg)
+}
+
+func newVariantRepo(t *testing.T, variants []*model.VariantEntity) *variantmocks.VariantRepository {
+	variantRepo := variantmocks.NewVariantRepository(t)
+	variantRepo.On("List", mock.Anything, mock.Anything).Return(variants, nil).Maybe()
+	return variantRepo
+}
+
+// newDomainRepo knows no custom domain
+func newDomainRepo(t *testing.T) *domainmocks.DomainRepository {
+	domainRepo := domainmocks.NewDomainRepository(t)
+	domainRepo.On("Get", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return domainRepo
+}
+
+// newTagRepo knows no tagged link
+func newTagRepo(t *testing.T) *tagmocks.TagRepository {
+	tagRepo := tagmocks.NewTagRepository(t)
+	tagRepo.On("ListByURLs", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return tagRepo
+}
+
+// resolveFilter is the lookup of a link on the default domain
+func resolveFilter(shortURL string) *model.URLFilter {
+	return &model.URLFilter{ShortURL: shortURL, DomainID: new(uint64)}
+}
+
+func newClickRepo(t *testing.T) *clickmocks.ClickRepository {
+	return clickmocks.NewClickRepository(t)
+}
+
+// clickOf matches the click recorded on the link
+func clickOf(urlID uint64) any {
+	return mock.MatchedBy(func(c *model.ClickEntity) bool {
+		return c.URLID == urlID
+	})
+}
+
 func TestURLApp_CreateURLShortner(t *testing.T) {
 	type fields struct {
 		urlRepo *urlmocks.URLRepository
@@ -24,12 +96,13 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 		req *model.CreateURLShortnerRequest
 	}
 	tests := []struct {
-		name     string
-		fields   fields
-		args     args
-		mockCall func(f fields)
-		want     *model.GetURLResponse
-		wantErr  bool
+		name        string
+		fields      fields
+		args        args
+		mockCall    func(f fields)
+		want        *model.GetURLResponse
+		wantErr     bool
+		wantErrType constant.ErrorType
 	}{
 		{
 			name: "success: normalize URL, create then update",
@@ -43,12 +116,13 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 			mockCall: func(f fields) {
 				f.urlRepo.
 					On("Create", mock.Anything, mock.MatchedBy(func(ent *model.URLEntity) bool {
-						return ent.OriginalURL == "https://example.com"
+						return ent.OriginalURL == "https://example.com" && ent.RedirectType == http.StatusTemporaryRedirect
 					})).
 					Return(&model.URLEntity{
-						ID:          1,
-						OriginalURL: "https://example.com",
-						CreatedAt:   time.Now(),
+						ID:           1,
+						OriginalURL:  "https://example.com",
+						RedirectType: http.StatusTemporaryRedirect,
+						CreatedAt:    time.Now(),
 					}, nil).
 					Once()
 
@@ -58,20 +132,94 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 						return ent.ID == 1 && ent.ShortURL == "00001"
 					})).
 					Return(&model.URLEntity{
-						ID:          1,
-						ShortURL:    "00001",
-						OriginalURL: "https://example.com",
-						CreatedAt:   time.Now(),
-						UpdatedAt:   nil,
+						ID:           1,
+						ShortURL:     "00001",
+						OriginalURL:  "https://example.com",
+						RedirectType: http.StatusTemporaryRedirect,
+						CreatedAt:    time.Now(),
+						UpdatedAt:    nil,
+					}, nil).
+					Once()
+			},
+			want: &model.GetURLResponse{
+				ShortURL:     "00001",
+				OriginalURL:  "https://example.com",
+				RedirectType: http.StatusTemporaryRedirect,
+			},
+			wantErr: false,
+		},
+		{
+			name: "success: keep chosen permanent redirect type",
+			fields: fields{
+				urlRepo: urlmocks.NewURLRepository(t),
+			},
+			args: args{
+				ctx: context.Background(),
+				req: &model.CreateURLShortnerRequest{OriginalURL: "https://example.org", RedirectType: http.StatusPermanentRedirect},
+			},
+			mockCall: func(f fields) {
+				f.urlRepo.
+					On("Create", mock.Anything, mock.MatchedBy(func(ent *model.URLEntity) bool {
+						return ent.RedirectType == http.StatusPermanentRedirect
+					})).
+					Return(&model.URLEntity{
+						ID:           2,
+						OriginalURL:  "https://example.org",
+						RedirectType: http.StatusPermanentRedirect,
+						CreatedAt:    time.Now(),
+					}, nil).
+					Once()
+
+				f.urlRepo.
+					On("Update", mock.Anything, mock.AnythingOfType("*model.URLEntity")).
+					Return(&model.URLEntity{
+						ID:           2,
+						ShortURL:     "00002",
+						OriginalURL:  "https://example.org",
+						RedirectType: http.StatusPermanentRedirect,
+						CreatedAt:    time.Now(),
 					}, nil).
 					Once()
 			},
 			want: &model.GetURLResponse{
-				ShortURL:    "00001",
-				OriginalURL: "https://example.com",
+				ShortURL:     "00002",
+				OriginalURL:  "https://example.org",
+				RedirectType: http.StatusPermanentRedirect,
 			},
 			wantErr: false,
 		},
+		{
+			name: "error: unsupported redirect type -> ErrInvalidRequest",
+			fields: fields{
+				urlRepo: urlmocks.NewURLRepository(t),
+			},
+			args: args{
+				ctx: context.Background(),
+				req: &model.CreateURLShortnerRequest{OriginalURL: "example.com", RedirectType: http.StatusSeeOther},
+			},
+			want:        nil,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
+		{
+			name: "error: invalid schedule window -> ErrInvalidRequest",
+			fields: fields{
+				urlRepo: urlmocks.NewURLRepository(t),
+			},
+			args: args{
+				ctx: context.Background(),
+				req: &model.CreateURLShortnerRequest{
+					OriginalURL: "example.com",
+					Schedule: &model.URLSchedule{
+						TimeZone: "Mars/Olympus",
+						Windows:  []model.ScheduleWindow{{Start: "09:00", End: "17:00"}},
+					},
+				},
+			},
+			want:        nil,
+			wantErr:     true,
+			wantErrType: constant.ErrInvalidRequest,
+		},
 		{
 			name: "error: repository Create returns error -> ErrInternal",
 			fields: fields{
@@ -87,8 +235,9 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 					Return(nil, errors.New("db down")).
 					Once()
 			},
-			want:    nil,
-			wantErr: true,
+			want:        nil,
+			wantErr:     true,
+			wantErrType: constant.ErrInternal,
 		},
 		{
 			name: "error: repository Update returns error -> ErrInternal",
@@ -114,8 +263,37 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 					Return(nil, errors.New("update failed")).
 					Once()
 			},
-			want:    nil,
-			wantErr: true,
+			want:        nil,
+			wantErr:     true,
+			wantErrType: constant.ErrInternal,
+		},
+		{
+			name: "error: short url already taken -> ErrConflict",
+			fields: fields{
+				urlRepo: urlmocks.NewURLRepository(t),
+			},
+			args: args{
+				ctx: context.Background(),
+				req: &model.CreateURLShortnerRequest{OriginalURL: "baz.com"},
+			},
+			mockCall: func(f fields) {
+				f.urlRepo.
+					On("Create", mock.Anything, mock.AnythingOfType("*model.URLEntity")).
+					Return(&model.URLEntity{
+						ID:          11,
+						OriginalURL: "https://baz.com",
+						CreatedAt:   time.Now(),
+					}, nil).
+					Once()
+
+				f.urlRepo.
+					On("Update", mock.Anything, mock.AnythingOfType("*model.URLEntity")).
+					Return(nil, cerr.SetCustomError(constant.ErrConflict)).
+					Once()
+			},
+			want:        nil,
+			wantErr:     true,
+			wantErrType: constant.ErrConflict,
 		},
 	}
 	for _, tt := range tests {
@@ -126,7 +304,7 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 				// Ensure mock expectations are set before calling app
 				tt.mockCall(ttFields)
 			}
-			app := appurl.NewURLApplication(tt.fields.urlRepo)
+			app := newTestApp(t, tt.fields.urlRepo, testConfig())
 
 			got, err := app.CreateURLShortner(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
@@ -138,13 +316,13 @@ func TestURLApp_CreateURLShortner(t *testing.T) {
 				if !errors.As(err, &ce) {
 					t.Fatalf("error type = %T, want CustomError", err)
 				}
-				if ce.ErrorCode() != constant.ErrorTypeCode[constant.ErrInternal] {
-					t.Fatalf("error code = %s, want %s", ce.ErrorCode(), constant.ErrorTypeCode[constant.ErrInternal])
+				if ce.ErrorCode() != constant.ErrorTypeCode[tt.wantErrType] {
+					t.Fatalf("error code = %s, want %s", ce.ErrorCode(), constant.ErrorTypeCode[tt.wantErrType])
 				}
 				return
 			}
 
-			if got.ShortURL != tt.want.ShortURL || got.OriginalURL != tt.want.OriginalURL {
+			if got.ShortURL != tt.want.ShortURL || got.OriginalURL != tt.want.OriginalURL || got.RedirectType != tt.want.RedirectType {
 				t.Fatalf("CreateURLShortner() = %+v, want %+v", got, tt.want)
 			}
 		})
@@ -179,7 +357,7 @@ func TestURLApp_GetURLByShortURL(t *testing.T) {
 			mockCall: func(f fields) {
 				now := time.Now()
 				f.urlRepo.
-					On("Get", mock.Anything, &model.URLFilter{ShortURL: "0000Z"}).
+					On("Get", mock.Anything, resolveFilter("0000Z")).
 					Return(&model.URLEntity{
 						ID:          99,
 						UserID:      0,
@@ -189,6 +367,11 @@ func TestURLApp_GetURLByShortURL(t *testing.T) {
 						UpdatedAt:   nil,
 					}, nil).
 					Once()
+
+				f.urlRepo.
+					On("RecordClick", mock.Anything, clickOf(99), true).
+					Return(nil).
+					Once()
 			},
 			want: &model.GetURLResponse{
 				ShortURL:    "0000Z",
@@ -207,7 +390,7 @@ func TestURLApp_GetURLByShortURL(t *testing.T) {
 			},
 			mockCall: func(f fields) {
 				f.urlRepo.
-					On("Get", mock.Anything, &model.URLFilter{ShortURL: "xxxxx"}).
+					On("Get", mock.Anything, resolveFilter("xxxxx")).
 					Return(nil, nil).
 					Once()
 			},
@@ -225,7 +408,7 @@ func TestURLApp_GetURLByShortURL(t *testing.T) {
 			},
 			mockCall: func(f fields) {
 				f.urlRepo.
-					On("Get", mock.Anything, &model.URLFilter{ShortURL: "errxx"}).
+					On("Get", mock.Anything, resolveFilter("errxx")).
 					Return(nil, errors.New("query failed")).
 					Once()
 			},
@@ -241,9 +424,9 @@ func TestURLApp_GetURLByShortURL(t *testing.T) {
 				// Ensure mock expectations are set before calling app
 				tt.mockCall(ttFields)
 			}
-			app := appurl.NewURLApplication(tt.fields.urlRepo)
+			app := newTestApp(t, tt.fields.urlRepo, testConfig())
 
-			got, err := app.GetURLByShortURL(tt.args.ctx, tt.args.shortURL)
+			got, err := app.GetURLByShortURL(tt.args.ctx, &model.ResolveURLRequest{ShortURL: tt.args.shortURL})
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("GetURLByShortURL() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -276,3 +459,1031 @@ func TestURLApp_GetURLByShortURL(t *testing.T) {
 		})
 	}
 }
+
+func TestURLApp_GetURLInfo(t *testing.T) {
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.
+		On("Get", mock.Anything, resolveFilter("0000Z")).
+		Return(&model.URLEntity{
+			ID:           99,
+			UserID:       7,
+			ShortURL:     "0000Z",
+			OriginalURL:  "https://golang.org",
+			RedirectType: http.StatusFound,
+			Status:       constant.URLStatusActive,
+			ClickCount:   42,
+			CreatedAt:    time.Now(),
+		}, nil).
+		Once()
+	urlRepo.
+		On("Get", mock.Anything, resolveFilter("xxxxx")).
+		Return(nil, nil).
+		Once()
+
+	app := newTestApp(t, urlRepo, testConfig())
+
+	got, err := app.GetURLInfo(context.Background(), "", "0000Z")
+	if err != nil {
+		t.Fatalf("GetURLInfo() error = %v", err)
+	}
+	if got.UserID != 7 || got.Status != constant.URLStatusActive || got.ClickCount != 42 || got.OriginalURL != "https://golang.org" {
+		t.Fatalf("GetURLInfo() = %+v", got)
+	}
+
+	// info lookups must not count as a click, mock fails on unexpected RecordClick
+	_, err = app.GetURLInfo(context.Background(), "", "xxxxx")
+	var ce cerr.CustomError
+	if !errors.As(err, &ce) || ce.ErrorCode() != constant.ErrorTypeCode[constant.ErrNotFound] {
+		t.Fatalf("GetURLInfo() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestURLApp_GetURLPreview(t *testing.T) {
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html><head><title>\n  Go Docs  \n</title></head><body></body></html>"))
+	}))
+	defer destination.Close()
+
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.
+		On("Get", mock.Anything, resolveFilter("0000Z")).
+		Return(&model.URLEntity{
+			ID:          99,
+			UserID:      3,
+			ShortURL:    "0000Z",
+			OriginalURL: destination.URL,
+			Status:      constant.URLStatusActive,
+			CreatedAt:   time.Now(),
+		}, nil).
+		Once()
+
+	cfg := testConfig()
+	cfg.Server.ForcePreviewUntrusted = true
+	cfg.Server.TrustedUserIDs = []uint64{1}
+	// the test destination listens on loopback
+	cfg.Server.AllowPrivateDestinations = true
+	app := newTestApp(t, urlRepo, cfg)
+
+	got, err := app.GetURLPreview(context.Background(), "", "0000Z", false)
+	if err != nil {
+		t.Fatalf("GetURLPreview() error = %v", err)
+	}
+	if got.Title != "Go Docs" {
+		t.Fatalf("GetURLPreview() title = %q, want %q", got.Title, "Go Docs")
+	}
+	if !got.PreviewRequired {
+		t.Fatalf("GetURLPreview() PreviewRequired = false, want true for untrusted user")
+	}
+}
+
+func TestURLApp_GetURLPreview_HiddenDestination(t *testing.T) {
+	var fetched int
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		_, _ = w.Write([]byte("<html><head><title>Secret</title></head></html>"))
+	}))
+	defer destination.Close()
+
+	launch := time.Now().Add(time.Hour)
+	tests := []struct {
+		name      string
+		entity    model.URLEntity
+		unlocked  bool
+		wantFetch bool
+	}{
+		{name: "locked link", entity: model.URLEntity{PasswordHash: "$2a$10$hash"}},
+		{name: "unlocked link", entity: model.URLEntity{PasswordHash: "$2a$10$hash"}, unlocked: true, wantFetch: true},
+		{name: "single use link", entity: model.URLEntity{SingleUse: true}, unlocked: true},
+		{name: "link not active yet", entity: model.URLEntity{ActiveFrom: &launch}, unlocked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched = 0
+			entity := tt.entity
+			entity.ID = 99
+			entity.ShortURL = "0000Z"
+			entity.OriginalURL = destination.URL
+			entity.Status = constant.URLStatusActive
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000Z")).Return(&entity, nil).Once()
+
+			cfg := testConfig()
+			cfg.Server.AllowPrivateDestinations = true
+			got, err := newTestApp(t, urlRepo, cfg).GetURLPreview(context.Background(), "", "0000Z", tt.unlocked)
+			if err != nil {
+				t.Fatalf("GetURLPreview() error = %v", err)
+			}
+			if (fetched == 1) != tt.wantFetch || (got.Title != "") != tt.wantFetch {
+				t.Fatalf("GetURLPreview() fetched %d times with title %q, want fetch %v", fetched, got.Title, tt.wantFetch)
+			}
+		})
+	}
+}
+
+func TestURLApp_CreateURLShortnerBatch(t *testing.T) {
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.
+		On("CreateBatch", mock.Anything, mock.MatchedBy(func(ents []*model.URLEntity) bool {
+			return len(ents) == 2 && ents[0].OriginalURL == "https://a.com" && ents[1].OriginalURL == "https://c.com" &&
+				ents[1].RedirectType == http.StatusMovedPermanently
+		})).
+		Return(func(_ context.Context, ents []*model.URLEntity) ([]*model.URLEntity, error) {
+			for i, ent := range ents {
+				ent.ID = uint64(61 + i)
+			}
+			return ents, nil
+		}).
+		Once()
+	urlRepo.
+		On("UpdateBatch", mock.Anything, mock.MatchedBy(func(ents []*model.URLEntity) bool {
+			return len(ents) == 2 && ents[0].ShortURL == "0000z" && ents[1].ShortURL == "00010"
+		})).
+		Return(func(_ context.Context, ents []*model.URLEntity) ([]*model.URLEntity, error) {
+			return ents, nil
+		}).
+		Once()
+
+	app := newTestApp(t, urlRepo, testConfig())
+
+	got, err := app.CreateURLShortnerBatch(context.Background(), &model.CreateURLShortnerBatchRequest{
+		Items: []model.CreateURLShortnerRequest{
+			{OriginalURL: "a.com"},
+			{OriginalURL: "b.com", RedirectType: http.StatusSeeOther},
+			{OriginalURL: "https://c.com", RedirectType: http.StatusMovedPermanently},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateURLShortnerBatch() error = %v", err)
+	}
+	if got.Total != 3 || got.Created != 2 || got.Failed != 1 {
+		t.Fatalf("CreateURLShortnerBatch() counts = %d/%d/%d, want 3/2/1", got.Total, got.Created, got.Failed)
+	}
+	if got.Items[0].ShortURL != "0000z" || got.Items[2].ShortURL != "00010" {
+		t.Fatalf("CreateURLShortnerBatch() items = %+v", got.Items)
+	}
+	if got.Items[1].Error == nil || got.Items[1].Error.Code != constant.ErrorTypeCode[constant.ErrInvalidRequest] {
+		t.Fatalf("CreateURLShortnerBatch() item 1 error = %+v, want ErrInvalidRequest", got.Items[1].Error)
+	}
+
+	// batches above the configured size are rejected before touching the repository
+	_, err = app.CreateURLShortnerBatch(context.Background(), &model.CreateURLShortnerBatchRequest{
+		Items: make([]model.CreateURLShortnerRequest, 4),
+	})
+	var ce cerr.CustomError
+	if !errors.As(err, &ce) || ce.ErrorCode() != constant.ErrorTypeCode[constant.ErrInvalidRequest] {
+		t.Fatalf("CreateURLShortnerBatch() error = %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestURLApp_PasswordProtectedURL(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+	entity := &model.URLEntity{
+		ID:           5,
+		ShortURL:     "00005",
+		OriginalURL:  "https://intranet.example.com/doc",
+		RedirectType: http.StatusFound,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}
+
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, resolveFilter("00005")).Return(entity, nil).Twice()
+	urlRepo.On("Get", mock.Anything, resolveFilter("00005")).Return(entity, nil)
+	urlRepo.On("RecordClick", mock.Anything, clickOf(5), true).Return(nil).Once()
+
+	app := newTestApp(t, urlRepo, testConfig())
+	ctx := context.Background()
+
+	_, err = app.GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "00005"})
+	if !cerr.Is(err, constant.ErrPasswordRequired) {
+		t.Fatalf("GetURLByShortURL() locked error = %v, want ErrPasswordRequired", err)
+	}
+
+	got, err := app.GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "00005", Unlocked: true})
+	if err != nil || got.OriginalURL != entity.OriginalURL || !got.PasswordProtected {
+		t.Fatalf("GetURLByShortURL() unlocked = %+v, %v", got, err)
+	}
+
+	if err := app.UnlockURL(ctx, &model.UnlockURLRequest{ShortURL: "00005", Password: "s3cret", ClientKey: "10.0.0.1"}); err != nil {
+		t.Fatalf("UnlockURL() correct password error = %v", err)
+	}
+
+	// PasswordMaxAttempts is 2, the third try is rejected even with the right password
+	for i := 0; i < 2; i++ {
+		err := app.UnlockURL(ctx, &model.UnlockURLRequest{ShortURL: "00005", Password: "guess", ClientKey: "10.0.0.2"})
+		if !cerr.Is(err, constant.ErrUnauthorize) {
+			t.Fatalf("UnlockURL() wrong password error = %v, want ErrUnauthorize", err)
+		}
+	}
+	err = app.UnlockURL(ctx, &model.UnlockURLRequest{ShortURL: "00005", Password: "s3cret", ClientKey: "10.0.0.2"})
+	if !cerr.Is(err, constant.ErrTooManyRequests) {
+		t.Fatalf("UnlockURL() after failures error = %v, want ErrTooManyRequests", err)
+	}
+
+	// other visitors are not affected by the lockout
+	if err := app.UnlockURL(ctx, &model.UnlockURLRequest{ShortURL: "00005", Password: "s3cret", ClientKey: "10.0.0.3"}); err != nil {
+		t.Fatalf("UnlockURL() other client error = %v", err)
+	}
+}
+
+// stubEvents takes only the events it lists
+type stubEvents []string
+
+func (s stubEvents) Wants(_ context.Context, event string) bool {
+	for _, e := range s {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func TestURLApp_ClickEventsOnlyWhenWanted(t *testing.T) {
+	entity := &model.URLEntity{ID: 7, ShortURL: "00007", OriginalURL: "https://golang.org", CreatedAt: time.Now()}
+
+	tests := []struct {
+		name      string
+		events    stubEvents
+		wantEvent bool
+	}{
+		{name: "a publisher takes clicks", events: stubEvents{constant.EventLinkClicked}, wantEvent: true},
+		{name: "nobody takes clicks", events: stubEvents{constant.EventLinkCreated}, wantEvent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("00007")).Return(entity, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, clickOf(7), tt.wantEvent).Return(nil).Once()
+			ruleRepo := rulemocks.NewRuleRepository(t)
+			ruleRepo.On("List", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			app := appurl.NewURLApplication(urlRepo, ruleRepo, newVariantRepo(t, nil), newClickRepo(t), newDomainRepo(t), newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), tt.events, testConfig())
+
+			if _, err := app.GetURLByShortURL(context.Background(), &model.ResolveURLRequest{ShortURL: "00007"}); err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestURLApp_SingleUseURL(t *testing.T) {
+	entity := &model.URLEntity{
+		ID:           8,
+		ShortURL:     "00008",
+		OriginalURL:  "https://secret.example.com",
+		RedirectType: http.StatusFound,
+		Status:       constant.URLStatusActive,
+		SingleUse:    true,
+		CreatedAt:    time.Now(),
+	}
+
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, resolveFilter("00008")).Return(entity, nil).Twice()
+	urlRepo.On("Consume", mock.Anything, uint64(8)).Return(true, nil).Once()
+	urlRepo.On("RecordClick", mock.Anything, clickOf(8), true).Return(nil).Once()
+	// a concurrent visit loses the conditional update
+	urlRepo.On("Consume", mock.Anything, uint64(8)).Return(false, nil).Once()
+	urlRepo.On("Get", mock.Anything, resolveFilter("00009")).Return(&model.URLEntity{
+		ID:        9,
+		ShortURL:  "00009",
+		Status:    constant.URLStatusConsumed,
+		SingleUse: true,
+	}, nil).Once()
+
+	app := newTestApp(t, urlRepo, testConfig())
+	ctx := context.Background()
+
+	got, err := app.GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "00008"})
+	if err != nil || got.OriginalURL != entity.OriginalURL || !got.SingleUse {
+		t.Fatalf("GetURLByShortURL() first visit = %+v, %v", got, err)
+	}
+
+	_, err = app.GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "00008"})
+	if !cerr.Is(err, constant.ErrGone) {
+		t.Fatalf("GetURLByShortURL() concurrent visit error = %v, want ErrGone", err)
+	}
+
+	_, err = app.GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "00009"})
+	if !cerr.Is(err, constant.ErrGone) {
+		t.Fatalf("GetURLByShortURL() consumed link error = %v, want ErrGone", err)
+	}
+}
+
+func TestURLApp_GetURLByShortURL_ActivationWindow(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	launch := time.Date(2026, 3, 2, 9, 0, 0, 0, jakarta)
+	weekdays := &model.URLSchedule{
+		TimeZone: "Asia/Jakarta",
+		Windows:  []model.ScheduleWindow{{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}},
+	}
+	overnight := &model.URLSchedule{
+		TimeZone: "Asia/Jakarta",
+		Windows:  []model.ScheduleWindow{{Days: []string{"fri"}, Start: "22:00", End: "02:00"}},
+	}
+
+	tests := []struct {
+		name        string
+		entity      model.URLEntity
+		now         time.Time
+		wantURL     string
+		wantErrType constant.ErrorType
+	}{
+		{
+			name:        "before launch without fallback -> ErrNotAvailable",
+			entity:      model.URLEntity{ActiveFrom: &launch},
+			now:         launch.Add(-time.Minute),
+			wantErrType: constant.ErrNotAvailable,
+		},
+		{
+			name:    "before launch with fallback -> fallback destination",
+			entity:  model.URLEntity{ActiveFrom: &launch, FallbackURL: "https://example.com/soon"},
+			now:     launch.Add(-time.Minute),
+			wantURL: "https://example.com/soon",
+		},
+		{
+			name:    "after launch -> destination",
+			entity:  model.URLEntity{ActiveFrom: &launch},
+			now:     launch,
+			wantURL: "https://example.com/launch",
+		},
+		{
+			name:        "after active until -> ErrNotAvailable",
+			entity:      model.URLEntity{ActiveUntil: &launch},
+			now:         launch,
+			wantErrType: constant.ErrNotAvailable,
+		},
+		{
+			name:    "inside weekday window -> destination",
+			entity:  model.URLEntity{Schedule: weekdays},
+			now:     time.Date(2026, 3, 4, 16, 59, 0, 0, jakarta),
+			wantURL: "https://example.com/launch",
+		},
+		{
+			name:        "weekday window evaluated in its time zone -> ErrNotAvailable",
+			entity:      model.URLEntity{Schedule: weekdays},
+			now:         time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC), // 17:30 in Jakarta
+			wantErrType: constant.ErrNotAvailable,
+		},
+		{
+			name:        "weekend -> ErrNotAvailable",
+			entity:      model.URLEntity{Schedule: weekdays},
+			now:         time.Date(2026, 3, 7, 10, 0, 0, 0, jakarta),
+			wantErrType: constant.ErrNotAvailable,
+		},
+		{
+			name:    "overnight window after midnight -> destination",
+			entity:  model.URLEntity{Schedule: overnight},
+			now:     time.Date(2026, 3, 7, 1, 0, 0, 0, jakarta),
+			wantURL: "https://example.com/launch",
+		},
+		{
+			name:        "overnight window on the wrong night -> ErrNotAvailable",
+			entity:      model.URLEntity{Schedule: overnight},
+			now:         time.Date(2026, 3, 6, 1, 0, 0, 0, jakarta),
+			wantErrType: constant.ErrNotAvailable,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			entity := tt.entity
+			entity.ID = 12
+			entity.ShortURL = "0000C"
+			entity.OriginalURL = "https://example.com/launch"
+			entity.Status = constant.URLStatusActive
+
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000C")).Return(&entity, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, clickOf(12), true).Return(nil).Maybe()
+
+			app := newTestApp(t, urlRepo, testConfig())
+			app.(*appurl.URLAppImpl).Now = func() time.Time { return tt.now }
+
+			got, err := app.GetURLByShortURL(context.Background(), &model.ResolveURLRequest{ShortURL: "0000C"})
+			if tt.wantErrType != constant.Successful {
+				if !cerr.Is(err, tt.wantErrType) {
+					t.Fatalf("GetURLByShortURL() error = %v, want %s", err, constant.ErrorTypeMessage[tt.wantErrType])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+			if got.OriginalURL != tt.wantURL {
+				t.Fatalf("GetURLByShortURL() OriginalURL = %s, want %s", got.OriginalURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestURLApp_GetURLByShortURL_TargetingRules(t *testing.T) {
+	const (
+		iphone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+		android = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+		desktop = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	)
+	rules := []*model.RuleEntity{
+		{ID: 1, URLID: 13, Priority: 1, OS: "ios", DestinationURL: "https://apps.apple.com/app/id1"},
+		{ID: 2, URLID: 13, Priority: 2, OS: "android", DestinationURL: "https://play.google.com/store/apps/details?id=app"},
+		{ID: 3, URLID: 13, Priority: 3, Country: "ID", Language: "id", DestinationURL: "https://example.com/id"},
+	}
+
+	tests := []struct {
+		name           string
+		country        string
+		userAgent      string
+		acceptLanguage string
+		wantURL        string
+	}{
+		{name: "ios -> app store", userAgent: iphone, wantURL: "https://apps.apple.com/app/id1"},
+		{name: "android -> play store", userAgent: android, wantURL: "https://play.google.com/store/apps/details?id=app"},
+		{name: "first matching rule wins", country: "ID", userAgent: iphone, acceptLanguage: "id", wantURL: "https://apps.apple.com/app/id1"},
+		{name: "country and language -> localized page", country: "ID", userAgent: desktop, acceptLanguage: "id-ID,id;q=0.9,en;q=0.8", wantURL: "https://example.com/id"},
+		{name: "country without language -> original", country: "ID", userAgent: desktop, acceptLanguage: "en-US", wantURL: "https://example.com/app"},
+		{name: "no match -> original", country: "SG", userAgent: desktop, wantURL: "https://example.com/app"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000D")).Return(&model.URLEntity{
+				ID: 13, ShortURL: "0000D", OriginalURL: "https://example.com/app", Status: constant.URLStatusActive,
+			}, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, clickOf(13), true).Return(nil).Once()
+			ruleRepo := rulemocks.NewRuleRepository(t)
+			ruleRepo.On("List", mock.Anything, uint64(13)).Return(rules, nil).Once()
+
+			app := appurl.NewURLApplication(urlRepo, ruleRepo, newVariantRepo(t, nil), newClickRepo(t), newDomainRepo(t), newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(tt.country), nil, testConfig())
+
+			got, err := app.GetURLByShortURL(context.Background(), &model.ResolveURLRequest{
+				ShortURL:       "0000D",
+				ClientIP:       "203.0.113.7",
+				UserAgent:      tt.userAgent,
+				AcceptLanguage: tt.acceptLanguage,
+			})
+			if err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+			if got.OriginalURL != tt.wantURL {
+				t.Fatalf("GetURLByShortURL() OriginalURL = %s, want %s", got.OriginalURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestURLApp_SplitURL(t *testing.T) {
+	variants := []*model.VariantEntity{
+		{ID: 21, URLID: 14, DestinationURL: "https://example.com/a", Weight: 3},
+		{ID: 22, URLID: 14, DestinationURL: "https://example.com/b", Weight: 1},
+	}
+	entity := &model.URLEntity{
+		ID: 14, ShortURL: "0000E", OriginalURL: "https://example.com", Status: constant.URLStatusActive, ClickCount: 4,
+	}
+
+	t.Run("create saves the variants", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *model.URLEntity) bool {
+			return e.StickyVariant
+		})).Return(&model.URLEntity{ID: 14, OriginalURL: "https://example.com", StickyVariant: true}, nil).Once()
+		urlRepo.On("Update", mock.Anything, mock.Anything).Return(&model.URLEntity{ID: 14, ShortURL: "0000E", OriginalURL: "https://example.com", StickyVariant: true}, nil).Once()
+		variantRepo := variantmocks.NewVariantRepository(t)
+		variantRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(v []*model.VariantEntity) bool {
+			return len(v) == 2 && v[0].URLID == 14 && v[1].Weight == 1
+		})).Return(variants, nil).Once()
+
+		app := appurl.NewURLApplication(urlRepo, rulemocks.NewRuleRepository(t), variantRepo, newClickRepo(t), newDomainRepo(t), newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, testConfig())
+
+		got, err := app.CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+			OriginalURL: "https://example.com",
+			Variants: []model.VariantRequest{
+				{DestinationURL: "https://example.com/a", Weight: 3},
+				{DestinationURL: "https://example.com/b", Weight: 1},
+			},
+			StickyVariant: true,
+		})
+		if err != nil {
+			t.Fatalf("CreateURLShortner() error = %v", err)
+		}
+		if len(got.Variants) != 2 || got.Variants[0].ID != 21 || !got.StickyVariant {
+			t.Fatalf("CreateURLShortner() = %+v, want 2 sticky variants", got)
+		}
+	})
+
+	t.Run("create rejects invalid variants", func(t *testing.T) {
+		app := newTestApp(t, urlmocks.NewURLRepository(t), testConfig())
+
+		_, err := app.CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+			OriginalURL: "https://example.com",
+			Variants:    []model.VariantRequest{{DestinationURL: "https://example.com/a", Weight: 0}},
+		})
+		if !cerr.Is(err, constant.ErrInvalidRequest) {
+			t.Fatalf("CreateURLShortner() error = %v, want ErrInvalidRequest", err)
+		}
+	})
+
+	resolveTests := []struct {
+		name          string
+		sticky        bool
+		pick          int
+		cookieVariant uint64
+		wantVariant   uint64
+	}{
+		{name: "pick inside the first weight -> first variant", pick: 2, wantVariant: 21},
+		{name: "pick past the first weight -> second variant", pick: 3, wantVariant: 22},
+		{name: "sticky link keeps the previous variant", sticky: true, pick: 0, cookieVariant: 22, wantVariant: 22},
+		{name: "cookie ignored when the link is not sticky", pick: 0, cookieVariant: 22, wantVariant: 21},
+		{name: "unknown sticky variant -> weighted pick", sticky: true, pick: 3, cookieVariant: 99, wantVariant: 22},
+	}
+	for _, tt := range resolveTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			linked := *entity
+			linked.StickyVariant = tt.sticky
+
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000E")).Return(&linked, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, mock.MatchedBy(func(c *model.ClickEntity) bool {
+				return c.URLID == 14 && c.VariantID != nil && *c.VariantID == tt.wantVariant
+			}), true).Return(nil).Once()
+			ruleRepo := rulemocks.NewRuleRepository(t)
+			ruleRepo.On("List", mock.Anything, uint64(14)).Return(nil, nil).Once()
+
+			app := appurl.NewURLApplication(urlRepo, ruleRepo, newVariantRepo(t, variants), newClickRepo(t), newDomainRepo(t), newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, testConfig())
+			app.(*appurl.URLAppImpl).RandIntn = func(n int) int {
+				if n != 4 {
+					t.Fatalf("RandIntn(%d), want total weight 4", n)
+				}
+				return tt.pick
+			}
+
+			got, err := app.GetURLByShortURL(context.Background(), &model.ResolveURLRequest{ShortURL: "0000E", VariantID: tt.cookieVariant})
+			if err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+			if got.VariantID != tt.wantVariant {
+				t.Fatalf("GetURLByShortURL() VariantID = %d, want %d", got.VariantID, tt.wantVariant)
+			}
+		})
+	}
+
+	t.Run("stats report clicks per variant", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Get", mock.Anything, resolveFilter("0000E")).Return(entity, nil).Once()
+		clickRepo := clickmocks.NewClickRepository(t)
+		clickRepo.On("CountByVariant", mock.Anything, uint64(14)).Return([]*model.VariantClickCount{{VariantID: 21, Clicks: 3}}, nil).Once()
+
+		app := appurl.NewURLApplication(urlRepo, rulemocks.NewRuleRepository(t), newVariantRepo(t, variants), clickRepo, newDomainRepo(t), newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, testConfig())
+
+		got, err := app.GetURLStats(context.Background(), "", "0000E")
+		if err != nil {
+			t.Fatalf("GetURLStats() error = %v", err)
+		}
+		if got.TotalClicks != 4 || len(got.Variants) != 2 || got.Variants[0].Clicks != 3 || got.Variants[1].Clicks != 0 {
+			t.Fatalf("GetURLStats() = %+v", got)
+		}
+	})
+}
+
+func TestURLApp_CreateURLShortner_UTM(t *testing.T) {
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *model.URLEntity) bool {
+		return e.OriginalURL == "https://example.com/landing?ref=ad&utm_campaign=launch&utm_medium=email&utm_source=newsletter" &&
+			e.ForwardQuery && e.QueryConflict == constant.QueryConflictKeep
+	})).Return(&model.URLEntity{ID: 15}, nil).Once()
+	urlRepo.On("Update", mock.Anything, mock.Anything).Return(&model.URLEntity{ID: 15, ShortURL: "0000F"}, nil).Once()
+
+	cfg := testConfig()
+	cfg.Server.DefaultQueryConflict = constant.QueryConflictKeep
+	app := newTestApp(t, urlRepo, cfg)
+
+	_, err := app.CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+		OriginalURL:  "https://example.com/landing?ref=ad&utm_source=old",
+		UTM:          &model.UTMParams{Source: "newsletter", Medium: "email", Campaign: "launch"},
+		ForwardQuery: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateURLShortner() error = %v", err)
+	}
+
+	_, err = app.CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+		OriginalURL:   "https://example.com",
+		ForwardQuery:  true,
+		QueryConflict: "merge",
+	})
+	if !cerr.Is(err, constant.ErrInvalidRequest) {
+		t.Fatalf("CreateURLShortner() error = %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestURLApp_GetURLByShortURL_ForwardQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		forward  bool
+		conflict string
+		query    string
+		wantURL  string
+	}{
+		{name: "query ignored when not forwarded", query: "ref=x", wantURL: "https://example.com/docs?lang=en"},
+		{name: "new parameter appended", forward: true, conflict: constant.QueryConflictKeep, query: "ref=x", wantURL: "https://example.com/docs?lang=en&ref=x"},
+		{name: "keep destination value", forward: true, conflict: constant.QueryConflictKeep, query: "lang=id", wantURL: "https://example.com/docs?lang=en"},
+		{name: "override destination value", forward: true, conflict: constant.QueryConflictOverride, query: "lang=id", wantURL: "https://example.com/docs?lang=id"},
+		{name: "append to destination value", forward: true, conflict: constant.QueryConflictAppend, query: "lang=id", wantURL: "https://example.com/docs?lang=en&lang=id"},
+		{name: "empty query leaves destination untouched", forward: true, conflict: constant.QueryConflictKeep, wantURL: "https://example.com/docs?lang=en"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000F")).Return(&model.URLEntity{
+				ID: 15, ShortURL: "0000F", OriginalURL: "https://example.com/docs?lang=en", Status: constant.URLStatusActive,
+				ForwardQuery: tt.forward, QueryConflict: tt.conflict,
+			}, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, clickOf(15), true).Return(nil).Once()
+
+			app := newTestApp(t, urlRepo, testConfig())
+
+			query, _ := neturl.ParseQuery(tt.query)
+			got, err := app.GetURLByShortURL(context.Background(), &model.ResolveURLRequest{ShortURL: "0000F", Query: query})
+			if err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+			if got.OriginalURL != tt.wantURL {
+				t.Fatalf("GetURLByShortURL() OriginalURL = %s, want %s", got.OriginalURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestURLApp_GetURLByShortURL_ForwardPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		forward     bool
+		path        string
+		wantURL     string
+		wantErrType constant.ErrorType
+	}{
+		{name: "path appended", destination: "https://docs.example.com/v2", forward: true, path: "guide/install", wantURL: "https://docs.example.com/v2/guide/install"},
+		{name: "trailing slashes kept once", destination: "https://docs.example.com/v2/", forward: true, path: "guide/", wantURL: "https://docs.example.com/v2/guide/"},
+		{name: "destination query kept", destination: "https://docs.example.com/v2?lang=en", forward: true, path: "guide", wantURL: "https://docs.example.com/v2/guide?lang=en"},
+		{name: "dot segments can't climb above the destination", destination: "https://docs.example.com/v2", forward: true, path: "../../admin", wantURL: "https://docs.example.com/v2/admin"},
+		{name: "host can't be escaped", destination: "https://docs.example.com", forward: true, path: "/evil.example.com/x", wantURL: "https://docs.example.com/evil.example.com/x"},
+		{name: "query characters escaped", destination: "https://docs.example.com", forward: true, path: "a?b#c", wantURL: "https://docs.example.com/a%3Fb%23c"},
+		{name: "backslash rejected", destination: "https://docs.example.com", forward: true, path: `\evil.example.com`, wantErrType: constant.ErrInvalidRequest},
+		{name: "path on a link not forwarding it -> ErrNotFound", destination: "https://docs.example.com", path: "guide", wantErrType: constant.ErrNotFound},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000G")).Return(&model.URLEntity{
+				ID: 16, ShortURL: "0000G", OriginalURL: tt.destination, Status: constant.URLStatusActive, ForwardPath: tt.forward,
+			}, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, clickOf(16), true).Return(nil).Maybe()
+
+			app := newTestApp(t, urlRepo, testConfig())
+
+			got, err := app.GetURLByShortURL(context.Background(), &model.ResolveURLRequest{ShortURL: "0000G", Path: tt.path})
+			if tt.wantErrType != constant.Successful {
+				if !cerr.Is(err, tt.wantErrType) {
+					t.Fatalf("GetURLByShortURL() error = %v, want %s", err, constant.ErrorTypeMessage[tt.wantErrType])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+			if got.OriginalURL != tt.wantURL {
+				t.Fatalf("GetURLByShortURL() OriginalURL = %s, want %s", got.OriginalURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestURLApp_CustomDomain(t *testing.T) {
+	verifiedAt := time.Now()
+	acme := &model.DomainEntity{ID: 3, Host: "go.acme.com", VerifiedAt: &verifiedAt}
+	pending := &model.DomainEntity{ID: 4, Host: "go.pending.com"}
+
+	newApp := func(t *testing.T, urlRepo *urlmocks.URLRepository) appurl.URLApp {
+		domainRepo := domainmocks.NewDomainRepository(t)
+		domainRepo.On("Get", mock.Anything, &model.DomainFilter{Host: "go.acme.com"}).Return(acme, nil).Maybe()
+		domainRepo.On("Get", mock.Anything, &model.DomainFilter{ID: 3}).Return(acme, nil).Maybe()
+		domainRepo.On("Get", mock.Anything, &model.DomainFilter{Host: "go.pending.com"}).Return(pending, nil).Maybe()
+		domainRepo.On("Get", mock.Anything, &model.DomainFilter{Host: "short.example.com"}).Return(nil, nil).Maybe()
+		ruleRepo := rulemocks.NewRuleRepository(t)
+		ruleRepo.On("List", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+		return appurl.NewURLApplication(urlRepo, ruleRepo, newVariantRepo(t, nil), newClickRepo(t), domainRepo, newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, testConfig())
+	}
+
+	t.Run("create on a verified domain", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *model.URLEntity) bool {
+			return e.DomainID == 3
+		})).Return(&model.URLEntity{ID: 17, DomainID: 3}, nil).Once()
+		urlRepo.On("Update", mock.Anything, mock.Anything).Return(&model.URLEntity{ID: 17, DomainID: 3, ShortURL: "0000H"}, nil).Once()
+
+		got, err := newApp(t, urlRepo).CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+			OriginalURL: "https://acme.com/pricing",
+			Domain:      "Go.Acme.com",
+		})
+		if err != nil {
+			t.Fatalf("CreateURLShortner() error = %v", err)
+		}
+		if got.Domain != "go.acme.com" || got.ShortLink != "https://go.acme.com/url/0000H" {
+			t.Fatalf("CreateURLShortner() = %+v, want short link on go.acme.com", got)
+		}
+	})
+
+	t.Run("create on an unverified domain -> ErrInvalidRequest", func(t *testing.T) {
+		_, err := newApp(t, urlmocks.NewURLRepository(t)).CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+			OriginalURL: "https://pending.com",
+			Domain:      "go.pending.com",
+		})
+		if !cerr.Is(err, constant.ErrInvalidRequest) {
+			t.Fatalf("CreateURLShortner() error = %v, want ErrInvalidRequest", err)
+		}
+	})
+
+	resolveTests := []struct {
+		name         string
+		host         string
+		wantDomainID uint64
+	}{
+		{name: "custom domain host", host: "go.acme.com", wantDomainID: 3},
+		{name: "unverified domain served as default", host: "go.pending.com", wantDomainID: 0},
+		{name: "unknown host served as default", host: "short.example.com", wantDomainID: 0},
+	}
+	for _, tt := range resolveTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, &model.URLFilter{ShortURL: "0000H", DomainID: &tt.wantDomainID}).Return(&model.URLEntity{
+				ID: 17, DomainID: tt.wantDomainID, ShortURL: "0000H", OriginalURL: "https://acme.com/pricing", Status: constant.URLStatusActive,
+			}, nil).Once()
+			urlRepo.On("RecordClick", mock.Anything, clickOf(17), true).Return(nil).Once()
+
+			got, err := newApp(t, urlRepo).GetURLByShortURL(context.Background(), &model.ResolveURLRequest{ShortURL: "0000H", Host: tt.host})
+			if err != nil {
+				t.Fatalf("GetURLByShortURL() error = %v", err)
+			}
+			if got.OriginalURL != "https://acme.com/pricing" {
+				t.Fatalf("GetURLByShortURL() OriginalURL = %s", got.OriginalURL)
+			}
+		})
+	}
+
+	// the same code may exist on several domains, every lookup is made on the domain of the host
+	t.Run("lookups by code are scoped to the domain of the host", func(t *testing.T) {
+		acmeID := uint64(3)
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Get", mock.Anything, &model.URLFilter{ShortURL: "0000H", DomainID: &acmeID}).Return(&model.URLEntity{
+			ID: 17, DomainID: 3, ShortURL: "0000H", OriginalURL: "https://acme.com/pricing", Status: constant.URLStatusActive,
+		}, nil)
+		urlRepo.On("Get", mock.Anything, resolveFilter("0000H")).Return(nil, nil)
+		urlRepo.On("Delete", mock.Anything, uint64(17)).Return(nil).Once()
+		app := newApp(t, urlRepo)
+		ctx := context.Background()
+
+		info, err := app.GetURLInfo(ctx, "go.acme.com", "0000H")
+		if err != nil || info.Domain != "go.acme.com" {
+			t.Fatalf("GetURLInfo() = %+v, %v, want the link of go.acme.com", info, err)
+		}
+		if _, err := app.GetURLInfo(ctx, "short.example.com", "0000H"); !cerr.Is(err, constant.ErrNotFound) {
+			t.Fatalf("GetURLInfo() on the default domain error = %v, want ErrNotFound", err)
+		}
+		if err := app.UnlockURL(ctx, &model.UnlockURLRequest{ShortURL: "0000H", Host: "short.example.com"}); !cerr.Is(err, constant.ErrNotFound) {
+			t.Fatalf("UnlockURL() on the default domain error = %v, want ErrNotFound", err)
+		}
+		if err := app.DeleteURL(ctx, "go.acme.com", "0000H"); err != nil {
+			t.Fatalf("DeleteURL() error = %v", err)
+		}
+	})
+}
+
+func TestURLApp_AbsoluteLinks(t *testing.T) {
+	verifiedAt := time.Now()
+	domains := map[uint64]*model.DomainEntity{
+		3: {ID: 3, Host: "go.acme.com", VerifiedAt: &verifiedAt},
+		5: {ID: 5, Host: "l.example.org", VerifiedAt: &verifiedAt},
+	}
+
+	tests := []struct {
+		name          string
+		domainID      uint64
+		wantShortLink string
+	}{
+		{name: "default domain on the public base url", wantShortLink: "https://sho.rt/url/0000J"},
+		{name: "custom domain", domainID: 3, wantShortLink: "https://go.acme.com/url/0000J"},
+		{name: "custom domain with configured base", domainID: 5, wantShortLink: "http://l.example.org:8080/url/0000J"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, resolveFilter("0000J")).Return(&model.URLEntity{
+				ID: 19, DomainID: tt.domainID, ShortURL: "0000J", OriginalURL: "https://example.com", Status: constant.URLStatusActive,
+			}, nil).Once()
+			domainRepo := domainmocks.NewDomainRepository(t)
+			if tt.domainID != 0 {
+				domainRepo.On("Get", mock.Anything, &model.DomainFilter{ID: tt.domainID}).Return(domains[tt.domainID], nil).Once()
+			}
+
+			cfg := testConfig()
+			cfg.Server.PublicBaseURL = "https://sho.rt"
+			cfg.Server.DomainBaseURLs = map[string]string{"l.example.org": "http://l.example.org:8080"}
+			app := appurl.NewURLApplication(urlRepo, rulemocks.NewRuleRepository(t), newVariantRepo(t, nil), newClickRepo(t), domainRepo, newTagRepo(t), campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, cfg)
+
+			got, err := app.GetURLInfo(context.Background(), "", "0000J")
+			if err != nil {
+				t.Fatalf("GetURLInfo() error = %v", err)
+			}
+			if got.ShortLink != tt.wantShortLink {
+				t.Fatalf("GetURLInfo() ShortLink = %s, want %s", got.ShortLink, tt.wantShortLink)
+			}
+			if got.QRURL != tt.wantShortLink+"/qr" || got.InfoURL != tt.wantShortLink+"/info" {
+				t.Fatalf("GetURLInfo() QRURL = %s, InfoURL = %s", got.QRURL, got.InfoURL)
+			}
+		})
+	}
+}
+
+func TestURLApp_TagsAndCampaigns(t *testing.T) {
+	newApp := func(t *testing.T, urlRepo *urlmocks.URLRepository, tagRepo *tagmocks.TagRepository) appurl.URLApp {
+		campaignRepo := campaignmocks.NewCampaignRepository(t)
+		campaignRepo.On("Get", mock.Anything, &model.CampaignFilter{ID: 7}).Return(&model.CampaignEntity{ID: 7, Name: "spring"}, nil).Maybe()
+		campaignRepo.On("Get", mock.Anything, &model.CampaignFilter{ID: 8}).Return(nil, nil).Maybe()
+		return appurl.NewURLApplication(urlRepo, rulemocks.NewRuleRepository(t), newVariantRepo(t, nil), newClickRepo(t), newDomainRepo(t), tagRepo, campaignRepo, stubLocator(""), nil, testConfig())
+	}
+
+	t.Run("create in a campaign with new and existing tags", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Create", mock.Anything, mock.MatchedBy(func(e *model.URLEntity) bool {
+			return e.CampaignID == 7
+		})).Return(&model.URLEntity{ID: 21, CampaignID: 7}, nil).Once()
+		urlRepo.On("Update", mock.Anything, mock.Anything).Return(&model.URLEntity{ID: 21, CampaignID: 7, ShortURL: "0000L"}, nil).Once()
+		tagRepo := tagmocks.NewTagRepository(t)
+		tagRepo.On("Get", mock.Anything, &model.TagFilter{Name: "email"}).Return(&model.TagEntity{ID: 2, Name: "email"}, nil).Once()
+		tagRepo.On("Get", mock.Anything, &model.TagFilter{Name: "q2"}).Return(nil, nil).Once()
+		tagRepo.On("Create", mock.Anything, &model.TagEntity{Name: "q2"}).Return(&model.TagEntity{ID: 9, Name: "q2"}, nil).Once()
+		tagRepo.On("SetURLTags", mock.Anything, uint64(21), []uint64{2, 9}).Return(nil).Once()
+
+		got, err := newApp(t, urlRepo, tagRepo).CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+			OriginalURL: "https://example.com",
+			CampaignID:  7,
+			Tags:        []string{" Email", "q2", "EMAIL"},
+		})
+		if err != nil {
+			t.Fatalf("CreateURLShortner() error = %v", err)
+		}
+		if got.CampaignID != 7 || !reflect.DeepEqual(got.Tags, []string{"email", "q2"}) {
+			t.Fatalf("CreateURLShortner() = %+v, want campaign 7 tagged email and q2", got)
+		}
+	})
+
+	t.Run("create in an unknown campaign -> ErrInvalidRequest", func(t *testing.T) {
+		_, err := newApp(t, urlmocks.NewURLRepository(t), tagmocks.NewTagRepository(t)).CreateURLShortner(context.Background(), &model.CreateURLShortnerRequest{
+			OriginalURL: "https://example.com",
+			CampaignID:  8,
+		})
+		if !cerr.Is(err, constant.ErrInvalidRequest) {
+			t.Fatalf("CreateURLShortner() error = %v, want ErrInvalidRequest", err)
+		}
+	})
+
+	t.Run("list by campaign and tag", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("List", mock.Anything, &model.URLFilter{CampaignID: 7, Tag: "email", Limit: 100, Offset: 40}).Return([]*model.URLEntity{
+			{ID: 22, CampaignID: 7, ShortURL: "0000M", Status: constant.URLStatusActive},
+			{ID: 21, CampaignID: 7, ShortURL: "0000L", Status: constant.URLStatusActive},
+		}, nil).Once()
+		tagRepo := tagmocks.NewTagRepository(t)
+		tagRepo.On("ListByURLs", mock.Anything, []uint64{22, 21}).Return([]*model.URLTag{
+			{URLID: 21, Name: "email"}, {URLID: 22, Name: "email"}, {URLID: 21, Name: "q2"},
+		}, nil).Once()
+
+		got, err := newApp(t, urlRepo, tagRepo).ListURLs(context.Background(), &model.ListURLRequest{
+			CampaignID: 7, Tag: "Email", Limit: 500, Offset: 40,
+		})
+		if err != nil {
+			t.Fatalf("ListURLs() error = %v", err)
+		}
+		if got.Limit != 100 || len(got.Items) != 2 {
+			t.Fatalf("ListURLs() = %+v, want 2 items and limit capped to 100", got)
+		}
+		if !reflect.DeepEqual(got.Items[1].Tags, []string{"email", "q2"}) {
+			t.Fatalf("ListURLs() tags of 0000L = %v", got.Items[1].Tags)
+		}
+	})
+
+	t.Run("set tags with an empty name -> ErrInvalidRequest", func(t *testing.T) {
+		_, err := newApp(t, urlmocks.NewURLRepository(t), tagmocks.NewTagRepository(t)).SetURLTags(context.Background(), "", "0000L", &model.SetURLTagsRequest{
+			Tags: []string{"email", " "},
+		})
+		if !cerr.Is(err, constant.ErrInvalidRequest) {
+			t.Fatalf("SetURLTags() error = %v, want ErrInvalidRequest", err)
+		}
+	})
+
+	t.Run("move to no campaign", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Get", mock.Anything, resolveFilter("0000L")).Return(&model.URLEntity{ID: 21, CampaignID: 7, ShortURL: "0000L"}, nil).Once()
+		urlRepo.On("SetCampaign", mock.Anything, uint64(21), uint64(0)).Return(nil).Once()
+
+		if err := newApp(t, urlRepo, newTagRepo(t)).SetURLCampaign(context.Background(), "", "0000L", &model.SetURLCampaignRequest{}); err != nil {
+			t.Fatalf("SetURLCampaign() error = %v", err)
+		}
+	})
+}
+
+func TestURLApp_LinkLifecycle(t *testing.T) {
+	newApp := func(t *testing.T, urlRepo *urlmocks.URLRepository, tagRepo *tagmocks.TagRepository) appurl.URLApp {
+		ruleRepo := rulemocks.NewRuleRepository(t)
+		ruleRepo.On("List", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+		return appurl.NewURLApplication(urlRepo, ruleRepo, newVariantRepo(t, nil), newClickRepo(t), newDomainRepo(t), tagRepo, campaignmocks.NewCampaignRepository(t), stubLocator(""), nil, testConfig())
+	}
+	ctx := context.Background()
+
+	t.Run("short url is saved last so link.created sees the tags", func(t *testing.T) {
+		var calls []string
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Create", mock.Anything, mock.Anything).Return(&model.URLEntity{ID: 30}, nil).Once()
+		urlRepo.On("Update", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+			calls = append(calls, "Update")
+		}).Return(&model.URLEntity{ID: 30, ShortURL: "0000U", OriginalURL: "https://example.com"}, nil).Once()
+		tagRepo := newTagRepo(t)
+		tagRepo.On("Get", mock.Anything, &model.TagFilter{Name: "promo"}).Return(&model.TagEntity{ID: 4, Name: "promo"}, nil).Once()
+		tagRepo.On("SetURLTags", mock.Anything, uint64(30), []uint64{4}).Run(func(mock.Arguments) {
+			calls = append(calls, "SetURLTags")
+		}).Return(nil).Once()
+
+		got, err := newApp(t, urlRepo, tagRepo).CreateURLShortner(ctx, &model.CreateURLShortnerRequest{OriginalURL: "https://example.com", Tags: []string{"promo"}})
+		if err != nil {
+			t.Fatalf("CreateURLShortner() error = %v", err)
+		}
+		if !reflect.DeepEqual(calls, []string{"SetURLTags", "Update"}) {
+			t.Fatalf("calls = %v, want the tags saved before the short url", calls)
+		}
+		if got.ShortURL != "0000U" || !reflect.DeepEqual(got.Tags, []string{"promo"}) {
+			t.Fatalf("CreateURLShortner() = %+v", got)
+		}
+	})
+
+	t.Run("click of a single use link is recorded after consuming it", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Get", mock.Anything, resolveFilter("0000V")).Return(&model.URLEntity{
+			ID: 31, ShortURL: "0000V", OriginalURL: "https://example.com", Status: constant.URLStatusActive, SingleUse: true,
+		}, nil).Once()
+		urlRepo.On("Consume", mock.Anything, uint64(31)).Return(true, nil).Once()
+		urlRepo.On("RecordClick", mock.Anything, clickOf(31), true).Return(nil).Once()
+
+		if _, err := newApp(t, urlRepo, newTagRepo(t)).GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "0000V"}); err != nil {
+			t.Fatalf("GetURLByShortURL() error = %v", err)
+		}
+	})
+
+	t.Run("failing to record the click still redirects", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Get", mock.Anything, resolveFilter("0000T")).Return(&model.URLEntity{
+			ID: 35, ShortURL: "0000T", OriginalURL: "https://example.com", Status: constant.URLStatusActive,
+		}, nil).Once()
+		urlRepo.On("RecordClick", mock.Anything, clickOf(35), true).Return(errors.New("db down")).Once()
+
+		got, err := newApp(t, urlRepo, newTagRepo(t)).GetURLByShortURL(ctx, &model.ResolveURLRequest{ShortURL: "0000T"})
+		if err != nil || got.OriginalURL != "https://example.com" {
+			t.Fatalf("GetURLByShortURL() = %+v, %v", got, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("Get", mock.Anything, resolveFilter("0000W")).Return(&model.URLEntity{ID: 32, ShortURL: "0000W", ClickCount: 12}, nil).Once()
+		urlRepo.On("Delete", mock.Anything, uint64(32)).Return(nil).Once()
+
+		if err := newApp(t, urlRepo, newTagRepo(t)).DeleteURL(ctx, "", "0000W"); err != nil {
+			t.Fatalf("DeleteURL() error = %v", err)
+		}
+	})
+
+	t.Run("expired links are announced once", func(t *testing.T) {
+		urlRepo := urlmocks.NewURLRepository(t)
+		urlRepo.On("ListExpired", mock.Anything, mock.Anything, mock.Anything).Return([]*model.URLEntity{
+			{ID: 33, ShortURL: "0000X"}, {ID: 34, ShortURL: "0000Y"},
+		}, nil).Once()
+		urlRepo.On("MarkExpiryNotified", mock.Anything, uint64(33)).Return(true, nil).Once()
+		// another instance announced this one first
+		urlRepo.On("MarkExpiryNotified", mock.Anything, uint64(34)).Return(false, nil).Once()
+
+		notified, err := newApp(t, urlRepo, newTagRepo(t)).NotifyExpiredLinks(ctx)
+		if err != nil || notified != 1 {
+			t.Fatalf("NotifyExpiredLinks() = %d, %v, want 1", notified, err)
+		}
+	})
+}
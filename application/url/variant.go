@@ -0,0 +1,86 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"log"
+	neturl "net/url"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// maxVariants bounds the destinations of one split link
+const maxVariants = 10
+
+// validateVariants checks every variant has an http(s) destination and a positive weight
+func validateVariants(variants []model.VariantRequest) error {
+	if len(variants) > maxVariants {
+		return fmt.Errorf("at most %d variants", maxVariants)
+	}
+
+	for _, variant := range variants {
+		if variant.Weight <= 0 {
+			return fmt.Errorf("invalid weight %d", variant.Weight)
+		}
+		parsed, err := neturl.ParseRequestURI(variant.DestinationURL)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("invalid destination %q", variant.DestinationURL)
+		}
+	}
+	return nil
+}
+
+func toVariantEntities(urlID uint64, variants []model.VariantRequest) []*model.VariantEntity {
+	entities := make([]*model.VariantEntity, 0, len(variants))
+	for _, variant := range variants {
+		entities = append(entities, &model.VariantEntity{
+			URLID:          urlID,
+			DestinationURL: variant.DestinationURL,
+			Weight:         variant.Weight,
+		})
+	}
+	return entities
+}
+
+func toGetVariantResponses(entities []*model.VariantEntity) []model.GetVariantResponse {
+	resp := make([]model.GetVariantResponse, 0, len(entities))
+	for _, entity := range entities {
+		resp = append(resp, model.GetVariantResponse{
+			ID:             entity.ID,
+			DestinationURL: entity.DestinationURL,
+			Weight:         entity.Weight,
+		})
+	}
+	return resp
+}
+
+// chooseVariant picks a variant of a split link proportionally to the weights,
+// a sticky link keeps the variant the visitor was served before. Nil is
+// returned when the link has no variants.
+func (u *URLAppImpl) chooseVariant(ctx context.Context, entity *model.URLEntity, req *model.ResolveURLRequest) *model.VariantEntity {
+	variants, err := u.VariantRepository.List(ctx, entity.ID)
+	if err != nil {
+		log.Println("[chooseVariant] err List", err)
+		return nil
+	}
+	if len(variants) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, variant := range variants {
+		if entity.StickyVariant && variant.ID == req.VariantID {
+			return variant
+		}
+		total += variant.Weight
+	}
+
+	pick := u.RandIntn(total)
+	for _, variant := range variants {
+		if pick < variant.Weight {
+			return variant
+		}
+		pick -= variant.Weight
+	}
+	return variants[len(variants)-1]
+}
@@ -0,0 +1,307 @@
+package webhook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/delivery"
+	"github.com/muhammadheryan/url-shortner-base62/repository/webhook"
+	sender "github.com/muhammadheryan/url-shortner-base62/utils/webhook"
+)
+
+// webhooksCacheTTL is how long the registered webhooks are kept in memory, webhooks
+// changed on another instance are picked up after at most this long
+const webhooksCacheTTL = 30 * time.Second
+
+// maxErrorLength fits the last_error column
+const maxErrorLength = 1024
+
+// Dispatcher stores the events as deliveries and sends them in the background,
+// failed deliveries are retried with exponential backoff then moved to the dead letters
+type Dispatcher struct {
+	WebhookRepository  webhook.WebhookRepository
+	DeliveryRepository delivery.DeliveryRepository
+	Sender             sender.Sender
+	Config             *config.Config
+	Now                func() time.Time
+
+	mu       sync.Mutex
+	webhooks []*model.WebhookEntity
+	loadedAt time.Time
+	wake     chan struct{}
+}
+
+func NewDispatcher(WebhookRepository webhook.WebhookRepository, DeliveryRepository delivery.DeliveryRepository, cfg *config.Config) *Dispatcher {
+	return &Dispatcher{
+		WebhookRepository:  WebhookRepository,
+		DeliveryRepository: DeliveryRepository,
+		Sender:             sender.NewSender(cfg.Server.WebhookTimeout, cfg.Server.AllowPrivateDestinations),
+		Config:             cfg,
+		Now:                time.Now,
+		wake:               make(chan struct{}, 1),
+	}
+}
+
+// Publish queues the event for every webhook subscribed to it, a click also reaches
+// the webhooks having its click count as threshold. The deliveries keep the id of
+// the event so receivers can drop the copies of an event published twice.
+func (d *Dispatcher) Publish(ctx context.Context, payload *model.EventPayload) error {
+	webhooks, err := d.getWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := d.Now()
+	var deliveries []*model.WebhookDeliveryEntity
+	var eventDeliveries, thresholdDeliveries []*model.WebhookDeliveryEntity
+	for _, webhookEntity := range webhooks {
+		if webhookEntity.Subscribes(payload.Event) {
+			eventDeliveries = append(eventDeliveries, &model.WebhookDeliveryEntity{WebhookID: webhookEntity.ID})
+		}
+		if payload.Event == constant.EventLinkClicked && webhookEntity.Subscribes(constant.WebhookEventClickThreshold) && reachesThreshold(webhookEntity, payload.Data.ClickCount) {
+			thresholdDeliveries = append(thresholdDeliveries, &model.WebhookDeliveryEntity{WebhookID: webhookEntity.ID})
+		}
+	}
+
+	if len(eventDeliveries) > 0 {
+		filled, err := fillDeliveries(eventDeliveries, payload, now)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, filled...)
+	}
+	if len(thresholdDeliveries) > 0 {
+		thresholdData := *payload.Data
+		thresholdData.Threshold = thresholdData.ClickCount
+		filled, err := fillDeliveries(thresholdDeliveries, &model.EventPayload{
+			ID:        thresholdEventID(payload.ID),
+			Event:     constant.WebhookEventClickThreshold,
+			CreatedAt: payload.CreatedAt,
+			Data:      &thresholdData,
+		}, now)
+		if err != nil {
+			return err
+		}
+		deliveries = append(deliveries, filled...)
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := d.DeliveryRepository.CreateBatch(ctx, deliveries); err != nil {
+		return err
+	}
+
+	d.Wake()
+	return nil
+}
+
+// Wants tells if a registered webhook subscribes to the event, a click is also wanted by
+// the webhooks watching click thresholds. The event is wanted when the webhooks can't be read.
+func (d *Dispatcher) Wants(ctx context.Context, event string) bool {
+	webhooks, err := d.getWebhooks(ctx)
+	if err != nil {
+		log.Println("[Wants] err getWebhooks", err)
+		return true
+	}
+
+	for _, webhookEntity := range webhooks {
+		if webhookEntity.Subscribes(event) {
+			return true
+		}
+		if event == constant.EventLinkClicked && webhookEntity.Subscribes(constant.WebhookEventClickThreshold) && len(webhookEntity.ClickThresholds) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Wake makes Run look for due deliveries right away instead of at the next poll
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Invalidate makes the next event reload the registered webhooks
+func (d *Dispatcher) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.webhooks = nil
+	d.loadedAt = time.Time{}
+}
+
+// Run sends the due deliveries until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Config.Server.WebhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+
+		// keep going while full batches are claimed so a backlog drains quickly
+		for {
+			sent, err := d.DeliverDue(ctx)
+			if err != nil {
+				log.Println("[Run] err DeliverDue", err)
+				break
+			}
+			if sent < d.Config.Server.WebhookBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// DeliverDue sends the deliveries due now in parallel and returns how many were claimed
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	now := d.Now()
+	// the lock outlives the requests so a crashed instance only delays its deliveries
+	lockUntil := now.Add(2 * d.Config.Server.WebhookTimeout)
+
+	deliveries, err := d.DeliveryRepository.Claim(ctx, now, lockUntil, d.Config.Server.WebhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, deliveryEntity := range deliveries {
+		wg.Add(1)
+		go func(deliveryEntity *model.WebhookDeliveryEntity) {
+			defer wg.Done()
+			d.deliver(ctx, deliveryEntity)
+		}(deliveryEntity)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, deliveryEntity *model.WebhookDeliveryEntity) {
+	webhookEntity, err := d.WebhookRepository.Get(ctx, deliveryEntity.WebhookID)
+	if err != nil {
+		log.Println("[deliver] err Get", err)
+		return
+	}
+
+	// the webhook was deleted meanwhile, nobody is waiting for the event anymore
+	if webhookEntity == nil {
+		if err := d.DeliveryRepository.Delete(ctx, deliveryEntity.ID); err != nil {
+			log.Println("[deliver] err Delete", err)
+		}
+		return
+	}
+
+	err = d.Sender.Send(ctx, &sender.Message{
+		URL:     webhookEntity.URL,
+		Secret:  webhookEntity.Secret,
+		ID:      deliveryEntity.EventID,
+		Event:   deliveryEntity.Event,
+		Payload: []byte(deliveryEntity.Payload),
+	})
+	if err == nil {
+		if err := d.DeliveryRepository.Delete(ctx, deliveryEntity.ID); err != nil {
+			log.Println("[deliver] err Delete", err)
+		}
+		return
+	}
+
+	deliveryEntity.Attempts++
+	deliveryEntity.LastError = err.Error()
+	if len(deliveryEntity.LastError) > maxErrorLength {
+		deliveryEntity.LastError = deliveryEntity.LastError[:maxErrorLength]
+	}
+
+	if deliveryEntity.Attempts >= d.Config.Server.WebhookMaxAttempts {
+		if err := d.DeliveryRepository.DeadLetter(ctx, deliveryEntity); err != nil {
+			log.Println("[deliver] err DeadLetter", err)
+		}
+		return
+	}
+
+	deliveryEntity.NextAttemptAt = d.Now().Add(d.backoff(deliveryEntity.Attempts))
+	if err := d.DeliveryRepository.Retry(ctx, deliveryEntity); err != nil {
+		log.Println("[deliver] err Retry", err)
+	}
+}
+
+// backoff doubles the retry delay after every failed attempt up to WebhookRetryMax
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.Config.Server.WebhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.Config.Server.WebhookRetryMax {
+			return d.Config.Server.WebhookRetryMax
+		}
+	}
+	return delay
+}
+
+func (d *Dispatcher) getWebhooks(ctx context.Context) ([]*model.WebhookEntity, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.webhooks != nil && d.Now().Sub(d.loadedAt) < webhooksCacheTTL {
+		return d.webhooks, nil
+	}
+
+	webhooks, err := d.WebhookRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		webhooks = []*model.WebhookEntity{}
+	}
+
+	d.webhooks = webhooks
+	d.loadedAt = d.Now()
+	return webhooks, nil
+}
+
+// fillDeliveries gives the deliveries of one event the same id and payload
+func fillDeliveries(deliveries []*model.WebhookDeliveryEntity, payload *model.EventPayload, now time.Time) ([]*model.WebhookDeliveryEntity, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deliveryEntity := range deliveries {
+		deliveryEntity.EventID = payload.ID
+		deliveryEntity.Event = payload.Event
+		deliveryEntity.Payload = string(body)
+		deliveryEntity.NextAttemptAt = now
+		deliveryEntity.CreatedAt = now
+	}
+
+	return deliveries, nil
+}
+
+// thresholdEventID derives the id of the link.click_threshold event from the id of its click,
+// the same click always gives the same threshold event
+func thresholdEventID(clickEventID string) string {
+	sum := sha256.Sum256([]byte(constant.WebhookEventClickThreshold + ":" + clickEventID))
+	return hex.EncodeToString(sum[:16])
+}
+
+func reachesThreshold(webhookEntity *model.WebhookEntity, clickCount uint64) bool {
+	for _, threshold := range webhookEntity.ClickThresholds {
+		if threshold == clickCount {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appwebhook "github.com/muhammadheryan/url-shortner-base62/application/webhook"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	deliverymocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/delivery"
+	webhookmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/webhook"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/webhook"
+	"github.com/stretchr/testify/mock"
+)
+
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			WebhookTimeout:      time.Second,
+			WebhookMaxAttempts:  3,
+			WebhookRetryBase:    30 * time.Second,
+			WebhookRetryMax:     time.Minute,
+			WebhookPollInterval: time.Second,
+			WebhookBatchSize:    10,
+			// the receivers of the tests listen on loopback
+			AllowPrivateDestinations: true,
+		},
+	}
+}
+
+func newDispatcher(webhookRepo *webhookmocks.WebhookRepository, deliveryRepo *deliverymocks.DeliveryRepository) *appwebhook.Dispatcher {
+	dispatcher := appwebhook.NewDispatcher(webhookRepo, deliveryRepo, testConfig())
+	dispatcher.Now = func() time.Time { return now }
+	return dispatcher
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	webhookRepo := webhookmocks.NewWebhookRepository(t)
+	webhookRepo.On("List", mock.Anything).Return([]*model.WebhookEntity{
+		{ID: 1, Events: model.JSONList[string]{constant.EventLinkCreated}},
+		{ID: 2, Events: model.JSONList[string]{constant.EventLinkClicked, constant.WebhookEventClickThreshold}, ClickThresholds: model.JSONList[uint64]{100, 1000}},
+		{ID: 3, Events: model.JSONList[string]{constant.WebhookEventClickThreshold}, ClickThresholds: model.JSONList[uint64]{1000}},
+	}, nil).Once()
+
+	var created [][]*model.WebhookDeliveryEntity
+	deliveryRepo := deliverymocks.NewDeliveryRepository(t)
+	deliveryRepo.On("CreateBatch", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(1).([]*model.WebhookDeliveryEntity))
+	}).Return(nil)
+
+	dispatcher := newDispatcher(webhookRepo, deliveryRepo)
+	ctx := context.Background()
+
+	// the webhooks are loaded once and kept in memory for the next events
+	if err := dispatcher.Publish(ctx, &model.EventPayload{ID: "evt-1", Event: constant.EventLinkDeleted, Data: &model.LinkEventData{ShortURL: "0000A"}}); err != nil {
+		t.Fatalf("Publish() link.deleted error = %v", err)
+	}
+	if err := dispatcher.Publish(ctx, &model.EventPayload{ID: "evt-2", Event: constant.EventLinkClicked, Data: &model.LinkEventData{ShortURL: "0000A", ClickCount: 99}}); err != nil {
+		t.Fatalf("Publish() click 99 error = %v", err)
+	}
+	if err := dispatcher.Publish(ctx, &model.EventPayload{ID: "evt-3", Event: constant.EventLinkClicked, Data: &model.LinkEventData{ShortURL: "0000A", ClickCount: 100}}); err != nil {
+		t.Fatalf("Publish() click 100 error = %v", err)
+	}
+
+	if len(created) != 2 {
+		t.Fatalf("CreateBatch() called %d times, want 2 as nobody subscribed to link.deleted", len(created))
+	}
+	if len(created[0]) != 1 || created[0][0].WebhookID != 2 || created[0][0].Event != constant.EventLinkClicked || created[0][0].EventID != "evt-2" {
+		t.Fatalf("click 99 deliveries = %+v, want only the click of webhook 2", created[0])
+	}
+
+	clicks := created[1]
+	if len(clicks) != 2 || clicks[1].WebhookID != 2 || clicks[1].Event != constant.WebhookEventClickThreshold {
+		t.Fatalf("click 100 deliveries = %+v, want the click and the threshold of webhook 2", clicks)
+	}
+	var payload model.EventPayload
+	if err := json.Unmarshal([]byte(clicks[1].Payload), &payload); err != nil {
+		t.Fatalf("threshold payload = %s, %v", clicks[1].Payload, err)
+	}
+	if payload.ID != clicks[1].EventID || payload.Event != constant.WebhookEventClickThreshold || payload.Data.Threshold != 100 {
+		t.Fatalf("threshold payload = %+v", payload)
+	}
+	// publishing the click again gives the threshold the same id so receivers can drop the copy
+	if payload.ID == "evt-3" || len(payload.ID) != 32 {
+		t.Fatalf("threshold event id = %q, want an id derived from the click", payload.ID)
+	}
+	if err := dispatcher.Publish(ctx, &model.EventPayload{ID: "evt-3", Event: constant.EventLinkClicked, Data: &model.LinkEventData{ShortURL: "0000A", ClickCount: 100}}); err != nil {
+		t.Fatalf("Publish() click 100 again error = %v", err)
+	}
+	if again := created[2]; again[1].EventID != clicks[1].EventID {
+		t.Fatalf("threshold event id = %q, want %q again", again[1].EventID, clicks[1].EventID)
+	}
+	if !clicks[1].NextAttemptAt.Equal(now) {
+		t.Fatalf("threshold NextAttemptAt = %v, want due now", clicks[1].NextAttemptAt)
+	}
+}
+
+func TestDispatcher_Wants(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhooks []*model.WebhookEntity
+		event    string
+		want     bool
+	}{
+		{name: "no webhook", event: constant.EventLinkClicked, want: false},
+		{
+			name:     "subscribed to the event",
+			webhooks: []*model.WebhookEntity{{ID: 1, Events: model.JSONList[string]{constant.EventLinkClicked}}},
+			event:    constant.EventLinkClicked,
+			want:     true,
+		},
+		{
+			name:     "subscribed to other events",
+			webhooks: []*model.WebhookEntity{{ID: 1, Events: model.JSONList[string]{constant.EventLinkCreated}}},
+			event:    constant.EventLinkClicked,
+			want:     false,
+		},
+		{
+			name:     "clicks watched for thresholds",
+			webhooks: []*model.WebhookEntity{{ID: 1, Events: model.JSONList[string]{constant.WebhookEventClickThreshold}, ClickThresholds: model.JSONList[uint64]{100}}},
+			event:    constant.EventLinkClicked,
+			want:     true,
+		},
+		{
+			name:     "threshold subscription without thresholds",
+			webhooks: []*model.WebhookEntity{{ID: 1, Events: model.JSONList[string]{constant.WebhookEventClickThreshold}}},
+			event:    constant.EventLinkClicked,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := webhookmocks.NewWebhookRepository(t)
+			webhookRepo.On("List", mock.Anything).Return(tt.webhooks, nil).Once()
+
+			if got := newDispatcher(webhookRepo, deliverymocks.NewDeliveryRepository(t)).Wants(context.Background(), tt.event); got != tt.want {
+				t.Fatalf("Wants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DeliverDue(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		attempts  int
+		setupRepo func(deliveryRepo *deliverymocks.DeliveryRepository)
+	}{
+		{
+			name:   "delivered",
+			status: http.StatusOK,
+			setupRepo: func(deliveryRepo *deliverymocks.DeliveryRepository) {
+				deliveryRepo.On("Delete", mock.Anything, uint64(7)).Return(nil).Once()
+			},
+		},
+		{
+			name:     "failed with attempts left is retried with backoff",
+			status:   http.StatusInternalServerError,
+			attempts: 1,
+			setupRepo: func(deliveryRepo *deliverymocks.DeliveryRepository) {
+				// second failed attempt waits twice the base delay
+				deliveryRepo.On("Retry", mock.Anything, mock.MatchedBy(func(d *model.WebhookDeliveryEntity) bool {
+					return d.Attempts == 2 && d.NextAttemptAt.Equal(now.Add(time.Minute)) && d.LastError != ""
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:     "last attempt failed goes to the dead letters",
+			status:   http.StatusBadGateway,
+			attempts: 2,
+			setupRepo: func(deliveryRepo *deliverymocks.DeliveryRepository) {
+				deliveryRepo.On("DeadLetter", mock.Anything, mock.MatchedBy(func(d *model.WebhookDeliveryEntity) bool {
+					return d.Attempts == 3
+				})).Return(nil).Once()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+				if webhook.Verify("s3cret", timestamp, body, r.Header.Get(webhook.HeaderSignature)) && r.Header.Get(webhook.HeaderID) == "evt-7" {
+					received.Add(1)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			webhookRepo := webhookmocks.NewWebhookRepository(t)
+			webhookRepo.On("Get", mock.Anything, uint64(4)).Return(&model.WebhookEntity{ID: 4, URL: server.URL, Secret: "s3cret"}, nil).Once()
+			deliveryRepo := deliverymocks.NewDeliveryRepository(t)
+			deliveryRepo.On("Claim", mock.Anything, now, now.Add(2*time.Second), 10).Return([]*model.WebhookDeliveryEntity{
+				{ID: 7, WebhookID: 4, EventID: "evt-7", Event: constant.EventLinkCreated, Payload: `{"id":"evt-7"}`, Attempts: tt.attempts},
+			}, nil).Once()
+			tt.setupRepo(deliveryRepo)
+
+			sent, err := newDispatcher(webhookRepo, deliveryRepo).DeliverDue(context.Background())
+			if err != nil || sent != 1 {
+				t.Fatalf("DeliverDue() = %d, %v, want 1", sent, err)
+			}
+			if received.Load() != 1 {
+				t.Fatalf("receiver got %d signed requests, want 1", received.Load())
+			}
+		})
+	}
+}
+
+func TestDispatcher_DeliverDue_DeletedWebhook(t *testing.T) {
+	webhookRepo := webhookmocks.NewWebhookRepository(t)
+	webhookRepo.On("Get", mock.Anything, uint64(5)).Return(nil, nil).Once()
+	deliveryRepo := deliverymocks.NewDeliveryRepository(t)
+	deliveryRepo.On("Claim", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return([]*model.WebhookDeliveryEntity{
+		{ID: 8, WebhookID: 5},
+	}, nil).Once()
+	deliveryRepo.On("Delete", mock.Anything, uint64(8)).Return(nil).Once()
+
+	if _, err := newDispatcher(webhookRepo, deliveryRepo).DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+}
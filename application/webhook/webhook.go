@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	neturl "net/url"
+	"sort"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/delivery"
+	"github.com/muhammadheryan/url-shortner-base62/repository/webhook"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+const maxClickThresholds = 20
+
+type WebhookAppImpl struct {
+	WebhookRepository  webhook.WebhookRepository
+	DeliveryRepository delivery.DeliveryRepository
+	Dispatcher         *Dispatcher
+	Now                func() time.Time
+}
+
+type WebhookApp interface {
+	CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.GetWebhookResponse, error)
+	ListWebhooks(ctx context.Context) ([]*model.GetWebhookResponse, error)
+	GetWebhook(ctx context.Context, id uint64) (*model.GetWebhookResponse, error)
+	DeleteWebhook(ctx context.Context, id uint64) error
+	ListDeadLetters(ctx context.Context, id uint64) ([]*model.GetWebhookDeadLetterResponse, error)
+	ReplayDeadLetter(ctx context.Context, id uint64, deadLetterID uint64) error
+}
+
+func NewWebhookApplication(WebhookRepository webhook.WebhookRepository, DeliveryRepository delivery.DeliveryRepository, Dispatcher *Dispatcher) WebhookApp {
+	return &WebhookAppImpl{
+		WebhookRepository:  WebhookRepository,
+		DeliveryRepository: DeliveryRepository,
+		Dispatcher:         Dispatcher,
+		Now:                time.Now,
+	}
+}
+
+// CreateWebhook registers the endpoint, the returned secret signs every payload and is not shown again
+func (w *WebhookAppImpl) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.GetWebhookResponse, error) {
+	if err := validateWebhook(req); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Println("[CreateWebhook] err Read", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	createdWebhook, err := w.WebhookRepository.Create(ctx, &model.WebhookEntity{
+		URL:             req.URL,
+		Secret:          hex.EncodeToString(secret),
+		Events:          req.Events,
+		ClickThresholds: req.ClickThresholds,
+		CreatedAt:       w.Now(),
+	})
+	if err != nil {
+		log.Println("[CreateWebhook] err Create", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+	w.Dispatcher.Invalidate()
+
+	resp := toGetWebhookResponse(createdWebhook)
+	resp.Secret = createdWebhook.Secret
+	return resp, nil
+}
+
+func (w *WebhookAppImpl) ListWebhooks(ctx context.Context) ([]*model.GetWebhookResponse, error) {
+	webhooks, err := w.WebhookRepository.List(ctx)
+	if err != nil {
+		log.Println("[ListWebhooks] err List", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := make([]*model.GetWebhookResponse, 0, len(webhooks))
+	for _, webhookEntity := range webhooks {
+		resp = append(resp, toGetWebhookResponse(webhookEntity))
+	}
+
+	return resp, nil
+}
+
+func (w *WebhookAppImpl) GetWebhook(ctx context.Context, id uint64) (*model.GetWebhookResponse, error) {
+	webhookEntity, err := w.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toGetWebhookResponse(webhookEntity), nil
+}
+
+// DeleteWebhook deletes the webhook, its pending deliveries and dead letters
+func (w *WebhookAppImpl) DeleteWebhook(ctx context.Context, id uint64) error {
+	webhookEntity, err := w.getWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := w.WebhookRepository.Delete(ctx, webhookEntity.ID); err != nil {
+		log.Println("[DeleteWebhook] err Delete", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+	w.Dispatcher.Invalidate()
+
+	return nil
+}
+
+// ListDeadLetters lists the events given up on after the last attempt, newest first
+func (w *WebhookAppImpl) ListDeadLetters(ctx context.Context, id uint64) ([]*model.GetWebhookDeadLetterResponse, error) {
+	webhookEntity, err := w.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := w.DeliveryRepository.ListDeadLetters(ctx, webhookEntity.ID)
+	if err != nil {
+		log.Println("[ListDeadLetters] err ListDeadLetters", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	resp := make([]*model.GetWebhookDeadLetterResponse, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		resp = append(resp, &model.GetWebhookDeadLetterResponse{
+			ID:        deadLetter.ID,
+			EventID:   deadLetter.EventID,
+			Event:     deadLetter.Event,
+			Payload:   json.RawMessage(deadLetter.Payload),
+			Attempts:  deadLetter.Attempts,
+			LastError: deadLetter.LastError,
+			CreatedAt: deadLetter.CreatedAt,
+			FailedAt:  deadLetter.FailedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// ReplayDeadLetter queues the event again with the same id and payload
+func (w *WebhookAppImpl) ReplayDeadLetter(ctx context.Context, id uint64, deadLetterID uint64) error {
+	deadLetter, err := w.DeliveryRepository.GetDeadLetter(ctx, &model.WebhookDeadLetterFilter{
+		ID:        deadLetterID,
+		WebhookID: id,
+	})
+	if err != nil {
+		log.Println("[ReplayDeadLetter] err GetDeadLetter", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if deadLetter == nil {
+		return errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	if err := w.DeliveryRepository.Replay(ctx, deadLetter.ID, w.Now()); err != nil {
+		log.Println("[ReplayDeadLetter] err Replay", err)
+		return errors.SetCustomError(constant.ErrInternal)
+	}
+	w.Dispatcher.Wake()
+
+	return nil
+}
+
+func (w *WebhookAppImpl) getWebhook(ctx context.Context, id uint64) (*model.WebhookEntity, error) {
+	webhookEntity, err := w.WebhookRepository.Get(ctx, id)
+	if err != nil {
+		log.Println("[getWebhook] err Get", err)
+		return nil, errors.SetCustomError(constant.ErrInternal)
+	}
+
+	if webhookEntity == nil {
+		return nil, errors.SetCustomError(constant.ErrNotFound)
+	}
+
+	return webhookEntity, nil
+}
+
+// validateWebhook checks the endpoint and the events, thresholds are only kept for link.click_threshold
+func validateWebhook(req *model.CreateWebhookRequest) error {
+	parsed, err := neturl.ParseRequestURI(req.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	if len(req.Events) == 0 {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+	thresholds := false
+	for _, event := range req.Events {
+		if !constant.IsValidWebhookEvent(event) {
+			return errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+		thresholds = thresholds || event == constant.WebhookEventClickThreshold
+	}
+
+	if !thresholds {
+		req.ClickThresholds = nil
+		return nil
+	}
+	if len(req.ClickThresholds) == 0 || len(req.ClickThresholds) > maxClickThresholds {
+		return errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+	for _, threshold := range req.ClickThresholds {
+		if threshold == 0 {
+			return errors.SetCustomError(constant.ErrInvalidRequest)
+		}
+	}
+	sort.Slice(req.ClickThresholds, func(i, j int) bool { return req.ClickThresholds[i] < req.ClickThresholds[j] })
+
+	return nil
+}
+
+func toGetWebhookResponse(entity *model.WebhookEntity) *model.GetWebhookResponse {
+	return &model.GetWebhookResponse{
+		ID:              entity.ID,
+		URL:             entity.URL,
+		Events:          entity.Events,
+		ClickThresholds: entity.ClickThresholds,
+		CreatedAt:       entity.CreatedAt,
+	}
+}
@@ -0,0 +1,269 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Defaults of NewClient, the retry delay doubles after every failed attempt up to the max
+const (
+	defaultMaxRetries = 3
+	defaultRetryBase  = 200 * time.Millisecond
+	defaultRetryMax   = 5 * time.Second
+)
+
+// maxErrorBodySize limits how much of a response without the JSON envelope is kept as the error message
+const maxErrorBodySize = 256
+
+// Client calls the REST API of the url shortener. Reads, updates and deletes are retried
+// with backoff on network errors, internal errors and rate limiting, creations are not
+// retried since the API would create the link twice.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// MaxRetries is the number of retries after the first attempt of an idempotent call
+	MaxRetries int
+	RetryBase  time.Duration
+	RetryMax   time.Duration
+}
+
+// NewClient calls the API at baseURL, such as https://sho.rt, with a timeout per attempt
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: timeout},
+		MaxRetries: defaultMaxRetries,
+		RetryBase:  defaultRetryBase,
+		RetryMax:   defaultRetryMax,
+	}
+}
+
+// envelope is the body of every JSON response of the API
+type envelope struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (c *Client) CreateURL(ctx context.Context, req *model.CreateURLShortnerRequest) (*model.GetURLResponse, error) {
+	var resp model.GetURLResponse
+	if err := c.do(ctx, http.MethodPost, "", "/url", req, &resp, false); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateURLBatch creates the links of the batch, an item failing is reported in its result
+func (c *Client) CreateURLBatch(ctx context.Context, req *model.CreateURLShortnerBatchRequest) (*model.CreateURLShortnerBatchResponse, error) {
+	var resp model.CreateURLShortnerBatchResponse
+	if err := c.do(ctx, http.MethodPost, "", "/url/batch", req.Items, &resp, false); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetURLInfo returns the link without visiting it, the destination of protected,
+// single use and inactive links is left empty. The link is looked up on the custom
+// domain, or on the default domain when domain is empty, like the other calls by short url
+func (c *Client) GetURLInfo(ctx context.Context, domain, shortURL string) (*model.GetURLInfoResponse, error) {
+	var resp model.GetURLInfoResponse
+	if err := c.do(ctx, http.MethodGet, domain, urlPath(shortURL, "info"), nil, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) ListURLs(ctx context.Context, req *model.ListURLRequest) (*model.ListURLResponse, error) {
+	query := url.Values{}
+	if req.CampaignID != 0 {
+		query.Set("campaign_id", strconv.FormatUint(req.CampaignID, 10))
+	}
+	if req.Tag != "" {
+		query.Set("tag", req.Tag)
+	}
+	if req.Limit != 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.Offset != 0 {
+		query.Set("offset", strconv.Itoa(req.Offset))
+	}
+
+	path := "/url"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp model.ListURLResponse
+	if err := c.do(ctx, http.MethodGet, "", path, nil, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetURLTags replaces the tags of the link and returns them
+func (c *Client) SetURLTags(ctx context.Context, domain, shortURL string, req *model.SetURLTagsRequest) ([]string, error) {
+	var resp []string
+	if err := c.do(ctx, http.MethodPut, domain, urlPath(shortURL, "tags"), req, &resp, true); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SetURLCampaign moves the link to a campaign, campaign 0 removes it from its campaign
+func (c *Client) SetURLCampaign(ctx context.Context, domain, shortURL string, req *model.SetURLCampaignRequest) error {
+	return c.do(ctx, http.MethodPut, domain, urlPath(shortURL, "campaign"), req, nil, true)
+}
+
+// DeleteURL removes the link, a retry after a lost response gets ErrNotFound
+func (c *Client) DeleteURL(ctx context.Context, domain, shortURL string) error {
+	return c.do(ctx, http.MethodDelete, domain, urlPath(shortURL, ""), nil, nil, true)
+}
+
+func (c *Client) GetURLStats(ctx context.Context, domain, shortURL string) (*model.GetURLStatsResponse, error) {
+	var resp model.GetURLStatsResponse
+	if err := c.do(ctx, http.MethodGet, domain, urlPath(shortURL, "stats"), nil, &resp, true); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func urlPath(shortURL string, action string) string {
+	path := "/url/" + url.PathEscape(shortURL)
+	if action != "" {
+		path += "/" + action
+	}
+	return path
+}
+
+// do sends the request and decodes the data of the envelope into out, idempotent
+// requests are sent again while the failure is temporary and retries are left.
+// A domain is sent as the Host of the request, the API looks links up on the domain
+// of the request host
+func (c *Client) do(ctx context.Context, method string, domain string, path string, in interface{}, out interface{}, idempotent bool) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, domain, path, body, out)
+		if err == nil || !idempotent || attempt >= c.MaxRetries || !retryable(ctx, err) {
+			return err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method string, domain string, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if domain != "" {
+		req.Host = domain
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "url-shortner-client")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var result envelope
+	if err := json.Unmarshal(raw, &result); err != nil || result.Code == "" {
+		message := string(bytes.TrimSpace(raw))
+		if len(message) > maxErrorBodySize {
+			message = message[:maxErrorBodySize]
+		}
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return &Error{
+			Type:       errorTypeByStatus(resp.StatusCode),
+			Message:    message,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	if result.Code != constant.ErrorTypeCode[constant.Successful] {
+		return &Error{
+			Type:       errorTypeByCode(result.Code),
+			Code:       result.Code,
+			Message:    result.Message,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	if out == nil || len(result.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// retryable checks if the failure may go away by itself, answers of the API other than
+// internal errors and rate limiting won't change on a retry
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiError *Error
+	if errors.As(err, &apiError) {
+		return apiError.Type == constant.ErrInternal || apiError.Type == constant.ErrTooManyRequests
+	}
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		return false
+	}
+
+	// network errors, the API was not reached or its answer was lost
+	return true
+}
+
+// backoff doubles the retry delay after every failed attempt up to RetryMax
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.RetryBase
+	for i := 0; i < attempt && delay < c.RetryMax; i++ {
+		delay *= 2
+	}
+	if delay > c.RetryMax {
+		delay = c.RetryMax
+	}
+	return delay
+}
@@ -0,0 +1,205 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/client"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+func newClient(url string) *client.Client {
+	c := client.NewClient(url, time.Second)
+	c.RetryBase = time.Millisecond
+	c.RetryMax = 4 * time.Millisecond
+	return c
+}
+
+func writeBody(w http.ResponseWriter, status int, code string, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": message, "data": data})
+}
+
+func TestClient_CreateURL(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var req model.CreateURLShortnerRequest
+		if r.Method != http.MethodPost || r.URL.Path != "/url" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if req.OriginalURL == "https://fail.example.com" {
+			writeBody(w, http.StatusInternalServerError, "0001", "error internal", nil)
+			return
+		}
+		writeBody(w, http.StatusOK, "0000", "success", model.GetURLResponse{ShortURL: "00001", OriginalURL: req.OriginalURL, Tags: req.Tags})
+	}))
+	defer server.Close()
+	c := newClient(server.URL)
+
+	resp, err := c.CreateURL(context.Background(), &model.CreateURLShortnerRequest{OriginalURL: "https://example.com", Tags: []string{"docs"}})
+	if err != nil || resp.ShortURL != "00001" || resp.OriginalURL != "https://example.com" || len(resp.Tags) != 1 {
+		t.Fatalf("CreateURL() = %+v, %v", resp, err)
+	}
+
+	// creating twice would make two links, so a failed creation is not retried
+	atomic.StoreInt32(&calls, 0)
+	if _, err := c.CreateURL(context.Background(), &model.CreateURLShortnerRequest{OriginalURL: "https://fail.example.com"}); !errors.Is(err, client.ErrInternal) {
+		t.Fatalf("CreateURL() error = %v, want ErrInternal", err)
+	}
+	if calls != 1 {
+		t.Fatalf("CreateURL() sent %d requests, want 1", calls)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		want      error
+		wantCalls int32
+	}{
+		{
+			name: "error of the API",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeBody(w, http.StatusBadRequest, "0002", "data not found", nil)
+			},
+			want:      client.ErrNotFound,
+			wantCalls: 1,
+		},
+		{
+			name: "gone link",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeBody(w, http.StatusGone, "0007", "data no longer available", nil)
+			},
+			want:      client.ErrGone,
+			wantCalls: 1,
+		},
+		{
+			name: "internal errors are retried",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeBody(w, http.StatusInternalServerError, "0001", "error internal", nil)
+			},
+			want:      client.ErrInternal,
+			wantCalls: 4,
+		},
+		{
+			name: "rate limiting is retried",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeBody(w, http.StatusTooManyRequests, "0006", "too many requests", nil)
+			},
+			want:      client.ErrTooManyRequests,
+			wantCalls: 4,
+		},
+		{
+			name: "response of a proxy",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			},
+			want:      client.ErrInternal,
+			wantCalls: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			_, err := newClient(server.URL).GetURLInfo(context.Background(), "", "00001")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetURLInfo() error = %v, want %v", err, tt.want)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("GetURLInfo() sent %d requests, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_RetryRecovers(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			writeBody(w, http.StatusInternalServerError, "0001", "error internal", nil)
+			return
+		}
+		if r.URL.Path != "/url/00001/stats" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		writeBody(w, http.StatusOK, "0000", "success", model.GetURLStatsResponse{ShortURL: "00001", TotalClicks: 42})
+	}))
+	defer server.Close()
+
+	resp, err := newClient(server.URL).GetURLStats(context.Background(), "", "00001")
+	if err != nil || resp.TotalClicks != 42 || calls != 3 {
+		t.Fatalf("GetURLStats() = %+v, %v after %d requests", resp, err, calls)
+	}
+}
+
+func TestClient_RetryStopsWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeBody(w, http.StatusInternalServerError, "0001", "error internal", nil)
+	}))
+	defer server.Close()
+
+	c := newClient(server.URL)
+	c.RetryBase = time.Hour
+	c.RetryMax = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := c.DeleteURL(ctx, "", "00001"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("DeleteURL() error = %v, want the context error", err)
+	}
+}
+
+func TestClient_ListURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.RawQuery; got != "campaign_id=3&limit=10&tag=docs" {
+			t.Errorf("query = %s", got)
+		}
+		writeBody(w, http.StatusOK, "0000", "success", model.ListURLResponse{
+			Items: []*model.GetURLInfoResponse{{GetURLResponse: model.GetURLResponse{ShortURL: "00001"}, ClickCount: 5}},
+			Limit: 10,
+		})
+	}))
+	defer server.Close()
+
+	resp, err := newClient(server.URL).ListURLs(context.Background(), &model.ListURLRequest{CampaignID: 3, Tag: "docs", Limit: 10})
+	if err != nil || len(resp.Items) != 1 || resp.Items[0].ShortURL != "00001" || resp.Items[0].ClickCount != 5 {
+		t.Fatalf("ListURLs() = %+v, %v", resp, err)
+	}
+}
+
+func TestClient_Domain(t *testing.T) {
+	var hosts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		writeBody(w, http.StatusOK, "0000", "success", nil)
+	}))
+	defer server.Close()
+	c := newClient(server.URL)
+	ctx := context.Background()
+
+	// the API looks links up on the domain of the request host
+	if err := c.DeleteURL(ctx, "go.acme.com", "00001"); err != nil {
+		t.Fatalf("DeleteURL() error = %v", err)
+	}
+	if err := c.DeleteURL(ctx, "", "00001"); err != nil {
+		t.Fatalf("DeleteURL() error = %v", err)
+	}
+	if len(hosts) != 2 || hosts[0] != "go.acme.com" || hosts[1] != server.Listener.Addr().String() {
+		t.Fatalf("request hosts = %v", hosts)
+	}
+}
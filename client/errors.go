@@ -0,0 +1,87 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+)
+
+// Error is an error answered by the API, Type is the constant.ErrorType matching its code
+// so callers can check it with errors.Is against the errors below
+type Error struct {
+	Type       constant.ErrorType
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("url-shortner: %s (status %d)", e.Message, e.StatusCode)
+	}
+	return fmt.Sprintf("url-shortner: %s (code %s, status %d)", e.Message, e.Code, e.StatusCode)
+}
+
+// Is reports errors of the same type as equal, whatever their status or message
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Type == e.Type
+}
+
+var (
+	ErrInternal           = newError(constant.ErrInternal)
+	ErrNotFound           = newError(constant.ErrNotFound)
+	ErrInvalidRequest     = newError(constant.ErrInvalidRequest)
+	ErrUnauthorize        = newError(constant.ErrUnauthorize)
+	ErrPasswordRequired   = newError(constant.ErrPasswordRequired)
+	ErrTooManyRequests    = newError(constant.ErrTooManyRequests)
+	ErrGone               = newError(constant.ErrGone)
+	ErrNotAvailable       = newError(constant.ErrNotAvailable)
+	ErrVerificationFailed = newError(constant.ErrVerificationFailed)
+	ErrConflict           = newError(constant.ErrConflict)
+)
+
+func newError(errorType constant.ErrorType) *Error {
+	return &Error{
+		Type:       errorType,
+		Code:       constant.ErrorTypeCode[errorType],
+		Message:    constant.ErrorTypeMessage[errorType],
+		StatusCode: constant.ErrorTypeHTTPCode[errorType],
+	}
+}
+
+// errorTypeByCode finds the error type of a code answered by the API, unknown codes are internal errors
+func errorTypeByCode(code string) constant.ErrorType {
+	for errorType, errorCode := range constant.ErrorTypeCode {
+		if errorCode == code {
+			return errorType
+		}
+	}
+	return constant.ErrInternal
+}
+
+// errorTypeByStatus guesses the error type of a response without the JSON envelope,
+// such as one written by a proxy in front of the service
+func errorTypeByStatus(statusCode int) constant.ErrorType {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return constant.ErrInvalidRequest
+	case http.StatusNotFound:
+		return constant.ErrNotFound
+	case http.StatusUnauthorized:
+		return constant.ErrUnauthorize
+	case http.StatusForbidden:
+		return constant.ErrNotAvailable
+	case http.StatusConflict:
+		return constant.ErrConflict
+	case http.StatusGone:
+		return constant.ErrGone
+	case http.StatusUnprocessableEntity:
+		return constant.ErrVerificationFailed
+	case http.StatusTooManyRequests:
+		return constant.ErrTooManyRequests
+	default:
+		return constant.ErrInternal
+	}
+}
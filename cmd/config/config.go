@@ -1,12 +1,19 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
 )
 
 // Config holds all configuration for our application
@@ -30,14 +37,97 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// AutoMigrate applies the pending migrations at startup in development, MigrateLockTimeout
+	// is how long an instance waits for another one migrating
+	AutoMigrate        bool
+	MigrateLockTimeout time.Duration
+	// Storage is where the links are kept, sql or memory
+	Storage string
+	// Driver is the database of the sql storage, mysql, postgres or sqlite, SSLMode is the
+	// sslmode of Postgres connections
+	Driver  string
+	SSLMode string
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port         string
+	GRPCPort     string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// ShutdownTimeout is how long the running requests and streams get to finish on shutdown
+	ShutdownTimeout time.Duration
+	// DefaultRedirectType is used when a link is created without a redirect type
+	DefaultRedirectType int
+	// RedirectCacheMaxAge is how long browsers may cache permanent redirects
+	RedirectCacheMaxAge time.Duration
+	// PreviewFetchTimeout limits fetching the destination title for preview pages
+	PreviewFetchTimeout time.Duration
+	// AllowPrivateDestinations lets the server request user supplied urls on loopback, private
+	// and link-local addresses, only meant for local development
+	AllowPrivateDestinations bool
+	// ForcePreviewUntrusted shows the preview page instead of redirecting for links of untrusted users
+	ForcePreviewUntrusted bool
+	// TrustedUserIDs are the users whose links always redirect directly
+	TrustedUserIDs []uint64
+	// BatchMaxSize is the maximum number of links created in one batch request
+	BatchMaxSize int
+	// CookieSecret signs the cookies set after a link password was entered
+	CookieSecret string
+	// PasswordCookieTTL is how long a visitor is not asked for the password again
+	PasswordCookieTTL time.Duration
+	// PasswordMaxAttempts is the number of wrong passwords allowed within PasswordLockout
+	PasswordMaxAttempts int
+	// PasswordLockout is how long a visitor is blocked after too many wrong passwords
+	PasswordLockout time.Duration
+	// GeoIPDatabasePath is a MaxMind-format country database used by targeting rules
+	GeoIPDatabasePath string
+	// TrustProxyHeaders takes the client IP from X-Forwarded-For when running behind a proxy,
+	// TrustedProxyHops is the number of proxies appending to it in front of the service
+	TrustProxyHeaders bool
+	TrustedProxyHops  int
+	// VariantCookieTTL is how long a visitor keeps the variant of a sticky split link
+	VariantCookieTTL time.Duration
+	// DefaultQueryConflict is used when a link forwarding the query has no conflict resolution
+	DefaultQueryConflict string
+	// DomainVerifyScheme and DomainVerifyTimeout are used to fetch the verification token of custom domains
+	DomainVerifyScheme  string
+	DomainVerifyTimeout time.Duration
+	// PublicBaseURL is where the service is reachable such as https://sho.rt, absolute
+	// links in responses are built from the request host when empty
+	PublicBaseURL string
+	// DomainBaseURLs overrides the https://{host} base of custom domains
+	DomainBaseURLs map[string]string
+	// WebhookTimeout limits each webhook request, WebhookMaxAttempts is the number of attempts
+	// before a delivery goes to the dead letters
+	WebhookTimeout     time.Duration
+	WebhookMaxAttempts int
+	// WebhookRetryBase is the delay before the first retry, doubled on every attempt up to WebhookRetryMax
+	WebhookRetryBase time.Duration
+	WebhookRetryMax  time.Duration
+	// WebhookPollInterval is how often pending deliveries are looked for, WebhookBatchSize
+	// how many deliveries are sent at once
+	WebhookPollInterval time.Duration
+	WebhookBatchSize    int
+	// ExpiryPollInterval is how often the links whose activation window ended are looked for
+	ExpiryPollInterval time.Duration
+	// OutboxPublishers receive the domain events in this order, among log, webhook and nats,
+	// the events are dropped when it is set empty
+	OutboxPublishers []string
+	// OutboxPollInterval is how often pending events are looked for, OutboxBatchSize how many
+	// are published at once
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	// OutboxRetryDelay is how long a claimed event is kept from other instances, an event
+	// failing on a publisher is published again after this delay, doubled on every attempt
+	// up to an hour. After OutboxMaxAttempts the event is marked as failed and left aside.
+	OutboxRetryDelay  time.Duration
+	OutboxMaxAttempts int
+	// NATSURL is the server the nats publisher sends to, on subjects NATSSubjectPrefix.{event}
+	NATSURL           string
+	NATSSubjectPrefix string
+	NATSTimeout       time.Duration
 }
 
 // Load reads configuration from environment variables
@@ -48,10 +138,12 @@ func Load() *Config {
 		log.Printf("Warning: Error loading .env file: %v", err)
 	}
 
+	driver := getEnvAsDriver("DB_DRIVER", constant.DriverMySQL)
+
 	return &Config{
 		Database: DatabaseConfig{
 			Host:            getEnv("DB_HOST", "127.0.0.1"),
-			Port:            getEnvAsInt("DB_PORT", 3306),
+			Port:            getEnvAsInt("DB_PORT", constant.Drivers[driver]),
 			User:            getEnv("DB_USER", "user"),
 			Password:        getEnv("DB_PASSWORD", ""),
 			Name:            getEnv("DB_NAME", "tests"),
@@ -59,17 +151,100 @@ func Load() *Config {
 			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 10),
 			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
 			ConnMaxLifetime: time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME", 3600)) * time.Second,
+			// Migrations
+			AutoMigrate:        getEnvAsBool("DB_AUTO_MIGRATE", false),
+			MigrateLockTimeout: time.Duration(getEnvAsInt("DB_MIGRATE_LOCK_TIMEOUT", 60)) * time.Second,
+			// Storage
+			Storage: getEnvAsStorage("STORAGE", constant.StorageSQL),
+			Driver:  driver,
+			SSLMode: getEnv("DB_SSL_MODE", "disable"),
 		},
 		Server: ServerConfig{
 			Port:         getEnv("SERVER_PORT", "8080"),
+			GRPCPort:     getEnv("GRPC_PORT", "9090"),
 			ReadTimeout:  time.Duration(getEnvAsInt("SERVER_READ_TIMEOUT", 5)) * time.Second,
 			WriteTimeout: time.Duration(getEnvAsInt("SERVER_WRITE_TIMEOUT", 10)) * time.Second,
 			IdleTimeout:  time.Duration(getEnvAsInt("SERVER_IDLE_TIMEOUT", 30)) * time.Second,
+			// Time left to the running requests on SIGINT or SIGTERM
+			ShutdownTimeout: time.Duration(getEnvAsInt("SERVER_SHUTDOWN_TIMEOUT", 15)) * time.Second,
+			// Default redirect type and cache lifetime for permanent redirects
+			DefaultRedirectType: getEnvAsRedirectType("DEFAULT_REDIRECT_TYPE", http.StatusTemporaryRedirect),
+			RedirectCacheMaxAge: time.Duration(getEnvAsInt("REDIRECT_CACHE_MAX_AGE", 86400)) * time.Second,
+			// Preview page settings
+			PreviewFetchTimeout:      time.Duration(getEnvAsInt("PREVIEW_FETCH_TIMEOUT", 3)) * time.Second,
+			AllowPrivateDestinations: getEnvAsBool("ALLOW_PRIVATE_DESTINATIONS", false),
+			ForcePreviewUntrusted:    getEnvAsBool("FORCE_PREVIEW_UNTRUSTED", false),
+			TrustedUserIDs:           getEnvAsUintList("TRUSTED_USER_IDS"),
+			BatchMaxSize:             getEnvAsInt("BATCH_MAX_SIZE", 1000),
+			// Password protected links
+			CookieSecret:        getEnv("COOKIE_SECRET", ""),
+			PasswordCookieTTL:   time.Duration(getEnvAsInt("PASSWORD_COOKIE_TTL", 3600)) * time.Second,
+			PasswordMaxAttempts: getEnvAsInt("PASSWORD_MAX_ATTEMPTS", 5),
+			PasswordLockout:     time.Duration(getEnvAsInt("PASSWORD_LOCKOUT", 900)) * time.Second,
+			// Targeting rules
+			GeoIPDatabasePath: getEnv("GEOIP_DATABASE_PATH", ""),
+			TrustProxyHeaders: getEnvAsBool("TRUST_PROXY_HEADERS", false),
+			TrustedProxyHops:  getEnvAsInt("TRUSTED_PROXY_HOPS", 1),
+			// Split links
+			VariantCookieTTL: time.Duration(getEnvAsInt("VARIANT_COOKIE_TTL", 2592000)) * time.Second,
+			// Query passthrough
+			DefaultQueryConflict: getEnvAsQueryConflict("DEFAULT_QUERY_CONFLICT", constant.QueryConflictKeep),
+			// Custom domains
+			DomainVerifyScheme:  getEnv("DOMAIN_VERIFY_SCHEME", "https"),
+			DomainVerifyTimeout: time.Duration(getEnvAsInt("DOMAIN_VERIFY_TIMEOUT", 5)) * time.Second,
+			// Absolute links
+			PublicBaseURL:  strings.TrimSuffix(getEnv("PUBLIC_BASE_URL", ""), "/"),
+			DomainBaseURLs: getEnvAsURLMap("DOMAIN_BASE_URLS"),
+			// Webhooks
+			WebhookTimeout:      time.Duration(getEnvAsInt("WEBHOOK_TIMEOUT", 10)) * time.Second,
+			WebhookMaxAttempts:  getEnvAsInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			WebhookRetryBase:    time.Duration(getEnvAsInt("WEBHOOK_RETRY_BASE", 30)) * time.Second,
+			WebhookRetryMax:     time.Duration(getEnvAsInt("WEBHOOK_RETRY_MAX", 3600)) * time.Second,
+			WebhookPollInterval: time.Duration(getEnvAsInt("WEBHOOK_POLL_INTERVAL", 5)) * time.Second,
+			WebhookBatchSize:    getEnvAsInt("WEBHOOK_BATCH_SIZE", 50),
+			// Expired links
+			ExpiryPollInterval: time.Duration(getEnvAsInt("EXPIRY_POLL_INTERVAL", 60)) * time.Second,
+			// Outbox
+			OutboxPublishers:   getEnvAsPublisherList("OUTBOX_PUBLISHERS", []string{constant.OutboxPublisherWebhook}),
+			OutboxPollInterval: time.Duration(getEnvAsInt("OUTBOX_POLL_INTERVAL", 1)) * time.Second,
+			OutboxBatchSize:    getEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+			OutboxRetryDelay:   time.Duration(getEnvAsInt("OUTBOX_RETRY_DELAY", 30)) * time.Second,
+			OutboxMaxAttempts:  getEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+			NATSURL:            getEnv("NATS_URL", "nats://127.0.0.1:4222"),
+			NATSSubjectPrefix:  getEnv("NATS_SUBJECT_PREFIX", "url-shortner"),
+			NATSTimeout:        time.Duration(getEnvAsInt("NATS_TIMEOUT", 5)) * time.Second,
 		},
 		Environment: getEnv("ENV", "development"),
 	}
 }
 
+// GetCookieSecret returns the cookie signing secret. The server refuses to start without
+// one outside development, in development a random one is generated so cookies only
+// survive until the next restart
+func (c *Config) GetCookieSecret() string {
+	if c.Server.CookieSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("failed to generate cookie secret: %v", err)
+		}
+		log.Printf("Warning: COOKIE_SECRET is not set, using a random secret")
+		c.Server.CookieSecret = hex.EncodeToString(secret)
+	}
+	return c.Server.CookieSecret
+}
+
+// BaseURL returns the public base url of the links served on the custom domain host,
+// or on the default domain when host is empty
+func (c *Config) BaseURL(host string) string {
+	if host == "" {
+		return c.Server.PublicBaseURL
+	}
+	if base, ok := c.Server.DomainBaseURLs[host]; ok {
+		return base
+	}
+	return "https://" + host
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -89,11 +264,150 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
-// GetDSN returns database connection string for Go applications
+// getEnvAsBool gets an environment variable as boolean with a fallback value
+func getEnvAsBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		log.Printf("Warning: Invalid boolean value for %s: %s, using fallback: %t", key, value, fallback)
+	}
+	return fallback
+}
+
+// getEnvAsUintList gets a comma separated environment variable as list of unsigned integers
+func getEnvAsUintList(key string) []uint64 {
+	var result []uint64
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		uintValue, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			log.Printf("Warning: Invalid integer value in %s: %s, skipping", key, item)
+			continue
+		}
+		result = append(result, uintValue)
+	}
+	return result
+}
+
+// getEnvAsURLMap gets a comma separated environment variable of host=url pairs
+func getEnvAsURLMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		host, base, ok := strings.Cut(item, "=")
+		if !ok || host == "" || base == "" {
+			log.Printf("Warning: Invalid host=url pair for %s: %s, skipping", key, item)
+			continue
+		}
+		result[strings.ToLower(host)] = strings.TrimSuffix(base, "/")
+	}
+	return result
+}
+
+// getEnvAsPublisherList gets a comma separated environment variable as list of outbox publishers,
+// the fallback is used when the variable is not set
+func getEnvAsPublisherList(key string, fallback []string) []string {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		if !constant.IsValidOutboxPublisher(item) {
+			log.Printf("Warning: Invalid outbox publisher in %s: %s, skipping", key, item)
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// getEnvAsRedirectType gets an environment variable as redirect status code with a fallback value
+func getEnvAsRedirectType(key string, fallback int) int {
+	value := getEnvAsInt(key, fallback)
+	if !constant.IsValidRedirectType(value) {
+		log.Printf("Warning: Invalid redirect type for %s: %d, using fallback: %d", key, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+// getEnvAsQueryConflict gets an environment variable as query conflict resolution with a fallback value
+func getEnvAsQueryConflict(key string, fallback string) string {
+	value := getEnv(key, fallback)
+	if !constant.IsValidQueryConflict(value) {
+		log.Printf("Warning: Invalid query conflict for %s: %s, using fallback: %s", key, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+// getEnvAsStorage gets an environment variable as storage with a fallback value
+func getEnvAsStorage(key string, fallback string) string {
+	value := strings.ToLower(getEnv(key, fallback))
+	if !constant.IsValidStorage(value) {
+		log.Printf("Warning: Invalid storage for %s: %s, using fallback: %s", key, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+// getEnvAsDriver gets an environment variable as database driver with a fallback value
+func getEnvAsDriver(key string, fallback string) string {
+	value := strings.ToLower(getEnv(key, fallback))
+	if !constant.IsValidDriver(value) {
+		log.Printf("Warning: Invalid database driver for %s: %s, using fallback: %s", key, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+// GetDSN returns database connection string for Go applications, in the format of Driver
 func (c *Config) GetDSN() string {
+	if c.Database.Driver == constant.DriverPostgres {
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.Database.User, c.Database.Password),
+			Host:     net.JoinHostPort(c.Database.Host, strconv.Itoa(c.Database.Port)),
+			Path:     "/" + c.Database.Name,
+			RawQuery: url.Values{"sslmode": {c.Database.SSLMode}}.Encode(),
+		}
+		return dsn.String()
+	}
+	if c.Database.Driver == constant.DriverSQLite {
+		// WAL lets the redirects read while a link is written, transactions take the write
+		// lock when they begin so they wait for each other instead of failing on upgrade
+		params := url.Values{
+			"_pragma":      {"journal_mode(WAL)", "busy_timeout(5000)", "foreign_keys(1)"},
+			"_time_format": {"sqlite"},
+			"_txlock":      {"immediate"},
+		}
+		return "file:" + c.Database.Name + "?" + params.Encode()
+	}
 	return c.Database.User + ":" + c.Database.Password + "@tcp(" + c.Database.Host + ":" + strconv.Itoa(c.Database.Port) + ")/" + c.Database.Name + "?parseTime=true"
 }
 
+// GetMigrateDSN returns the connection string used to apply migrations, which run
+// several statements per query. Postgres and SQLite allow it without parameters.
+func (c *Config) GetMigrateDSN() string {
+	if c.Database.Driver != constant.DriverMySQL {
+		return c.GetDSN()
+	}
+	return c.GetDSN() + "&multiStatements=true"
+}
+
 // GetDatabaseURL returns DATABASE_URL for dbmate
 func (c *Config) GetDatabaseURL() string {
 	return c.Database.URL
@@ -104,6 +418,16 @@ func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
 }
 
+// IsTrustedUser checks if links of the user may redirect without a forced preview
+func (c *Config) IsTrustedUser(userID uint64) bool {
+	for _, id := range c.Server.TrustedUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // IsProduction checks if environment is production
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
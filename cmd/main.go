@@ -1,16 +1,46 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/muhammadheryan/url-shortner-base62/application/campaign"
+	"github.com/muhammadheryan/url-shortner-base62/application/domain"
+	"github.com/muhammadheryan/url-shortner-base62/application/outbox"
+	"github.com/muhammadheryan/url-shortner-base62/application/rule"
+	"github.com/muhammadheryan/url-shortner-base62/application/tag"
 	"github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/application/webhook"
 	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/db/migrations"
 	_ "github.com/muhammadheryan/url-shortner-base62/docs"
+	campaignRepo "github.com/muhammadheryan/url-shortner-base62/repository/campaign"
+	clickRepo "github.com/muhammadheryan/url-shortner-base62/repository/click"
+	deliveryRepo "github.com/muhammadheryan/url-shortner-base62/repository/delivery"
+	domainRepo "github.com/muhammadheryan/url-shortner-base62/repository/domain"
+	outboxRepo "github.com/muhammadheryan/url-shortner-base62/repository/outbox"
+	ruleRepo "github.com/muhammadheryan/url-shortner-base62/repository/rule"
+	tagRepo "github.com/muhammadheryan/url-shortner-base62/repository/tag"
 	urlRepo "github.com/muhammadheryan/url-shortner-base62/repository/url"
+	variantRepo "github.com/muhammadheryan/url-shortner-base62/repository/variant"
+	webhookRepo "github.com/muhammadheryan/url-shortner-base62/repository/webhook"
 	"github.com/muhammadheryan/url-shortner-base62/transport"
+	"github.com/muhammadheryan/url-shortner-base62/utils/geoip"
+	"github.com/muhammadheryan/url-shortner-base62/utils/migrate"
+	"github.com/muhammadheryan/url-shortner-base62/utils/nats"
+	_ "modernc.org/sqlite"
 )
 
 // @title URL Shortener API
@@ -22,23 +52,138 @@ func main() {
 	// Load configuration from environment variables
 	cfg := config.Load()
 
+	// `migrate up|down|status` applies the embedded migrations instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatal("err migrate ", err)
+		}
+		return
+	}
+
 	log.Printf("Starting server in %s environment", cfg.Environment)
 
-	// Connect to database
-	db, err := sqlx.Connect("mysql", cfg.GetDSN())
-	if err != nil {
-		log.Fatal("err connect db ", err)
+	// Unlock cookies signed with a random secret stop working on restart and across replicas
+	if cfg.Server.CookieSecret == "" && !cfg.IsDevelopment() {
+		log.Fatal("COOKIE_SECRET is required outside development")
 	}
 
-	// Set database connection pool settings
-	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	memoryStorage := cfg.Database.Storage == constant.StorageMemory
+	if memoryStorage {
+		log.Println("Keeping links in memory, they are lost on restart")
+	}
+
+	if cfg.IsDevelopment() && cfg.Database.AutoMigrate && !memoryStorage {
+		if err := runMigrate(cfg, []string{"up"}); err != nil {
+			log.Fatal("err migrate ", err)
+		}
+	}
+
+	// Connect to database, memory storage keeps everything in memory and runs without one
+	var db *sqlx.DB
+	if !memoryStorage {
+		var err error
+		db, err = sqlx.Connect(cfg.Database.Driver, cfg.GetDSN())
+		if err != nil {
+			log.Fatal("err connect db ", err)
+		}
+
+		// Set database connection pool settings
+		db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+		db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+		db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	}
+
+	// Open the country database used by targeting rules
+	geoLocator, err := geoip.NewLocator(cfg.Server.GeoIPDatabasePath)
+	if err != nil {
+		log.Fatal("err open geoip database ", err)
+	}
+	defer geoip.Close(geoLocator)
 
 	// Initialize application layers
 	URLRepo := urlRepo.NewURLRepository(db)
-	URLApp := url.NewURLApplication(URLRepo)
-	httpTransport := transport.NewTransport(URLApp)
+	switch cfg.Database.Driver {
+	case constant.DriverPostgres:
+		URLRepo = urlRepo.NewPostgresURLRepository(db)
+	case constant.DriverSQLite:
+		URLRepo = urlRepo.NewSQLiteURLRepository(db)
+	}
+	RuleRepo := ruleRepo.NewRuleRepository(db)
+	VariantRepo := variantRepo.NewVariantRepository(db)
+	ClickRepo := clickRepo.NewClickRepository(db)
+	DomainRepo := domainRepo.NewDomainRepository(db)
+	TagRepo := tagRepo.NewTagRepository(db)
+	CampaignRepo := campaignRepo.NewCampaignRepository(db)
+	WebhookRepo := webhookRepo.NewWebhookRepository(db)
+	DeliveryRepo := deliveryRepo.NewDeliveryRepository(db)
+	if memoryStorage {
+		memoryTagRepo := tagRepo.NewMemoryTagRepository()
+		memoryRuleRepo := ruleRepo.NewMemoryRuleRepository()
+		memoryVariantRepo := variantRepo.NewMemoryVariantRepository()
+		memoryURLRepo := urlRepo.NewMemoryURLRepository(memoryTagRepo, memoryRuleRepo, memoryVariantRepo)
+		URLRepo, ClickRepo, TagRepo = memoryURLRepo, memoryURLRepo, memoryTagRepo
+		RuleRepo, VariantRepo = memoryRuleRepo, memoryVariantRepo
+		DomainRepo = domainRepo.NewMemoryDomainRepository()
+		CampaignRepo = campaignRepo.NewMemoryCampaignRepository(memoryURLRepo)
+		WebhookRepo = webhookRepo.NewMemoryWebhookRepository()
+		DeliveryRepo = deliveryRepo.NewMemoryDeliveryRepository()
+	}
+	// The outbox is only read by the relay, which does not run with memory storage
+	OutboxRepo := outboxRepo.NewOutboxRepository(db)
+	Dispatcher := webhook.NewDispatcher(WebhookRepo, DeliveryRepo, cfg)
+
+	// Hand the events written to the outbox to the configured publishers
+	publishers := make([]outbox.Publisher, 0, len(cfg.Server.OutboxPublishers))
+	for _, name := range cfg.Server.OutboxPublishers {
+		switch name {
+		case constant.OutboxPublisherLog:
+			publishers = append(publishers, outbox.NewLogPublisher())
+		case constant.OutboxPublisherWebhook:
+			publishers = append(publishers, Dispatcher)
+		case constant.OutboxPublisherNATS:
+			natsClient, err := nats.NewClient(cfg.Server.NATSURL, cfg.Server.NATSTimeout)
+			if err != nil {
+				log.Fatal("err connect nats ", err)
+			}
+			defer natsClient.Close()
+			publishers = append(publishers, outbox.NewNATSPublisher(natsClient, cfg.Server.NATSSubjectPrefix))
+		}
+	}
+	Relay := outbox.NewRelay(OutboxRepo, publishers, cfg)
+
+	// Click events are only written while a publisher takes them
+	URLApp := url.NewURLApplication(URLRepo, RuleRepo, VariantRepo, ClickRepo, DomainRepo, TagRepo, CampaignRepo, geoLocator, Relay, cfg)
+	RuleApp := rule.NewRuleApplication(URLRepo, RuleRepo, DomainRepo)
+	DomainApp := domain.NewDomainApplication(DomainRepo, URLRepo, cfg)
+	CampaignApp := campaign.NewCampaignApplication(CampaignRepo)
+	TagApp := tag.NewTagApplication(TagRepo)
+	WebhookApp := webhook.NewWebhookApplication(WebhookRepo, DeliveryRepo, Dispatcher)
+	httpTransport := transport.NewTransport(URLApp, RuleApp, DomainApp, CampaignApp, TagApp, WebhookApp, cfg)
+	grpcServer := transport.NewGRPCServer(URLApp, cfg)
+
+	// Stop on SIGINT or SIGTERM once the running requests are done
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Publish the outbox, send webhook deliveries and announce the links reaching the end
+	// of their activation window in the background. Memory storage writes no outbox events.
+	if !memoryStorage {
+		go Relay.Run(ctx)
+		go Dispatcher.Run(ctx)
+	}
+	go runExpiryNotifier(ctx, URLApp, cfg.Server.ExpiryPollInterval)
+
+	// Serve the gRPC API on its own port
+	grpcListener, err := net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+	if err != nil {
+		log.Fatal("err listen grpc ", err)
+	}
+	go func() {
+		log.Printf("gRPC server running on port %s", cfg.Server.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalln("failed grpc server ", err)
+		}
+	}()
 
 	// Create HTTP server
 	server := &http.Server{
@@ -49,9 +194,111 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
-	log.Printf("HTTP server running on port %s", cfg.Server.Port)
-	err = server.ListenAndServe()
+	go func() {
+		log.Printf("HTTP server running on port %s", cfg.Server.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalln("failed server ", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("err shutdown http server ", err)
+	}
+	// GracefulStop waits for the open Resolve streams, they are cut once the timeout is over
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+}
+
+// runExpiryNotifier announces the links whose activation window ended every interval
+// until ctx is done
+func runExpiryNotifier(ctx context.Context, URLApp url.URLApp, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := URLApp.NotifyExpiredLinks(ctx); err != nil {
+			log.Println("[runExpiryNotifier] err NotifyExpiredLinks", err)
+		}
+	}
+}
+
+// runMigrate runs a migrate subcommand on its own connection since migrations send
+// several statements per query
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: migrate up|down|status")
+	}
+
+	conn, err := sqlx.Connect(cfg.Database.Driver, cfg.GetMigrateDSN())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	fsys, err := migrations.ForDriver(cfg.Database.Driver)
 	if err != nil {
-		log.Fatalln("failed server ", err)
+		return err
+	}
+
+	migrator, err := migrate.NewMigrator(conn, fsys, cfg.Database.MigrateLockTimeout)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Printf("Applied migration %s", migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			log.Println("Database is up to date")
+		}
+		return err
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Rolled back migration %s", migration.Name)
+		return nil
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for _, status := range statuses {
+			mark := "X"
+			if !status.Applied {
+				mark = " "
+				pending++
+			}
+			fmt.Printf("[%s] %s\n", mark, status.Name)
+		}
+		fmt.Printf("\nApplied: %d\nPending: %d\n", len(statuses)-pending, pending)
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q, use up, down or status", args[0])
 	}
 }
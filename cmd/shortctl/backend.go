@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	campaignRepo "github.com/muhammadheryan/url-shortner-base62/repository/campaign"
+	clickRepo "github.com/muhammadheryan/url-shortner-base62/repository/click"
+	domainRepo "github.com/muhammadheryan/url-shortner-base62/repository/domain"
+	ruleRepo "github.com/muhammadheryan/url-shortner-base62/repository/rule"
+	tagRepo "github.com/muhammadheryan/url-shortner-base62/repository/tag"
+	urlRepo "github.com/muhammadheryan/url-shortner-base62/repository/url"
+	variantRepo "github.com/muhammadheryan/url-shortner-base62/repository/variant"
+	"github.com/muhammadheryan/url-shortner-base62/utils/geoip"
+	_ "modernc.org/sqlite"
+)
+
+// backend runs the commands, either through the REST API with client.Client or on the
+// database in admin mode
+type backend interface {
+	CreateURL(ctx context.Context, req *model.CreateURLShortnerRequest) (*model.GetURLResponse, error)
+	GetURLInfo(ctx context.Context, domain, shortURL string) (*model.GetURLInfoResponse, error)
+	ListURLs(ctx context.Context, req *model.ListURLRequest) (*model.ListURLResponse, error)
+	SetURLTags(ctx context.Context, domain, shortURL string, req *model.SetURLTagsRequest) ([]string, error)
+	SetURLCampaign(ctx context.Context, domain, shortURL string, req *model.SetURLCampaignRequest) error
+	DeleteURL(ctx context.Context, domain, shortURL string) error
+}
+
+// adminBackend calls the application layer on the database of the service, the destination
+// of protected and single use links is shown and the events are written to the outbox
+// like for changes made through the API. Links are looked up on the given custom domain,
+// the default domain when it is empty.
+type adminBackend struct {
+	URLApp     url.URLApp
+	db         *sqlx.DB
+	geoLocator geoip.Locator
+}
+
+func newAdminBackend(cfg *config.Config) (*adminBackend, error) {
+	db, err := sqlx.Connect(cfg.Database.Driver, cfg.GetDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	geoLocator, err := geoip.NewLocator(cfg.Server.GeoIPDatabasePath)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	URLRepo := urlRepo.NewURLRepository(db)
+	switch cfg.Database.Driver {
+	case constant.DriverPostgres:
+		URLRepo = urlRepo.NewPostgresURLRepository(db)
+	case constant.DriverSQLite:
+		URLRepo = urlRepo.NewSQLiteURLRepository(db)
+	}
+
+	URLApp := url.NewURLApplication(
+		URLRepo,
+		ruleRepo.NewRuleRepository(db),
+		variantRepo.NewVariantRepository(db),
+		clickRepo.NewClickRepository(db),
+		domainRepo.NewDomainRepository(db),
+		tagRepo.NewTagRepository(db),
+		campaignRepo.NewCampaignRepository(db),
+		geoLocator,
+		nil,
+		cfg,
+	)
+
+	return &adminBackend{URLApp: URLApp, db: db, geoLocator: geoLocator}, nil
+}
+
+func (a *adminBackend) CreateURL(ctx context.Context, req *model.CreateURLShortnerRequest) (*model.GetURLResponse, error) {
+	return a.URLApp.CreateURLShortner(ctx, req)
+}
+
+func (a *adminBackend) GetURLInfo(ctx context.Context, domain, shortURL string) (*model.GetURLInfoResponse, error) {
+	return a.URLApp.GetURLInfo(ctx, domain, shortURL)
+}
+
+func (a *adminBackend) ListURLs(ctx context.Context, req *model.ListURLRequest) (*model.ListURLResponse, error) {
+	return a.URLApp.ListURLs(ctx, req)
+}
+
+func (a *adminBackend) SetURLTags(ctx context.Context, domain, shortURL string, req *model.SetURLTagsRequest) ([]string, error) {
+	return a.URLApp.SetURLTags(ctx, domain, shortURL, req)
+}
+
+func (a *adminBackend) SetURLCampaign(ctx context.Context, domain, shortURL string, req *model.SetURLCampaignRequest) error {
+	return a.URLApp.SetURLCampaign(ctx, domain, shortURL, req)
+}
+
+func (a *adminBackend) DeleteURL(ctx context.Context, domain, shortURL string) error {
+	return a.URLApp.DeleteURL(ctx, domain, shortURL)
+}
+
+func (a *adminBackend) Close() error {
+	return errors.Join(geoip.Close(a.geoLocator), a.db.Close())
+}
@@ -0,0 +1,385 @@
+// Command shortctl manages short links from scripts, through the REST API or, in admin
+// mode, directly on the database of the service.
+//
+// Usage:
+//
+//	shortctl [-config file] [-api url] [-admin] [-o table|json] <command> [flags] [short url]
+//
+// Settings are read from the environment (SHORTCTL_API_URL, SHORTCTL_TIMEOUT, SHORTCTL_ADMIN,
+// SHORTCTL_OUTPUT) or a file of KEY=VALUE lines given with -config or SHORTCTL_CONFIG,
+// the environment wins over the file. Admin mode also reads the database settings of
+// the service (DB_HOST, DB_USER, ...) from there.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/muhammadheryan/url-shortner-base62/client"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// exportPageSize is the number of links fetched per page by export, the most the API returns
+const exportPageSize = 100
+
+// errUsage is returned when the command line is wrong, the usage was already printed
+var errUsage = errors.New("invalid usage")
+
+const usage = `Usage: shortctl [global flags] <command> [flags] [short url]
+
+Commands:
+  create   -url URL [-domain D] [-campaign ID] [-tags a,b] [-redirect 301|302|307|308] [-password P] [-single-use]
+  resolve  [-domain D] SHORT_URL      show the destination of a link without counting a click
+  list     [-campaign ID] [-tag T] [-limit N] [-offset N]
+  update   [-domain D] [-tags a,b] [-campaign ID] SHORT_URL
+                                      replace the tags (empty to remove them) or move to a campaign (0 for none)
+  delete   [-domain D] SHORT_URL
+  export   [-format csv|json] [-campaign ID] [-tag T] [-out FILE]
+
+Global flags:
+`
+
+// cli holds the settings shared by the commands
+type cli struct {
+	backend backend
+	output  string
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+func main() {
+	if code := exitCode(run(context.Background(), os.Args[1:], os.Stdout, os.Stderr), os.Stderr); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// exitCode prints the error of run and returns the exit status, 2 for a wrong command
+// line whose usage was already printed
+func exitCode(err error, stderr io.Writer) int {
+	switch {
+	case err == nil:
+		return 0
+	case err == errUsage:
+		return 2
+	default:
+		fmt.Fprintln(stderr, "shortctl:", err)
+		return 1
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	global := flag.NewFlagSet("shortctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		global.PrintDefaults()
+	}
+	configFile := global.String("config", os.Getenv("SHORTCTL_CONFIG"), "file of KEY=VALUE settings")
+	apiURL := global.String("api", "", "base url of the API (SHORTCTL_API_URL, default http://localhost:8080)")
+	admin := global.Bool("admin", false, "use the database of the service instead of the API (SHORTCTL_ADMIN)")
+	output := global.String("o", "", "output format, table or json (SHORTCTL_OUTPUT, default table)")
+	if err := global.Parse(args); err != nil {
+		return errUsage
+	}
+
+	// the flags win over the environment, the environment over the file
+	if *configFile != "" {
+		if err := godotenv.Load(*configFile); err != nil {
+			return err
+		}
+	}
+	if *apiURL == "" {
+		*apiURL = getEnv("SHORTCTL_API_URL", "http://localhost:8080")
+	}
+	if *output == "" {
+		*output = getEnv("SHORTCTL_OUTPUT", outputTable)
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return errUsage
+	}
+	if !isFlagSet(global, "admin") {
+		*admin, _ = strconv.ParseBool(os.Getenv("SHORTCTL_ADMIN"))
+	}
+
+	if global.NArg() == 0 {
+		global.Usage()
+		return errUsage
+	}
+
+	c := &cli{output: *output, stdout: stdout, stderr: stderr}
+	if *admin {
+		adminBackend, err := newAdminBackend(config.Load())
+		if err != nil {
+			return err
+		}
+		defer adminBackend.Close()
+		c.backend = adminBackend
+	} else {
+		timeout, err := strconv.Atoi(getEnv("SHORTCTL_TIMEOUT", "10"))
+		if err != nil {
+			return fmt.Errorf("invalid SHORTCTL_TIMEOUT: %w", err)
+		}
+		c.backend = client.NewClient(*apiURL, time.Duration(timeout)*time.Second)
+	}
+
+	commands := map[string]func(context.Context, []string) error{
+		"create":  c.create,
+		"resolve": c.resolve,
+		"list":    c.list,
+		"update":  c.update,
+		"delete":  c.delete,
+		"export":  c.export,
+	}
+	command, ok := commands[global.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", global.Arg(0))
+		global.Usage()
+		return errUsage
+	}
+
+	return command(ctx, global.Args()[1:])
+}
+
+func (c *cli) create(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("create")
+	originalURL := flags.String("url", "", "destination of the link")
+	domain := flags.String("domain", "", "verified custom domain to serve the link on")
+	campaignID := flags.Uint64("campaign", 0, "campaign of the link")
+	tags := flags.String("tags", "", "comma separated tags")
+	redirectType := flags.Int("redirect", 0, "redirect type, server default when empty")
+	password := flags.String("password", "", "password visitors have to enter")
+	singleUse := flags.Bool("single-use", false, "stop working after the first visit")
+	if err := flags.Parse(args); err != nil || *originalURL == "" || flags.NArg() != 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	data, err := c.backend.CreateURL(ctx, &model.CreateURLShortnerRequest{
+		OriginalURL:  *originalURL,
+		Domain:       *domain,
+		CampaignID:   *campaignID,
+		Tags:         splitTags(*tags),
+		RedirectType: *redirectType,
+		Password:     *password,
+		SingleUse:    *singleUse,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, data)
+	}
+	return writeURL(c.stdout, data)
+}
+
+func (c *cli) resolve(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("resolve")
+	domain := flags.String("domain", "", "custom domain of the link, the default domain when empty")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	data, err := c.backend.GetURLInfo(ctx, *domain, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, data)
+	}
+	return writeURLInfo(c.stdout, data)
+}
+
+func (c *cli) list(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("list")
+	campaignID := flags.Uint64("campaign", 0, "only the links of the campaign")
+	tag := flags.String("tag", "", "only the links with the tag")
+	limit := flags.Int("limit", 0, "page size, 20 by default and at most 100")
+	offset := flags.Int("offset", 0, "number of links to skip")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	data, err := c.backend.ListURLs(ctx, &model.ListURLRequest{
+		CampaignID: *campaignID,
+		Tag:        *tag,
+		Limit:      *limit,
+		Offset:     *offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, data)
+	}
+	return writeURLTable(c.stdout, data.Items)
+}
+
+// update only changes what is given, so tags and campaign can be set separately
+func (c *cli) update(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("update")
+	domain := flags.String("domain", "", "custom domain of the link, the default domain when empty")
+	tags := flags.String("tags", "", "comma separated tags replacing those of the link, empty to remove them")
+	campaignID := flags.Uint64("campaign", 0, "campaign to move the link to, 0 to remove it from its campaign")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+	if !isFlagSet(flags, "tags") && !isFlagSet(flags, "campaign") {
+		fmt.Fprintln(flags.Output(), "nothing to update, give -tags or -campaign")
+		return errUsage
+	}
+	shortURL := flags.Arg(0)
+
+	if isFlagSet(flags, "tags") {
+		if _, err := c.backend.SetURLTags(ctx, *domain, shortURL, &model.SetURLTagsRequest{Tags: splitTags(*tags)}); err != nil {
+			return err
+		}
+	}
+	if isFlagSet(flags, "campaign") {
+		if err := c.backend.SetURLCampaign(ctx, *domain, shortURL, &model.SetURLCampaignRequest{CampaignID: *campaignID}); err != nil {
+			return err
+		}
+	}
+
+	return c.resolve(ctx, []string{"-domain", *domain, shortURL})
+}
+
+func (c *cli) delete(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("delete")
+	domain := flags.String("domain", "", "custom domain of the link, the default domain when empty")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	if err := c.backend.DeleteURL(ctx, *domain, flags.Arg(0)); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return writeJSON(c.stdout, map[string]string{"deleted": flags.Arg(0)})
+	}
+	fmt.Fprintln(c.stdout, "deleted", flags.Arg(0))
+	return nil
+}
+
+// exporter writes the links read by export
+type exporter interface {
+	Write(item *model.GetURLInfoResponse) error
+	Close() error
+}
+
+// export pages through every matching link, newest first. Links created while it runs
+// shift the pages, so one may be written twice.
+func (c *cli) export(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("export")
+	format := flags.String("format", exportCSV, "csv or json, one object per line")
+	campaignID := flags.Uint64("campaign", 0, "only the links of the campaign")
+	tag := flags.String("tag", "", "only the links with the tag")
+	out := flags.String("out", "", "file to write, standard output when empty")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	w := c.stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	var exp exporter
+	switch *format {
+	case exportCSV:
+		csvExp, err := newCSVExporter(w)
+		if err != nil {
+			return err
+		}
+		exp = csvExp
+	case exportJSON:
+		exp = newJSONExporter(w)
+	default:
+		fmt.Fprintf(flags.Output(), "unknown export format %q\n", *format)
+		return errUsage
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		data, err := c.backend.ListURLs(ctx, &model.ListURLRequest{
+			CampaignID: *campaignID,
+			Tag:        *tag,
+			Limit:      exportPageSize,
+			Offset:     offset,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, item := range data.Items {
+			if err := exp.Write(item); err != nil {
+				return err
+			}
+		}
+		if len(data.Items) < exportPageSize {
+			break
+		}
+	}
+
+	return exp.Close()
+}
+
+func (c *cli) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s:\n", name)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// splitTags reads a comma separated list, blanks are dropped
+func splitTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
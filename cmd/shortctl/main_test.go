@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/client"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// linkInfo is a link listed by the fake API
+func linkInfo(shortURL string, clicks uint64) *model.GetURLInfoResponse {
+	return &model.GetURLInfoResponse{
+		GetURLResponse: model.GetURLResponse{
+			ShortURL:    shortURL,
+			ShortLink:   "https://sho.rt/url/" + shortURL,
+			OriginalURL: "https://example.com/" + shortURL,
+			Tags:        []string{"docs"},
+			CreatedAt:   time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		},
+		Status:     "active",
+		ClickCount: clicks,
+		Active:     true,
+	}
+}
+
+// newAPI serves the routes of the REST API used by shortctl, unknown links are not found
+func newAPI(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	write := func(w http.ResponseWriter, status int, code, message string, data interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": message, "data": data})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/url":
+			var req model.CreateURLShortnerRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				write(w, http.StatusBadRequest, "0003", "invalid request", nil)
+				return
+			}
+			if req.OriginalURL == "not a url" {
+				write(w, http.StatusBadRequest, "0003", "invalid request", nil)
+				return
+			}
+			write(w, http.StatusOK, "0000", "success", model.GetURLResponse{
+				ShortURL:     "0000A",
+				ShortLink:    "https://sho.rt/url/0000A",
+				OriginalURL:  req.OriginalURL,
+				Tags:         req.Tags,
+				CampaignID:   req.CampaignID,
+				RedirectType: http.StatusFound,
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/url":
+			items := []*model.GetURLInfoResponse{}
+			if r.URL.Query().Get("tag") == "docs" {
+				items = append(items, linkInfo("0000B", 5), linkInfo("0000A", 2))
+			}
+			write(w, http.StatusOK, "0000", "success", model.ListURLResponse{Items: items, Limit: 20})
+		case r.Method == http.MethodDelete && r.URL.Path == "/url/0000A":
+			write(w, http.StatusOK, "0000", "success", nil)
+		// 0000C only exists on the custom domain go.acme.com
+		case r.Method == http.MethodDelete && r.URL.Path == "/url/0000C" && r.Host == "go.acme.com":
+			write(w, http.StatusOK, "0000", "success", nil)
+		default:
+			write(w, http.StatusBadRequest, "0002", "data not found", nil)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// the settings of the machine running the tests must not change the commands
+	for _, key := range []string{"SHORTCTL_CONFIG", "SHORTCTL_API_URL", "SHORTCTL_TIMEOUT", "SHORTCTL_ADMIN", "SHORTCTL_OUTPUT"} {
+		t.Setenv(key, "")
+	}
+	return server
+}
+
+// runCLI runs shortctl against the fake API and returns what it printed
+func runCLI(t *testing.T, server *httptest.Server, args ...string) (string, string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), append([]string{"-api", server.URL}, args...), &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRun_Create(t *testing.T) {
+	server := newAPI(t)
+
+	stdout, _, err := runCLI(t, server, "create", "-url", "https://example.com/docs", "-tags", "docs, ,api", "-campaign", "3")
+	if err != nil {
+		t.Fatalf("create error = %v", err)
+	}
+	for _, want := range []string{"https://sho.rt/url/0000A", "https://example.com/docs", "docs,api", "302"} {
+		if !strings.Contains(stdout, want) {
+			t.Fatalf("create output does not show %q:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, err = runCLI(t, server, "-o", "json", "create", "-url", "https://example.com/docs")
+	if err != nil {
+		t.Fatalf("create -o json error = %v", err)
+	}
+	var created model.GetURLResponse
+	if err := json.Unmarshal([]byte(stdout), &created); err != nil || created.ShortURL != "0000A" {
+		t.Fatalf("create -o json output = %s, %v", stdout, err)
+	}
+}
+
+func TestRun_List(t *testing.T) {
+	server := newAPI(t)
+
+	stdout, _, err := runCLI(t, server, "list", "-tag", "docs")
+	if err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "0000B") || !strings.Contains(lines[2], "0000A") {
+		t.Fatalf("list output = %q, want a header and the links newest first", stdout)
+	}
+
+	stdout, _, err = runCLI(t, server, "-o", "json", "list", "-tag", "docs")
+	if err != nil {
+		t.Fatalf("list -o json error = %v", err)
+	}
+	var listed model.ListURLResponse
+	if err := json.Unmarshal([]byte(stdout), &listed); err != nil || len(listed.Items) != 2 || listed.Items[0].ClickCount != 5 {
+		t.Fatalf("list -o json output = %s, %v", stdout, err)
+	}
+}
+
+func TestRun_Delete(t *testing.T) {
+	server := newAPI(t)
+
+	stdout, _, err := runCLI(t, server, "delete", "0000A")
+	if err != nil || strings.TrimSpace(stdout) != "deleted 0000A" {
+		t.Fatalf("delete = %q, %v", stdout, err)
+	}
+
+	stdout, _, err = runCLI(t, server, "delete", "-domain", "go.acme.com", "0000C")
+	if err != nil || strings.TrimSpace(stdout) != "deleted 0000C" {
+		t.Fatalf("delete -domain = %q, %v", stdout, err)
+	}
+	if _, _, err = runCLI(t, server, "delete", "0000C"); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("delete on the default domain error = %v, want ErrNotFound", err)
+	}
+
+	_, _, err = runCLI(t, server, "delete", "0000Z")
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("delete of an unknown link error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRun_Export(t *testing.T) {
+	server := newAPI(t)
+	out := filepath.Join(t.TempDir(), "links.csv")
+
+	if _, _, err := runCLI(t, server, "export", "-tag", "docs", "-out", out); err != nil {
+		t.Fatalf("export error = %v", err)
+	}
+	file, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("exported csv error = %v", err)
+	}
+	if len(records) != 3 || strings.Join(records[0], ",") != strings.Join(csvHeader, ",") || records[1][0] != "0000B" || records[2][0] != "0000A" {
+		t.Fatalf("exported csv = %v", records)
+	}
+
+	stdout, _, err := runCLI(t, server, "export", "-format", "json", "-tag", "docs")
+	if err != nil {
+		t.Fatalf("export -format json error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"short_url":"0000B"`) {
+		t.Fatalf("export -format json output = %q, want one link per line", stdout)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantErr    error
+		wantCode   int
+		wantStderr string
+	}{
+		{name: "no command", args: nil, wantErr: errUsage, wantCode: 2, wantStderr: "Usage: shortctl"},
+		{name: "unknown command", args: []string{"migrate", "up"}, wantErr: errUsage, wantCode: 2, wantStderr: `unknown command "migrate"`},
+		{name: "unknown output", args: []string{"-o", "yaml", "list"}, wantErr: errUsage, wantCode: 2, wantStderr: `unknown output format "yaml"`},
+		{name: "create without url", args: []string{"create"}, wantErr: errUsage, wantCode: 2, wantStderr: "Usage of create"},
+		{name: "update without change", args: []string{"update", "0000A"}, wantErr: errUsage, wantCode: 2, wantStderr: "nothing to update"},
+		{name: "unknown export format", args: []string{"export", "-format", "xml"}, wantErr: errUsage, wantCode: 2, wantStderr: `unknown export format "xml"`},
+		{name: "rejected by the API", args: []string{"create", "-url", "not a url"}, wantErr: client.ErrInvalidRequest, wantCode: 1, wantStderr: "shortctl: url-shortner: invalid request (code 0003, status 400)"},
+		{name: "unknown link", args: []string{"resolve", "0000Z"}, wantErr: client.ErrNotFound, wantCode: 1, wantStderr: "shortctl: url-shortner: data not found (code 0002, status 400)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAPI(t)
+
+			stdout, stderr, err := runCLI(t, server, tt.args...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("run() error = %v, want %v", err, tt.wantErr)
+			}
+			if stdout != "" {
+				t.Fatalf("run() printed %q on stdout, want nothing", stdout)
+			}
+
+			var errOut bytes.Buffer
+			if code := exitCode(err, &errOut); code != tt.wantCode {
+				t.Fatalf("exitCode() = %d, want %d", code, tt.wantCode)
+			}
+			if got := stderr + errOut.String(); !strings.Contains(got, tt.wantStderr) {
+				t.Fatalf("stderr = %q, want %q", got, tt.wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Output formats of the commands
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// Formats of the export command
+const (
+	exportCSV  = "csv"
+	exportJSON = "json"
+)
+
+// csvHeader are the columns of an exported link
+var csvHeader = []string{"short_url", "short_link", "original_url", "domain", "campaign_id", "tags", "status", "click_count", "created_at"}
+
+func writeJSON(w io.Writer, data interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// writeFields prints name and value pairs, one per line
+func writeFields(w io.Writer, fields [][2]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+	}
+	return tw.Flush()
+}
+
+func writeURL(w io.Writer, data *model.GetURLResponse) error {
+	return writeFields(w, urlFields(data))
+}
+
+func writeURLInfo(w io.Writer, data *model.GetURLInfoResponse) error {
+	return writeFields(w, append(urlFields(&data.GetURLResponse),
+		[2]string{"Status", data.Status},
+		[2]string{"Active", strconv.FormatBool(data.Active)},
+		[2]string{"Clicks", strconv.FormatUint(data.ClickCount, 10)},
+	))
+}
+
+func urlFields(data *model.GetURLResponse) [][2]string {
+	return [][2]string{
+		{"Short URL", data.ShortURL},
+		{"Short link", data.ShortLink},
+		{"Destination", data.OriginalURL},
+		{"Domain", data.Domain},
+		{"Campaign", formatID(data.CampaignID)},
+		{"Tags", strings.Join(data.Tags, ",")},
+		{"Redirect", formatInt(data.RedirectType)},
+		{"Created at", data.CreatedAt.Format(time.RFC3339)},
+	}
+}
+
+// writeURLTable prints one link per row
+func writeURLTable(w io.Writer, items []*model.GetURLInfoResponse) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SHORT URL\tDESTINATION\tDOMAIN\tCAMPAIGN\tTAGS\tSTATUS\tCLICKS\tCREATED AT")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			item.ShortURL, item.OriginalURL, item.Domain, formatID(item.CampaignID), strings.Join(item.Tags, ","),
+			item.Status, item.ClickCount, item.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+// csvExporter writes the exported links as CSV rows
+type csvExporter struct {
+	writer *csv.Writer
+}
+
+func newCSVExporter(w io.Writer) (*csvExporter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return &csvExporter{writer: writer}, nil
+}
+
+func (e *csvExporter) Write(item *model.GetURLInfoResponse) error {
+	return e.writer.Write([]string{
+		item.ShortURL,
+		item.ShortLink,
+		item.OriginalURL,
+		item.Domain,
+		formatID(item.CampaignID),
+		strings.Join(item.Tags, ","),
+		item.Status,
+		strconv.FormatUint(item.ClickCount, 10),
+		item.CreatedAt.Format(time.RFC3339),
+	})
+}
+
+func (e *csvExporter) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonExporter writes the exported links as one JSON object per line
+type jsonExporter struct {
+	encoder *json.Encoder
+}
+
+func newJSONExporter(w io.Writer) *jsonExporter {
+	return &jsonExporter{encoder: json.NewEncoder(w)}
+}
+
+func (e *jsonExporter) Write(item *model.GetURLInfoResponse) error {
+	return e.encoder.Encode(item)
+}
+
+func (e *jsonExporter) Close() error {
+	return nil
+}
+
+func formatID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+func formatInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
@@ -10,28 +10,52 @@ const (
 	ErrNotFound
 	ErrInvalidRequest
 	ErrUnauthorize
+	ErrPasswordRequired
+	ErrTooManyRequests
+	ErrGone
+	ErrNotAvailable
+	ErrVerificationFailed
+	ErrConflict
 )
 
 var ErrorTypeMessage = map[ErrorType]string{
-	Successful:        "success",
-	ErrInternal:       "error internal",
-	ErrNotFound:       "data not found",
-	ErrInvalidRequest: "invalid request",
-	ErrUnauthorize:    "unauthorize request",
+	Successful:            "success",
+	ErrInternal:           "error internal",
+	ErrNotFound:           "data not found",
+	ErrInvalidRequest:     "invalid request",
+	ErrUnauthorize:        "unauthorize request",
+	ErrPasswordRequired:   "password required",
+	ErrTooManyRequests:    "too many requests",
+	ErrGone:               "data no longer available",
+	ErrNotAvailable:       "data not available at this time",
+	ErrVerificationFailed: "verification failed",
+	ErrConflict:           "data already exists",
 }
 
 var ErrorTypeHTTPCode = map[ErrorType]int{
-	Successful:        http.StatusOK,
-	ErrInternal:       http.StatusInternalServerError,
-	ErrNotFound:       http.StatusBadRequest,
-	ErrInvalidRequest: http.StatusBadRequest,
-	ErrUnauthorize:    http.StatusUnauthorized,
+	Successful:            http.StatusOK,
+	ErrInternal:           http.StatusInternalServerError,
+	ErrNotFound:           http.StatusBadRequest,
+	ErrInvalidRequest:     http.StatusBadRequest,
+	ErrUnauthorize:        http.StatusUnauthorized,
+	ErrPasswordRequired:   http.StatusUnauthorized,
+	ErrTooManyRequests:    http.StatusTooManyRequests,
+	ErrGone:               http.StatusGone,
+	ErrNotAvailable:       http.StatusForbidden,
+	ErrVerificationFailed: http.StatusUnprocessableEntity,
+	ErrConflict:           http.StatusConflict,
 }
 
 var ErrorTypeCode = map[ErrorType]string{
-	Successful:        "0000",
-	ErrInternal:       "0001",
-	ErrNotFound:       "0002",
-	ErrInvalidRequest: "0003",
-	ErrUnauthorize:    "0004",
+	Successful:            "0000",
+	ErrInternal:           "0001",
+	ErrNotFound:           "0002",
+	ErrInvalidRequest:     "0003",
+	ErrUnauthorize:        "0004",
+	ErrPasswordRequired:   "0005",
+	ErrTooManyRequests:    "0006",
+	ErrGone:               "0007",
+	ErrNotAvailable:       "0008",
+	ErrVerificationFailed: "0009",
+	ErrConflict:           "0010",
 }
@@ -0,0 +1,18 @@
+package constant
+
+// Domain events about links, written to the outbox and handed to the publishers
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	// EventLinkExpired is written when a single use link is consumed or the activation window of a link ends
+	EventLinkExpired = "link.expired"
+	// EventLinkClicked is written on every click
+	EventLinkClicked = "link.clicked"
+)
+
+// Reasons a link expired
+const (
+	ExpiredReasonConsumed    = "consumed"
+	ExpiredReasonActiveUntil = "active_until"
+)
@@ -0,0 +1,23 @@
+package constant
+
+// Publishers the outbox events can be sent to
+const (
+	// OutboxPublisherLog writes every event to the service log
+	OutboxPublisherLog = "log"
+	// OutboxPublisherWebhook queues the events for the registered webhooks
+	OutboxPublisherWebhook = "webhook"
+	// OutboxPublisherNATS sends the events to a NATS server
+	OutboxPublisherNATS = "nats"
+)
+
+// OutboxPublishers holds the supported outbox publishers
+var OutboxPublishers = map[string]bool{
+	OutboxPublisherLog:     true,
+	OutboxPublisherWebhook: true,
+	OutboxPublisherNATS:    true,
+}
+
+// IsValidOutboxPublisher checks if name is one of the supported outbox publishers
+func IsValidOutboxPublisher(name string) bool {
+	return OutboxPublishers[name]
+}
@@ -0,0 +1,23 @@
+package constant
+
+// Query conflict resolutions, used when a forwarded query parameter is already on the destination
+const (
+	// QueryConflictKeep keeps the destination value and drops the incoming one
+	QueryConflictKeep = "keep"
+	// QueryConflictOverride replaces the destination value with the incoming one
+	QueryConflictOverride = "override"
+	// QueryConflictAppend keeps both values
+	QueryConflictAppend = "append"
+)
+
+// QueryConflicts holds the supported query conflict resolutions
+var QueryConflicts = map[string]bool{
+	QueryConflictKeep:     true,
+	QueryConflictOverride: true,
+	QueryConflictAppend:   true,
+}
+
+// IsValidQueryConflict checks if conflict is one of the supported resolutions
+func IsValidQueryConflict(conflict string) bool {
+	return QueryConflicts[conflict]
+}
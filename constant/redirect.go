@@ -0,0 +1,21 @@
+package constant
+
+import "net/http"
+
+// RedirectTypes holds the HTTP status codes a short link is allowed to redirect with
+var RedirectTypes = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             true,
+	http.StatusTemporaryRedirect: true,
+	http.StatusPermanentRedirect: true,
+}
+
+// IsValidRedirectType checks if code is one of the supported redirect status codes
+func IsValidRedirectType(code int) bool {
+	return RedirectTypes[code]
+}
+
+// IsPermanentRedirect checks if code tells clients the redirect may be cached
+func IsPermanentRedirect(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
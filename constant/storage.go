@@ -0,0 +1,42 @@
+package constant
+
+// Storages the links can be kept in
+const (
+	// StorageSQL keeps everything in the SQL database
+	StorageSQL = "sql"
+	// StorageMemory keeps the links, domains, tags, rules and variants in memory, they are
+	// lost on restart. Meant for development without a database.
+	StorageMemory = "memory"
+)
+
+// Storages holds the supported storages
+var Storages = map[string]bool{
+	StorageSQL:    true,
+	StorageMemory: true,
+}
+
+// IsValidStorage checks if storage is one of the supported storages
+func IsValidStorage(storage string) bool {
+	return Storages[storage]
+}
+
+// Database drivers of the sql storage
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	// DriverSQLite keeps the database in a single file, DB_NAME being its path
+	DriverSQLite = "sqlite"
+)
+
+// Drivers holds the supported database drivers with their default port, SQLite has none
+var Drivers = map[string]int{
+	DriverMySQL:    3306,
+	DriverPostgres: 5432,
+	DriverSQLite:   0,
+}
+
+// IsValidDriver checks if driver is one of the supported database drivers
+func IsValidDriver(driver string) bool {
+	_, ok := Drivers[driver]
+	return ok
+}
@@ -0,0 +1,7 @@
+package constant
+
+// URL status stored on the url table
+const (
+	URLStatusActive   = "active"
+	URLStatusConsumed = "consumed"
+)
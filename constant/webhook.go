@@ -0,0 +1,19 @@
+package constant
+
+// WebhookEventClickThreshold is sent when the click count of a link reaches one of the thresholds of the webhook
+const WebhookEventClickThreshold = "link.click_threshold"
+
+// WebhookEvents holds the events webhooks may subscribe to
+var WebhookEvents = map[string]bool{
+	EventLinkCreated:           true,
+	EventLinkUpdated:           true,
+	EventLinkDeleted:           true,
+	EventLinkExpired:           true,
+	EventLinkClicked:           true,
+	WebhookEventClickThreshold: true,
+}
+
+// IsValidWebhookEvent checks if event is one of the supported webhook events
+func IsValidWebhookEvent(event string) bool {
+	return WebhookEvents[event]
+}
@@ -0,0 +1,6 @@
+-- migrate:up
+ALTER TABLE url ADD COLUMN redirect_type SMALLINT NOT NULL DEFAULT 307;
+
+
+-- migrate:down
+ALTER TABLE url DROP COLUMN redirect_type;
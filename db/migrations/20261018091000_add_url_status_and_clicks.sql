@@ -0,0 +1,10 @@
+-- migrate:up
+ALTER TABLE url
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN click_count BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP COLUMN status,
+    DROP COLUMN click_count;
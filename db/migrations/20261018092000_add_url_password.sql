@@ -0,0 +1,6 @@
+-- migrate:up
+ALTER TABLE url ADD COLUMN password_hash VARCHAR(255) NOT NULL DEFAULT '';
+
+
+-- migrate:down
+ALTER TABLE url DROP COLUMN password_hash;
@@ -0,0 +1,10 @@
+-- migrate:up
+ALTER TABLE url
+    ADD COLUMN single_use BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN consumed_at TIMESTAMP NULL;
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP COLUMN single_use,
+    DROP COLUMN consumed_at;
@@ -0,0 +1,14 @@
+-- migrate:up
+ALTER TABLE url
+    ADD COLUMN active_from TIMESTAMP NULL,
+    ADD COLUMN active_until TIMESTAMP NULL,
+    ADD COLUMN schedule JSON NULL,
+    ADD COLUMN fallback_url VARCHAR(2048) NOT NULL DEFAULT '';
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP COLUMN active_from,
+    DROP COLUMN active_until,
+    DROP COLUMN schedule,
+    DROP COLUMN fallback_url;
@@ -0,0 +1,18 @@
+-- migrate:up
+CREATE TABLE url_rule (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    url_id BIGINT NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    country CHAR(2) NOT NULL DEFAULT '',
+    device_type VARCHAR(16) NOT NULL DEFAULT '',
+    os VARCHAR(16) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT '',
+    destination_url VARCHAR(2048) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    INDEX idx_url_rule_url_id_priority (url_id, priority)
+);
+
+
+-- migrate:down
+DROP TABLE url_rule;
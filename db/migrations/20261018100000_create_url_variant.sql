@@ -0,0 +1,20 @@
+-- migrate:up
+ALTER TABLE url
+    ADD COLUMN sticky_variant BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE url_variant (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    url_id BIGINT NOT NULL,
+    destination_url VARCHAR(2048) NOT NULL,
+    weight INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    INDEX idx_url_variant_url_id (url_id)
+);
+
+
+-- migrate:down
+DROP TABLE url_variant;
+
+ALTER TABLE url
+    DROP COLUMN sticky_variant;
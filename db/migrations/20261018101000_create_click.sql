@@ -0,0 +1,12 @@
+-- migrate:up
+CREATE TABLE click (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    url_id BIGINT NOT NULL,
+    variant_id BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_click_url_id_variant_id (url_id, variant_id)
+);
+
+
+-- migrate:down
+DROP TABLE click;
@@ -0,0 +1,10 @@
+-- migrate:up
+ALTER TABLE url
+    ADD COLUMN forward_query BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN query_conflict VARCHAR(16) NOT NULL DEFAULT '';
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP COLUMN forward_query,
+    DROP COLUMN query_conflict;
@@ -0,0 +1,8 @@
+-- migrate:up
+ALTER TABLE url
+    ADD COLUMN forward_path BOOLEAN NOT NULL DEFAULT FALSE;
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP COLUMN forward_path;
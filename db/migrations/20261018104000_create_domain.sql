@@ -0,0 +1,23 @@
+-- migrate:up
+CREATE TABLE domain (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    host VARCHAR(255) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    UNIQUE INDEX idx_domain_host (host)
+);
+
+ALTER TABLE url
+    ADD COLUMN domain_id BIGINT NOT NULL DEFAULT 0,
+    ADD INDEX idx_url_domain_id_short_url (domain_id, short_url);
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP INDEX idx_url_domain_id_short_url,
+    DROP COLUMN domain_id;
+
+DROP TABLE domain;
@@ -0,0 +1,39 @@
+-- migrate:up
+CREATE TABLE campaign (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(128) NOT NULL,
+    description VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    UNIQUE INDEX idx_campaign_name (name)
+);
+
+CREATE TABLE tag (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_tag_name (name)
+);
+
+CREATE TABLE url_tag (
+    url_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    PRIMARY KEY (url_id, tag_id),
+    INDEX idx_url_tag_tag_id (tag_id)
+);
+
+ALTER TABLE url
+    ADD COLUMN campaign_id BIGINT NOT NULL DEFAULT 0,
+    ADD INDEX idx_url_campaign_id (campaign_id);
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP INDEX idx_url_campaign_id,
+    DROP COLUMN campaign_id;
+
+DROP TABLE url_tag;
+
+DROP TABLE tag;
+
+DROP TABLE campaign;
@@ -0,0 +1,58 @@
+-- migrate:up
+CREATE TABLE webhook (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    events JSON NOT NULL,
+    click_thresholds JSON NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_delivery (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    webhook_id BIGINT NOT NULL,
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    lock_token VARCHAR(32) NULL,
+    locked_until TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_webhook_delivery_next_attempt_at (next_attempt_at),
+    INDEX idx_webhook_delivery_webhook_id (webhook_id),
+    INDEX idx_webhook_delivery_lock_token (lock_token)
+);
+
+CREATE TABLE webhook_dead_letter (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    webhook_id BIGINT NOT NULL,
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload JSON NOT NULL,
+    attempts INT NOT NULL,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    failed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_webhook_dead_letter_webhook_id (webhook_id)
+);
+
+ALTER TABLE url
+    ADD COLUMN expiry_notified_at TIMESTAMP NULL,
+    ADD INDEX idx_url_active_until (active_until);
+
+-- links already past their activation window are not announced as expired
+UPDATE url SET expiry_notified_at = NOW() WHERE active_until <= NOW();
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP INDEX idx_url_active_until,
+    DROP COLUMN expiry_notified_at;
+
+DROP TABLE webhook_dead_letter;
+
+DROP TABLE webhook_delivery;
+
+DROP TABLE webhook;
@@ -0,0 +1,19 @@
+-- migrate:up
+CREATE TABLE outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    url_id BIGINT NOT NULL,
+    payload JSON NOT NULL,
+    lock_token VARCHAR(32) NULL,
+    locked_until TIMESTAMP NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    failed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_outbox_lock_token (lock_token)
+);
+
+
+-- migrate:down
+DROP TABLE outbox;
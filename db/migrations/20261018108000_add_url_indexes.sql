@@ -0,0 +1,23 @@
+-- migrate:up
+ALTER TABLE url CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
+
+-- short urls are case sensitive, and NULL until the id of a new link is known so the
+-- unique index only applies to complete links. Codes are unique per domain, the index
+-- replaces the one used to resolve a code on the domain of a visit.
+ALTER TABLE url MODIFY short_url VARCHAR(20) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NULL DEFAULT NULL;
+UPDATE url SET short_url = NULL WHERE short_url = '';
+
+ALTER TABLE url
+    ADD UNIQUE INDEX idx_url_short_url (domain_id, short_url),
+    DROP INDEX idx_url_domain_id_short_url;
+
+
+-- migrate:down
+ALTER TABLE url
+    DROP INDEX idx_url_short_url,
+    ADD INDEX idx_url_domain_id_short_url (domain_id, short_url);
+UPDATE url SET short_url = '' WHERE short_url IS NULL;
+ALTER TABLE url MODIFY short_url VARCHAR(20) DEFAULT "";
+
+-- the table was created in the character set of the database
+ALTER TABLE url CONVERT TO CHARACTER SET DEFAULT;
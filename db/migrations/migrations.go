@@ -0,0 +1,30 @@
+// Package migrations embeds the migrations so the binary can apply them without dbmate,
+// the MySQL ones in this directory, the Postgres ones in postgres and the SQLite ones in sqlite
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed postgres/*.sql
+var postgresFS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// ForDriver returns the migrations of the database driver
+func ForDriver(driver string) (fs.FS, error) {
+	switch driver {
+	case constant.DriverPostgres:
+		return fs.Sub(postgresFS, "postgres")
+	case constant.DriverSQLite:
+		return fs.Sub(sqliteFS, "sqlite")
+	}
+	return FS, nil
+}
@@ -0,0 +1,180 @@
+-- migrate:up
+-- the schema of the MySQL migrations up to 20261018108000_add_url_indexes. AUTOINCREMENT
+-- keeps the ids of deleted links from being reused, SQLite has no sha256 so original_url is
+-- indexed itself and JSON is kept as TEXT.
+CREATE TABLE url (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL,
+    -- NULL until the id of a new link is known so the unique index only applies to complete links
+    short_url VARCHAR(20) NULL DEFAULT NULL,
+    original_url TEXT NOT NULL,
+    redirect_type SMALLINT NOT NULL DEFAULT 307,
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    click_count BIGINT NOT NULL DEFAULT 0,
+    password_hash VARCHAR(255) NOT NULL DEFAULT '',
+    single_use BOOLEAN NOT NULL DEFAULT FALSE,
+    consumed_at DATETIME NULL,
+    active_from DATETIME NULL,
+    active_until DATETIME NULL,
+    schedule TEXT NULL,
+    fallback_url VARCHAR(2048) NOT NULL DEFAULT '',
+    sticky_variant BOOLEAN NOT NULL DEFAULT FALSE,
+    forward_query BOOLEAN NOT NULL DEFAULT FALSE,
+    query_conflict VARCHAR(16) NOT NULL DEFAULT '',
+    forward_path BOOLEAN NOT NULL DEFAULT FALSE,
+    domain_id BIGINT NOT NULL DEFAULT 0,
+    campaign_id BIGINT NOT NULL DEFAULT 0,
+    expiry_notified_at DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL
+);
+
+CREATE UNIQUE INDEX idx_url_short_url ON url (domain_id, short_url);
+CREATE INDEX idx_url_original_url ON url (original_url);
+CREATE INDEX idx_url_campaign_id ON url (campaign_id);
+CREATE INDEX idx_url_active_until ON url (active_until);
+
+CREATE TABLE url_rule (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id BIGINT NOT NULL,
+    priority INT NOT NULL DEFAULT 0,
+    country CHAR(2) NOT NULL DEFAULT '',
+    device_type VARCHAR(16) NOT NULL DEFAULT '',
+    os VARCHAR(16) NOT NULL DEFAULT '',
+    language VARCHAR(16) NOT NULL DEFAULT '',
+    destination_url VARCHAR(2048) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL
+);
+
+CREATE INDEX idx_url_rule_url_id_priority ON url_rule (url_id, priority);
+
+CREATE TABLE url_variant (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id BIGINT NOT NULL,
+    destination_url VARCHAR(2048) NOT NULL,
+    weight INT NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL
+);
+
+CREATE INDEX idx_url_variant_url_id ON url_variant (url_id);
+
+CREATE TABLE click (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url_id BIGINT NOT NULL,
+    variant_id BIGINT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_click_url_id_variant_id ON click (url_id, variant_id);
+
+CREATE TABLE domain (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NOT NULL DEFAULT 0,
+    host VARCHAR(255) NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL
+);
+
+CREATE UNIQUE INDEX idx_domain_host ON domain (host);
+
+CREATE TABLE campaign (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(128) NOT NULL,
+    description VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NULL
+);
+
+CREATE UNIQUE INDEX idx_campaign_name ON campaign (name);
+
+CREATE TABLE tag (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(64) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tag_name ON tag (name);
+
+CREATE TABLE url_tag (
+    url_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    PRIMARY KEY (url_id, tag_id)
+);
+
+CREATE INDEX idx_url_tag_tag_id ON url_tag (tag_id);
+
+CREATE TABLE webhook (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    events TEXT NOT NULL,
+    click_thresholds TEXT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE webhook_delivery (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id BIGINT NOT NULL,
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    lock_token VARCHAR(32) NULL,
+    locked_until DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_delivery_next_attempt_at ON webhook_delivery (next_attempt_at);
+CREATE INDEX idx_webhook_delivery_webhook_id ON webhook_delivery (webhook_id);
+CREATE INDEX idx_webhook_delivery_lock_token ON webhook_delivery (lock_token);
+
+CREATE TABLE webhook_dead_letter (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id BIGINT NOT NULL,
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INT NOT NULL,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    failed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_dead_letter_webhook_id ON webhook_dead_letter (webhook_id);
+
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id VARCHAR(32) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    url_id BIGINT NOT NULL,
+    payload TEXT NOT NULL,
+    lock_token VARCHAR(32) NULL,
+    locked_until DATETIME NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    failed_at DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_lock_token ON outbox (lock_token);
+
+
+-- migrate:down
+DROP TABLE outbox;
+DROP TABLE webhook_dead_letter;
+DROP TABLE webhook_delivery;
+DROP TABLE webhook;
+DROP TABLE url_tag;
+DROP TABLE tag;
+DROP TABLE campaign;
+DROP TABLE domain;
+DROP TABLE click;
+DROP TABLE url_variant;
+DROP TABLE url_rule;
+DROP TABLE url;
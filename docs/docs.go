@@ -15,24 +15,47 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/url": {
+        "/campaign": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List campaigns",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetCampaignResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Create a new short URL from original URL",
+                "description": "Create a campaign grouping short URLs, every link belongs to at most one campaign",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create short URL",
+                "summary": "Create campaign",
                 "parameters": [
                     {
-                        "description": "Create URL Request",
+                        "description": "Campaign",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateURLShortnerRequest"
+                            "$ref": "#/definitions/model.UpsertCampaignRequest"
                         }
                     }
                 ],
@@ -40,7 +63,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.GetURLResponse"
+                            "$ref": "#/definitions/model.GetCampaignResponse"
                         }
                     },
                     "400": {
@@ -52,67 +75,2133 @@ const docTemplate = `{
                 }
             }
         },
-        "/url/{shortURL}": {
+        "/campaign/{campaignID}": {
             "get": {
-                "description": "Redirect to original URL using short URL",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get campaign",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "campaignID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetCampaignResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "put": {
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Redirect to original URL",
+                "summary": "Update campaign",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Short URL",
-                        "name": "shortURL",
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "campaignID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Campaign",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpsertCampaignRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "308": {
-                        "description": "Redirect to original URL",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/model.GetCampaignResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a campaign, its short URLs are kept without a campaign",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete campaign",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "campaignID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/errors.CustomError"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "errors.CustomError": {
-            "type": "object"
         },
-        "model.CreateURLShortnerRequest": {
-            "type": "object",
-            "properties": {
-                "original_url": {
-                    "type": "string"
+        "/campaign/{campaignID}/stats": {
+            "get": {
+                "description": "Get the number of short URLs of a campaign and their total clicks",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get campaign click stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "campaignID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetCampaignStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
                 }
             }
         },
-        "model.GetURLResponse": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "original_url": {
-                    "type": "string"
-                },
-                "short_url": {
-                    "type": "string"
-                },
-                "updated_at": {
+        "/domain": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List custom domains",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetDomainResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register a short domain, serve the returned verification token at its verification url then verify it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register custom domain",
+                "parameters": [
+                    {
+                        "description": "Domain",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateDomainRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetDomainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/domain/{domainID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get custom domain",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Domain ID",
+                        "name": "domainID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetDomainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete custom domain",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Domain ID",
+                        "name": "domainID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/domain/{domainID}/verify": {
+            "post": {
+                "description": "Fetch the verification token served on the domain, links can only be created on verified domains",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Verify custom domain",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Domain ID",
+                        "name": "domainID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetDomainResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetTagResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a tag, tags are also created when first put on a short URL",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create tag",
+                "parameters": [
+                    {
+                        "description": "Tag",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetTagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag/{tagID}": {
+            "delete": {
+                "description": "Delete a tag and remove it from its short URLs",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tagID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url": {
+            "get": {
+                "description": "List the short URLs newest first, optionally only those of a campaign or with a tag",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List short URLs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Campaign ID",
+                        "name": "campaign_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, 20 by default and at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of links to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new short URL from original URL",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create short URL",
+                "parameters": [
+                    {
+                        "description": "Create URL Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateURLShortnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/batch": {
+            "post": {
+                "description": "Create many short URLs at once from a JSON array or a CSV upload (columns original_url and optional redirect_type), returns a result per item",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create short URLs in batch",
+                "parameters": [
+                    {
+                        "description": "Create URL Requests",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CreateURLShortnerRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateURLShortnerBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}": {
+            "get": {
+                "description": "Redirect to original URL using short URL, returns the link metadata instead when the client accepts application/json.\nLinks forwarding the path also answer on /url/{shortURL}/rest/of/path and append rest/of/path to the destination.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Redirect to original URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "301": {
+                        "description": "Permanent redirect to original URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "302": {
+                        "description": "Redirect to original URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "307": {
+                        "description": "Temporary redirect to original URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "308": {
+                        "description": "Permanent redirect to original URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Not available page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Verify the password of a protected short URL, on success a cookie is set and the visitor is sent back to the short URL with the forwarded path and query",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "summary": "Unlock password protected short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Link password",
+                        "name": "password",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Forwarded path of the visit",
+                        "name": "path",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Query string of the visit",
+                        "name": "query",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "Redirect to short URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Password form",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Password form",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a short URL with its rules, variants, clicks and tags",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}+": {
+            "get": {
+                "description": "Render an HTML page showing the destination of a short URL instead of redirecting",
+                "produces": [
+                    "text/html"
+                ],
+                "summary": "Preview short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Preview page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/campaign": {
+            "put": {
+                "description": "Move a short URL to a campaign, campaign_id 0 removes it from its campaign",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Set short URL campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Campaign",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SetURLCampaignRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/info": {
+            "get": {
+                "description": "Get metadata of a short URL (owner, status, click count) without redirecting",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get short URL metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetURLInfoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/qr": {
+            "get": {
+                "description": "Render the full short link as a QR code image",
+                "produces": [
+                    "image/png",
+                    "image/svg+xml"
+                ],
+                "summary": "Get QR code of short URL",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "png",
+                        "description": "Image format (png or svg)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 256,
+                        "description": "Image width and height in pixels (64-2048)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "M",
+                        "description": "Error correction level (L, M, Q or H)",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 4,
+                        "description": "Quiet zone in modules (0-16)",
+                        "name": "margin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "000000",
+                        "description": "Foreground color as hex",
+                        "name": "fg",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "ffffff",
+                        "description": "Background color as hex",
+                        "name": "bg",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "QR code image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/rules": {
+            "get": {
+                "description": "List the rules of a short URL in evaluation order, the destinations are left empty like on /info",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List targeting rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetRuleResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a rule sending visitors matching country, device type, OS or language to another destination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create targeting rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpsertRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/rules/{ruleID}": {
+            "put": {
+                "description": "Replace the conditions and destination of a rule",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update targeting rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "ruleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpsertRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetRuleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete targeting rule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rule ID",
+                        "name": "ruleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/stats": {
+            "get": {
+                "description": "Get the total clicks of a short URL and the clicks served by each of its variants",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get short URL click stats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetURLStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/url/{shortURL}/tags": {
+            "put": {
+                "description": "Replace the tags of a short URL, missing tags are created",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Set short URL tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short URL",
+                        "name": "shortURL",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SetURLTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetWebhookResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Register an endpoint receiving link events as JSON signed with HMAC-SHA256 in the X-Webhook-Signature header, the secret is only returned here",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook/{webhookID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a webhook with its pending deliveries and dead letters",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook/{webhookID}/dead-letters": {
+            "get": {
+                "description": "List the events not delivered after the last retry, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List webhook dead letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetWebhookDeadLetterResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhook/{webhookID}/dead-letters/{deadLetterID}/replay": {
+            "post": {
+                "description": "Queue a dead letter again, it is sent with the same event id so receivers can drop duplicates",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Replay webhook dead letter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "deadLetterID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/transport.body"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.CustomError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "errors.CustomError": {
+            "type": "object"
+        },
+        "model.BatchItemError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateDomainRequest": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "description": "Host is the short domain such as go.acme.com",
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateTagRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateURLShortnerBatchItem": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "type": "string"
+                },
+                "active_until": {
+                    "type": "string"
+                },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Domain is the custom domain the link is served on",
+                    "type": "string"
+                },
+                "error": {
+                    "$ref": "#/definitions/model.BatchItemError"
+                },
+                "fallback_url": {
+                    "type": "string"
+                },
+                "forward_path": {
+                    "type": "boolean"
+                },
+                "forward_query": {
+                    "type": "boolean"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "info_url": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "password_protected": {
+                    "description": "PasswordProtected tells the visitor has to enter a password before being redirected",
+                    "type": "boolean"
+                },
+                "preview_required": {
+                    "description": "PreviewRequired tells the link opens the preview page instead of redirecting",
+                    "type": "boolean"
+                },
+                "qr_url": {
+                    "type": "string"
+                },
+                "query_conflict": {
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "type": "integer"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/model.URLSchedule"
+                },
+                "short_link": {
+                    "description": "ShortLink, QRURL and InfoURL are absolute urls so clients don't have to know the public base url",
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                },
+                "single_use": {
+                    "description": "SingleUse tells the link stops working after its first visit",
+                    "type": "boolean"
+                },
+                "sticky_variant": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant_id": {
+                    "description": "VariantID is the variant served by this resolution",
+                    "type": "integer"
+                },
+                "variants": {
+                    "description": "Variants are the weighted destinations of a split link",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GetVariantResponse"
+                    }
+                }
+            }
+        },
+        "model.CreateURLShortnerBatchResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CreateURLShortnerBatchItem"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CreateURLShortnerRequest": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "description": "ActiveFrom and ActiveUntil bound when the link redirects",
+                    "type": "string"
+                },
+                "active_until": {
+                    "type": "string"
+                },
+                "campaign_id": {
+                    "description": "CampaignID puts the link in a campaign, Tags are created when missing",
+                    "type": "integer"
+                },
+                "domain": {
+                    "description": "Domain is a verified custom domain to serve the link on, the default domain when empty",
+                    "type": "string"
+                },
+                "fallback_url": {
+                    "description": "FallbackURL is used outside the activation window, a not available page is shown when empty",
+                    "type": "string"
+                },
+                "forward_path": {
+                    "description": "ForwardPath makes /url/{shortURL}/rest/of/path go to the destination followed by rest/of/path, except the paths info, qr, stats and rules served by the API",
+                    "type": "boolean"
+                },
+                "forward_query": {
+                    "description": "ForwardQuery appends the query of every visit to the destination",
+                    "type": "boolean"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password protects the link, visitors have to enter it before being redirected",
+                    "type": "string"
+                },
+                "query_conflict": {
+                    "description": "QueryConflict is keep, override or append, server default is used when empty",
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "description": "RedirectType is one of 301, 302, 307 or 308, server default is used when empty",
+                    "type": "integer"
+                },
+                "schedule": {
+                    "description": "Schedule further restricts the link to recurring weekly windows",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.URLSchedule"
+                        }
+                    ]
+                },
+                "single_use": {
+                    "description": "SingleUse makes the link stop working after its first visit",
+                    "type": "boolean"
+                },
+                "sticky_variant": {
+                    "description": "StickyVariant keeps serving a visitor the same variant",
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "utm": {
+                    "description": "UTM parameters are merged into the destination and the variants",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UTMParams"
+                        }
+                    ]
+                },
+                "variants": {
+                    "description": "Variants split the clicks between weighted destinations, OriginalURL is\nonly used when they can't be loaded",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.VariantRequest"
+                    }
+                }
+            }
+        },
+        "model.CreateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "click_thresholds": {
+                    "description": "ClickThresholds are the click counts announced by link.click_threshold",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetCampaignResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetCampaignStatsResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "links": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "total_clicks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.GetDomainResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "host": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "verification_token": {
+                    "description": "VerificationToken has to be served as plain text at VerificationURL before verifying",
+                    "type": "string"
+                },
+                "verification_url": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                },
+                "verified_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetRuleResponse": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "destination_url": {
+                    "type": "string"
+                },
+                "device_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "language": {
+                    "type": "string"
+                },
+                "os": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetTagResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.GetURLInfoResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active tells the link is currently inside its activation window",
+                    "type": "boolean"
+                },
+                "active_from": {
+                    "type": "string"
+                },
+                "active_until": {
+                    "type": "string"
+                },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "click_count": {
+                    "type": "integer"
+                },
+                "consumed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Domain is the custom domain the link is served on",
+                    "type": "string"
+                },
+                "fallback_url": {
+                    "type": "string"
+                },
+                "forward_path": {
+                    "type": "boolean"
+                },
+                "forward_query": {
+                    "type": "boolean"
+                },
+                "info_url": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "password_protected": {
+                    "description": "PasswordProtected tells the visitor has to enter a password before being redirected",
+                    "type": "boolean"
+                },
+                "preview_required": {
+                    "description": "PreviewRequired tells the link opens the preview page instead of redirecting",
+                    "type": "boolean"
+                },
+                "qr_url": {
+                    "type": "string"
+                },
+                "query_conflict": {
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "type": "integer"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/model.URLSchedule"
+                },
+                "short_link": {
+                    "description": "ShortLink, QRURL and InfoURL are absolute urls so clients don't have to know the public base url",
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                },
+                "single_use": {
+                    "description": "SingleUse tells the link stops working after its first visit",
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "sticky_variant": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "variant_id": {
+                    "description": "VariantID is the variant served by this resolution",
+                    "type": "integer"
+                },
+                "variants": {
+                    "description": "Variants are the weighted destinations of a split link",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GetVariantResponse"
+                    }
+                }
+            }
+        },
+        "model.GetURLResponse": {
+            "type": "object",
+            "properties": {
+                "active_from": {
+                    "type": "string"
+                },
+                "active_until": {
+                    "type": "string"
+                },
+                "campaign_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "Domain is the custom domain the link is served on",
+                    "type": "string"
+                },
+                "fallback_url": {
+                    "type": "string"
+                },
+                "forward_path": {
+                    "type": "boolean"
+                },
+                "forward_query": {
+                    "type": "boolean"
+                },
+                "info_url": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "password_protected": {
+                    "description": "PasswordProtected tells the visitor has to enter a password before being redirected",
+                    "type": "boolean"
+                },
+                "preview_required": {
+                    "description": "PreviewRequired tells the link opens the preview page instead of redirecting",
+                    "type": "boolean"
+                },
+                "qr_url": {
+                    "type": "string"
+                },
+                "query_conflict": {
+                    "type": "string"
+                },
+                "redirect_type": {
+                    "type": "integer"
+                },
+                "schedule": {
+                    "$ref": "#/definitions/model.URLSchedule"
+                },
+                "short_link": {
+                    "description": "ShortLink, QRURL and InfoURL are absolute urls so clients don't have to know the public base url",
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                },
+                "single_use": {
+                    "description": "SingleUse tells the link stops working after its first visit",
+                    "type": "boolean"
+                },
+                "sticky_variant": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variant_id": {
+                    "description": "VariantID is the variant served by this resolution",
+                    "type": "integer"
+                },
+                "variants": {
+                    "description": "Variants are the weighted destinations of a split link",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GetVariantResponse"
+                    }
+                }
+            }
+        },
+        "model.GetURLStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "password_protected": {
+                    "description": "PasswordProtected, SingleUse and Active tell if the destinations of the variants may be shown",
+                    "type": "boolean"
+                },
+                "short_url": {
+                    "type": "string"
+                },
+                "single_use": {
+                    "type": "boolean"
+                },
+                "sticky_variant": {
+                    "type": "boolean"
+                },
+                "total_clicks": {
+                    "type": "integer"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.VariantStats"
+                    }
+                }
+            }
+        },
+        "model.GetVariantResponse": {
+            "type": "object",
+            "properties": {
+                "destination_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.GetWebhookDeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                }
+            }
+        },
+        "model.GetWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "click_thresholds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Secret signs the payloads, it is only returned when the webhook is created",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ListURLResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.GetURLInfoResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "offset": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ScheduleWindow": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "Days are mon, tue, wed, thu, fri, sat or sun, every day when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "end": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SetURLCampaignRequest": {
+            "type": "object",
+            "properties": {
+                "campaign_id": {
+                    "description": "CampaignID moves the link to the campaign, 0 removes it from its campaign",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.SetURLTagsRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "description": "Tags replace the tags of the link, missing tags are created",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.URLSchedule": {
+            "type": "object",
+            "properties": {
+                "time_zone": {
+                    "description": "TimeZone is an IANA time zone name, UTC when empty",
+                    "type": "string"
+                },
+                "windows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ScheduleWindow"
+                    }
+                }
+            }
+        },
+        "model.UTMParams": {
+            "type": "object",
+            "properties": {
+                "campaign": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "medium": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "term": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpsertCampaignRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.UpsertRuleRequest": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "description": "Country is an ISO 3166-1 alpha-2 code such as ID or US",
+                    "type": "string"
+                },
+                "destination_url": {
+                    "type": "string"
+                },
+                "device_type": {
+                    "description": "DeviceType is desktop, mobile, tablet or bot",
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Language is a language tag such as en or pt-br matched against Accept-Language",
+                    "type": "string"
+                },
+                "os": {
+                    "description": "OS is ios, android, windows, macos, linux or chromeos",
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.VariantRequest": {
+            "type": "object",
+            "properties": {
+                "destination_url": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "Weight is the share of clicks relative to the other variants",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.VariantStats": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "destination_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer"
+                }
+            }
+        },
+        "transport.body": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "data": {},
+                "message": {
                     "type": "string"
                 }
             }
@@ -7,6 +7,8 @@ import (
 
 	model "github.com/muhammadheryan/url-shortner-base62/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // URLRepository is an autogenerated mock type for the URLRepository type
@@ -14,6 +16,34 @@ type URLRepository struct {
 	mock.Mock
 }
 
+// Consume provides a mock function with given fields: ctx, id
+func (_m *URLRepository) Consume(ctx context.Context, id uint64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Consume")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, req
 func (_m *URLRepository) Create(ctx context.Context, req *model.URLEntity) (*model.URLEntity, error) {
 	ret := _m.Called(ctx, req)
@@ -44,6 +74,54 @@ func (_m *URLRepository) Create(ctx context.Context, req *model.URLEntity) (*mod
 	return r0, r1
 }
 
+// CreateBatch provides a mock function with given fields: ctx, req
+func (_m *URLRepository) CreateBatch(ctx context.Context, req []*model.URLEntity) ([]*model.URLEntity, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBatch")
+	}
+
+	var r0 []*model.URLEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.URLEntity) ([]*model.URLEntity, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.URLEntity) []*model.URLEntity); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.URLEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*model.URLEntity) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *URLRepository) Delete(ctx context.Context, id uint64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: ctx, filter
 func (_m *URLRepository) Get(ctx context.Context, filter *model.URLFilter) (*model.URLEntity, error) {
 	ret := _m.Called(ctx, filter)
@@ -74,6 +152,130 @@ func (_m *URLRepository) Get(ctx context.Context, filter *model.URLFilter) (*mod
 	return r0, r1
 }
 
+// List provides a mock function with given fields: ctx, filter
+func (_m *URLRepository) List(ctx context.Context, filter *model.URLFilter) ([]*model.URLEntity, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*model.URLEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.URLFilter) ([]*model.URLEntity, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.URLFilter) []*model.URLEntity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.URLEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.URLFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListExpired provides a mock function with given fields: ctx, now, limit
+func (_m *URLRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.URLEntity, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListExpired")
+	}
+
+	var r0 []*model.URLEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]*model.URLEntity, error)); ok {
+		return rf(ctx, now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []*model.URLEntity); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.URLEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkExpiryNotified provides a mock function with given fields: ctx, id
+func (_m *URLRepository) MarkExpiryNotified(ctx context.Context, id uint64) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkExpiryNotified")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordClick provides a mock function with given fields: ctx, click, writeEvent
+func (_m *URLRepository) RecordClick(ctx context.Context, click *model.ClickEntity, writeEvent bool) error {
+	ret := _m.Called(ctx, click, writeEvent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordClick")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ClickEntity, bool) error); ok {
+		r0 = rf(ctx, click, writeEvent)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetCampaign provides a mock function with given fields: ctx, id, campaignID
+func (_m *URLRepository) SetCampaign(ctx context.Context, id uint64, campaignID uint64) error {
+	ret := _m.Called(ctx, id, campaignID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCampaign")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, id, campaignID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, req
 func (_m *URLRepository) Update(ctx context.Context, req *model.URLEntity) (*model.URLEntity, error) {
 	ret := _m.Called(ctx, req)
@@ -104,6 +306,36 @@ func (_m *URLRepository) Update(ctx context.Context, req *model.URLEntity) (*mod
 	return r0, r1
 }
 
+// UpdateBatch provides a mock function with given fields: ctx, req
+func (_m *URLRepository) UpdateBatch(ctx context.Context, req []*model.URLEntity) ([]*model.URLEntity, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBatch")
+	}
+
+	var r0 []*model.URLEntity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.URLEntity) ([]*model.URLEntity, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.URLEntity) []*model.URLEntity); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.URLEntity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*model.URLEntity) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewURLRepository creates a new instance of URLRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewURLRepository(t interface {
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// CampaignEntity represents the campaign table entity, the folder a link belongs to
+type CampaignEntity struct {
+	ID          uint64     `db:"id" json:"id"`
+	Name        string     `db:"name" json:"name"`
+	Description string     `db:"description" json:"description"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type CampaignFilter struct {
+	ID   uint64
+	Name string
+}
+
+// CampaignStats aggregates the links of a campaign
+type CampaignStats struct {
+	Links       uint64 `db:"links"`
+	TotalClicks uint64 `db:"total_clicks"`
+}
+
+type UpsertCampaignRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type GetCampaignResponse struct {
+	ID          uint64     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+type GetCampaignStatsResponse struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Links       uint64 `json:"links"`
+	TotalClicks uint64 `json:"total_clicks"`
+}
+
+type SetURLCampaignRequest struct {
+	// CampaignID moves the link to the campaign, 0 removes it from its campaign
+	CampaignID uint64 `json:"campaign_id"`
+}
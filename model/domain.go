@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// DomainEntity represents the domain table entity, a short domain registered by a tenant.
+// Links are only served on it once the tenant proved ownership by serving VerificationToken.
+type DomainEntity struct {
+	ID                uint64     `db:"id" json:"id"`
+	UserID            uint64     `db:"user_id" json:"user_id"`
+	Host              string     `db:"host" json:"host"`
+	VerificationToken string     `db:"verification_token" json:"-"`
+	VerifiedAt        *time.Time `db:"verified_at" json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt         *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type DomainFilter struct {
+	ID   uint64
+	Host string
+}
+
+type CreateDomainRequest struct {
+	// Host is the short domain such as go.acme.com
+	Host string `json:"host"`
+}
+
+type GetDomainResponse struct {
+	ID       uint64 `json:"id"`
+	Host     string `json:"host"`
+	Verified bool   `json:"verified"`
+	// VerificationToken has to be served as plain text at VerificationURL before verifying
+	VerificationToken string     `json:"verification_token,omitempty"`
+	VerificationURL   string     `json:"verification_url,omitempty"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
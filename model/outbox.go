@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// OutboxEntity represents the outbox table entity, a domain event written with the
+// change it describes and waiting to be handed to the publishers
+type OutboxEntity struct {
+	ID      uint64 `db:"id" json:"id"`
+	EventID string `db:"event_id" json:"event_id"`
+	Event   string `db:"event" json:"event"`
+	URLID   uint64 `db:"url_id" json:"url_id"`
+	Payload string `db:"payload" json:"payload"`
+	// Attempts counts the failed publications, LastError is the error of the last one
+	Attempts  int       `db:"attempts" json:"attempts"`
+	LastError string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// EventPayload is the JSON body of an event, posted to the webhooks and sent to the message brokers
+type EventPayload struct {
+	ID        string         `json:"id"`
+	Event     string         `json:"event"`
+	CreatedAt time.Time      `json:"created_at"`
+	Data      *LinkEventData `json:"data"`
+}
+
+// LinkEventData describes the link an event is about
+type LinkEventData struct {
+	ShortURL    string   `json:"short_url"`
+	ShortLink   string   `json:"short_link,omitempty"`
+	OriginalURL string   `json:"original_url"`
+	Domain      string   `json:"domain,omitempty"`
+	CampaignID  uint64   `json:"campaign_id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	ClickCount  uint64   `json:"click_count"`
+	// VariantID is the variant served by a click
+	VariantID uint64 `json:"variant_id,omitempty"`
+	// Threshold is the click count reached by a link.click_threshold event
+	Threshold uint64 `json:"threshold,omitempty"`
+	// Reason tells why a link expired, consumed or active_until
+	Reason string `json:"reason,omitempty"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// RuleEntity represents the url_rule table entity, a rule sends visitors matching
+// all of its non empty conditions to DestinationURL
+type RuleEntity struct {
+	ID             uint64     `db:"id" json:"id"`
+	URLID          uint64     `db:"url_id" json:"url_id"`
+	Priority       int        `db:"priority" json:"priority"`
+	Country        string     `db:"country" json:"country"`
+	DeviceType     string     `db:"device_type" json:"device_type"`
+	OS             string     `db:"os" json:"os"`
+	Language       string     `db:"language" json:"language"`
+	DestinationURL string     `db:"destination_url" json:"destination_url"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type RuleFilter struct {
+	ID    uint64
+	URLID uint64
+}
+
+type GetRuleResponse struct {
+	ID             uint64     `json:"id"`
+	Priority       int        `json:"priority"`
+	Country        string     `json:"country,omitempty"`
+	DeviceType     string     `json:"device_type,omitempty"`
+	OS             string     `json:"os,omitempty"`
+	Language       string     `json:"language,omitempty"`
+	DestinationURL string     `json:"destination_url"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpsertRuleRequest creates or replaces a rule, rules are evaluated by ascending priority
+type UpsertRuleRequest struct {
+	Priority int `json:"priority"`
+	// Country is an ISO 3166-1 alpha-2 code such as ID or US
+	Country string `json:"country,omitempty"`
+	// DeviceType is desktop, mobile, tablet or bot
+	DeviceType string `json:"device_type,omitempty"`
+	// OS is ios, android, windows, macos, linux or chromeos
+	OS string `json:"os,omitempty"`
+	// Language is a language tag such as en or pt-br matched against Accept-Language
+	Language       string `json:"language,omitempty"`
+	DestinationURL string `json:"destination_url"`
+}
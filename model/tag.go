@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// TagEntity represents the tag table entity, links and tags are linked through url_tag
+type TagEntity struct {
+	ID        uint64    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type TagFilter struct {
+	ID   uint64
+	Name string
+}
+
+// URLTag is one tag of a link
+type URLTag struct {
+	URLID uint64 `db:"url_id"`
+	Name  string `db:"name"`
+}
+
+type CreateTagRequest struct {
+	Name string `json:"name"`
+}
+
+type GetTagResponse struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SetURLTagsRequest struct {
+	// Tags replace the tags of the link, missing tags are created
+	Tags []string `json:"tags"`
+}
@@ -1,29 +1,253 @@
 package model
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
 
 // URL represents the url table entity
 type URLEntity struct {
-	ID          uint64     `db:"id" json:"id"`
-	UserID      uint64     `db:"user_id" json:"user_id"`
-	ShortURL    string     `db:"short_url" json:"short_url"`
-	OriginalURL string     `db:"original_url" json:"original_url"`
+	ID     uint64 `db:"id" json:"id"`
+	UserID uint64 `db:"user_id" json:"user_id"`
+	// DomainID is the custom domain the link is served on, 0 for the default domain
+	DomainID uint64 `db:"domain_id" json:"domain_id"`
+	// CampaignID is the campaign the link belongs to, 0 for none
+	CampaignID   uint64       `db:"campaign_id" json:"campaign_id"`
+	ShortURL     string       `db:"short_url" json:"short_url"`
+	OriginalURL  string       `db:"original_url" json:"original_url"`
+	RedirectType int          `db:"redirect_type" json:"redirect_type"`
+	Status       string       `db:"status" json:"status"`
+	ClickCount   uint64       `db:"click_count" json:"click_count"`
+	PasswordHash string       `db:"password_hash" json:"-"`
+	SingleUse    bool         `db:"single_use" json:"single_use"`
+	ConsumedAt   *time.Time   `db:"consumed_at" json:"consumed_at,omitempty"`
+	ActiveFrom   *time.Time   `db:"active_from" json:"active_from,omitempty"`
+	ActiveUntil  *time.Time   `db:"active_until" json:"active_until,omitempty"`
+	Schedule     *URLSchedule `db:"schedule" json:"schedule,omitempty"`
+	FallbackURL  string       `db:"fallback_url" json:"fallback_url,omitempty"`
+	// StickyVariant keeps serving a visitor the same variant of a split link
+	StickyVariant bool `db:"sticky_variant" json:"sticky_variant"`
+	// ForwardQuery appends the query of the visit to the destination, QueryConflict
+	// tells what to do when a parameter is already on the destination
+	ForwardQuery  bool   `db:"forward_query" json:"forward_query"`
+	QueryConflict string `db:"query_conflict" json:"query_conflict,omitempty"`
+	// ForwardPath appends the path after the short url to the destination
+	ForwardPath bool       `db:"forward_path" json:"forward_path"`
 	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at,omitempty"`
 }
 
+// URLSchedule restricts a link to recurring weekly windows in a time zone
+type URLSchedule struct {
+	// TimeZone is an IANA time zone name, UTC when empty
+	TimeZone string           `json:"time_zone,omitempty"`
+	Windows  []ScheduleWindow `json:"windows"`
+}
+
+// ScheduleWindow is open on the given days between Start and End (HH:MM),
+// an End before Start spans midnight
+type ScheduleWindow struct {
+	// Days are mon, tue, wed, thu, fri, sat or sun, every day when empty
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// Scan implements sql.Scanner for the JSON schedule column
+func (s *URLSchedule) Scan(src any) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, s)
+	case string:
+		return json.Unmarshal([]byte(value), s)
+	default:
+		return errors.New("unsupported schedule value")
+	}
+}
+
+// Value implements driver.Valuer for the JSON schedule column
+func (s URLSchedule) Value() (driver.Value, error) {
+	value, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
 type URLFilter struct {
 	ID       uint64
 	ShortURL string
+	// DomainID restricts the lookup to one domain, any domain when nil
+	DomainID *uint64
+	// CampaignID and Tag narrow down List, Limit and Offset page through it
+	CampaignID uint64
+	Tag        string
+	Limit      int
+	Offset     int
 }
 
 type GetURLResponse struct {
-	ShortURL    string     `json:"short_url"`
-	OriginalURL string     `json:"original_url"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	ShortURL string `json:"short_url"`
+	// Domain is the custom domain the link is served on
+	Domain     string   `json:"domain,omitempty"`
+	CampaignID uint64   `json:"campaign_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// ShortLink, QRURL and InfoURL are absolute urls so clients don't have to know the public base url
+	ShortLink    string `json:"short_link,omitempty"`
+	QRURL        string `json:"qr_url,omitempty"`
+	InfoURL      string `json:"info_url,omitempty"`
+	OriginalURL  string `json:"original_url"`
+	RedirectType int    `json:"redirect_type"`
+	// PreviewRequired tells the link opens the preview page instead of redirecting
+	PreviewRequired bool `json:"preview_required,omitempty"`
+	// PasswordProtected tells the visitor has to enter a password before being redirected
+	PasswordProtected bool `json:"password_protected,omitempty"`
+	// SingleUse tells the link stops working after its first visit
+	SingleUse   bool         `json:"single_use,omitempty"`
+	ActiveFrom  *time.Time   `json:"active_from,omitempty"`
+	ActiveUntil *time.Time   `json:"active_until,omitempty"`
+	Schedule    *URLSchedule `json:"schedule,omitempty"`
+	FallbackURL string       `json:"fallback_url,omitempty"`
+	// Variants are the weighted destinations of a split link
+	Variants      []GetVariantResponse `json:"variants,omitempty"`
+	StickyVariant bool                 `json:"sticky_variant,omitempty"`
+	ForwardQuery  bool                 `json:"forward_query,omitempty"`
+	QueryConflict string               `json:"query_conflict,omitempty"`
+	ForwardPath   bool                 `json:"forward_path,omitempty"`
+	// VariantID is the variant served by this resolution
+	VariantID uint64     `json:"variant_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// GetURLInfoResponse is the link metadata returned instead of a redirect
+type GetURLInfoResponse struct {
+	GetURLResponse
+	UserID     uint64     `json:"user_id"`
+	Status     string     `json:"status"`
+	ClickCount uint64     `json:"click_count"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	// Active tells the link is currently inside its activation window
+	Active bool `json:"active"`
+	// CheckedAt is the time of the application clock Active was evaluated at
+	CheckedAt time.Time `json:"-"`
+}
+
+// GetURLPreviewResponse is rendered on the preview page of a link
+type GetURLPreviewResponse struct {
+	GetURLInfoResponse
+	Title string `json:"title"`
 }
 
 type CreateURLShortnerRequest struct {
 	OriginalURL string `json:"original_url"`
+	// Domain is a verified custom domain to serve the link on, the default domain when empty
+	Domain string `json:"domain,omitempty"`
+	// CampaignID puts the link in a campaign, Tags are created when missing
+	CampaignID uint64   `json:"campaign_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// RedirectType is one of 301, 302, 307 or 308, server default is used when empty
+	RedirectType int `json:"redirect_type,omitempty"`
+	// Password protects the link, visitors have to enter it before being redirected
+	Password string `json:"password,omitempty"`
+	// SingleUse makes the link stop working after its first visit
+	SingleUse bool `json:"single_use,omitempty"`
+	// ActiveFrom and ActiveUntil bound when the link redirects
+	ActiveFrom  *time.Time `json:"active_from,omitempty"`
+	ActiveUntil *time.Time `json:"active_until,omitempty"`
+	// Schedule further restricts the link to recurring weekly windows
+	Schedule *URLSchedule `json:"schedule,omitempty"`
+	// FallbackURL is used outside the activation window, a not available page is shown when empty
+	FallbackURL string `json:"fallback_url,omitempty"`
+	// Variants split the clicks between weighted destinations, OriginalURL is
+	// only used when they can't be loaded
+	Variants []VariantRequest `json:"variants,omitempty"`
+	// StickyVariant keeps serving a visitor the same variant
+	StickyVariant bool `json:"sticky_variant,omitempty"`
+	// UTM parameters are merged into the destination and the variants
+	UTM *UTMParams `json:"utm,omitempty"`
+	// ForwardQuery appends the query of every visit to the destination
+	ForwardQuery bool `json:"forward_query,omitempty"`
+	// QueryConflict is keep, override or append, server default is used when empty
+	QueryConflict string `json:"query_conflict,omitempty"`
+	// ForwardPath makes /url/{shortURL}/rest/of/path go to the destination followed by rest/of/path,
+	// except the paths info, qr, stats and rules served by the API
+	ForwardPath bool `json:"forward_path,omitempty"`
+}
+
+// UTMParams are the campaign tracking parameters added to a destination
+type UTMParams struct {
+	Source   string `json:"source,omitempty"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// ResolveURLRequest holds what is known about a visit of a short link
+type ResolveURLRequest struct {
+	ShortURL string
+	// Host is the domain the visit was sent to, links are resolved on the matching custom domain
+	Host string
+	// Unlocked tells the visitor already entered the password of the link
+	Unlocked bool
+	// ClientIP, UserAgent and AcceptLanguage are matched against the targeting rules
+	ClientIP       string
+	UserAgent      string
+	AcceptLanguage string
+	// VariantID is the variant previously served to the visitor of a sticky link
+	VariantID uint64
+	// Query is the query string of the visit, forwarded when the link asks for it
+	Query url.Values
+	// Path is what follows the short url, only links forwarding the path accept one
+	Path string
+}
+
+// ListURLRequest filters and pages the links, Limit is capped by the server
+type ListURLRequest struct {
+	CampaignID uint64
+	Tag        string
+	Limit      int
+	Offset     int
+}
+
+type ListURLResponse struct {
+	Items  []*GetURLInfoResponse `json:"items"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+}
+
+type UnlockURLRequest struct {
+	ShortURL string
+	// Host picks the custom domain of the link like on a visit
+	Host     string
+	Password string
+	// ClientKey identifies the visitor for rate limiting wrong passwords
+	ClientKey string
+}
+
+type CreateURLShortnerBatchRequest struct {
+	Items []CreateURLShortnerRequest `json:"items"`
+}
+
+// BatchItemError describes why one item of a batch was not created
+type BatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type CreateURLShortnerBatchItem struct {
+	Index int `json:"index"`
+	*GetURLResponse
+	Error *BatchItemError `json:"error,omitempty"`
+}
+
+type CreateURLShortnerBatchResponse struct {
+	Total   int                          `json:"total"`
+	Created int                          `json:"created"`
+	Failed  int                          `json:"failed"`
+	Items   []CreateURLShortnerBatchItem `json:"items"`
 }
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// VariantEntity represents the url_variant table entity, one of the weighted
+// destinations a split link chooses from on every click
+type VariantEntity struct {
+	ID             uint64     `db:"id" json:"id"`
+	URLID          uint64     `db:"url_id" json:"url_id"`
+	DestinationURL string     `db:"destination_url" json:"destination_url"`
+	Weight         int        `db:"weight" json:"weight"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      *time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type VariantRequest struct {
+	DestinationURL string `json:"destination_url"`
+	// Weight is the share of clicks relative to the other variants
+	Weight int `json:"weight"`
+}
+
+type GetVariantResponse struct {
+	ID             uint64 `json:"id"`
+	DestinationURL string `json:"destination_url"`
+	Weight         int    `json:"weight"`
+}
+
+// ClickEntity represents the click table entity, one row per resolved visit
+type ClickEntity struct {
+	ID    uint64 `db:"id" json:"id"`
+	URLID uint64 `db:"url_id" json:"url_id"`
+	// VariantID is the variant served, nil when the link has no variants or a rule matched
+	VariantID *uint64   `db:"variant_id" json:"variant_id,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// VariantClickCount is the number of clicks recorded for one variant
+type VariantClickCount struct {
+	VariantID uint64 `db:"variant_id"`
+	Clicks    uint64 `db:"clicks"`
+}
+
+type VariantStats struct {
+	GetVariantResponse
+	Clicks uint64 `json:"clicks"`
+}
+
+// GetURLStatsResponse reports the clicks of a link and of each of its variants
+type GetURLStatsResponse struct {
+	ShortURL      string `json:"short_url"`
+	TotalClicks   uint64 `json:"total_clicks"`
+	StickyVariant bool   `json:"sticky_variant"`
+	// PasswordProtected, SingleUse and Active tell if the destinations of the variants may be shown
+	PasswordProtected bool           `json:"password_protected,omitempty"`
+	SingleUse         bool           `json:"single_use,omitempty"`
+	Active            bool           `json:"active"`
+	Variants          []VariantStats `json:"variants"`
+}
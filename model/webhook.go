@@ -0,0 +1,120 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// WebhookEntity represents the webhook table entity, an endpoint receiving link events
+type WebhookEntity struct {
+	ID     uint64 `db:"id" json:"id"`
+	URL    string `db:"url" json:"url"`
+	Secret string `db:"secret" json:"-"`
+	// Events are the subscribed events, ClickThresholds the click counts sent as link.click_threshold
+	Events          JSONList[string] `db:"events" json:"events"`
+	ClickThresholds JSONList[uint64] `db:"click_thresholds" json:"click_thresholds,omitempty"`
+	CreatedAt       time.Time        `db:"created_at" json:"created_at"`
+}
+
+// Subscribes checks if the webhook receives the event
+func (w *WebhookEntity) Subscribes(event string) bool {
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONList is a list stored in a JSON column
+type JSONList[T any] []T
+
+// Scan implements sql.Scanner for the JSON list column
+func (l *JSONList[T]) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(value, l)
+	case string:
+		return json.Unmarshal([]byte(value), l)
+	default:
+		return errors.New("unsupported list value")
+	}
+}
+
+// Value implements driver.Valuer for the JSON list column
+func (l JSONList[T]) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	value, err := json.Marshal([]T(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(value), nil
+}
+
+// WebhookDeliveryEntity represents the webhook_delivery table entity, an event waiting to be sent
+type WebhookDeliveryEntity struct {
+	ID        uint64 `db:"id" json:"id"`
+	WebhookID uint64 `db:"webhook_id" json:"webhook_id"`
+	// EventID is the same on every attempt so receivers can drop duplicates
+	EventID       string    `db:"event_id" json:"event_id"`
+	Event         string    `db:"event" json:"event"`
+	Payload       string    `db:"payload" json:"-"`
+	Attempts      int       `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	LastError     string    `db:"last_error" json:"last_error,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// WebhookDeadLetterEntity represents the webhook_dead_letter table entity, an event
+// given up on after the last attempt which can be replayed
+type WebhookDeadLetterEntity struct {
+	ID        uint64    `db:"id" json:"id"`
+	WebhookID uint64    `db:"webhook_id" json:"webhook_id"`
+	EventID   string    `db:"event_id" json:"event_id"`
+	Event     string    `db:"event" json:"event"`
+	Payload   string    `db:"payload" json:"-"`
+	Attempts  int       `db:"attempts" json:"attempts"`
+	LastError string    `db:"last_error" json:"last_error"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	FailedAt  time.Time `db:"failed_at" json:"failed_at"`
+}
+
+type WebhookDeadLetterFilter struct {
+	ID        uint64
+	WebhookID uint64
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// ClickThresholds are the click counts announced by link.click_threshold
+	ClickThresholds []uint64 `json:"click_thresholds,omitempty"`
+}
+
+type GetWebhookResponse struct {
+	ID              uint64   `json:"id"`
+	URL             string   `json:"url"`
+	Events          []string `json:"events"`
+	ClickThresholds []uint64 `json:"click_thresholds,omitempty"`
+	// Secret signs the payloads, it is only returned when the webhook is created
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetWebhookDeadLetterResponse struct {
+	ID        uint64          `json:"id"`
+	EventID   string          `json:"event_id"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error"`
+	CreatedAt time.Time       `json:"created_at"`
+	FailedAt  time.Time       `json:"failed_at"`
+}
@@ -0,0 +1,292 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: url/v1/url.proto
+
+package urlv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	URLService_CreateShortURL_FullMethodName = "/url.v1.URLService/CreateShortURL"
+	URLService_GetURL_FullMethodName         = "/url.v1.URLService/GetURL"
+	URLService_ListURLs_FullMethodName       = "/url.v1.URLService/ListURLs"
+	URLService_DeleteURL_FullMethodName      = "/url.v1.URLService/DeleteURL"
+	URLService_Resolve_FullMethodName        = "/url.v1.URLService/Resolve"
+)
+
+// URLServiceClient is the client API for URLService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// URLService serves the links to internal services, sharing the application layer
+// and the error codes of the REST API. Failed calls carry the error code of the REST
+// API as the reason of a google.rpc.ErrorInfo detail.
+type URLServiceClient interface {
+	// CreateShortURL shortens a url
+	CreateShortURL(ctx context.Context, in *CreateShortURLRequest, opts ...grpc.CallOption) (*URL, error)
+	// GetURL returns a link without counting a click, the destination of single use,
+	// inactive and password protected links is left empty
+	GetURL(ctx context.Context, in *GetURLRequest, opts ...grpc.CallOption) (*URLInfo, error)
+	// ListURLs pages the links, newest first
+	ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (*ListURLsResponse, error)
+	// DeleteURL removes a link with its rules, variants, clicks and tags
+	DeleteURL(ctx context.Context, in *DeleteURLRequest, opts ...grpc.CallOption) (*DeleteURLResponse, error)
+	// Resolve looks links up like visits of the redirect endpoint, counting a click for each.
+	// Every request gets one response in the same order, a failed lookup is reported in its
+	// response without ending the stream.
+	Resolve(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ResolveRequest, ResolveResponse], error)
+}
+
+type uRLServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewURLServiceClient(cc grpc.ClientConnInterface) URLServiceClient {
+	return &uRLServiceClient{cc}
+}
+
+func (c *uRLServiceClient) CreateShortURL(ctx context.Context, in *CreateShortURLRequest, opts ...grpc.CallOption) (*URL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(URL)
+	err := c.cc.Invoke(ctx, URLService_CreateShortURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) GetURL(ctx context.Context, in *GetURLRequest, opts ...grpc.CallOption) (*URLInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(URLInfo)
+	err := c.cc.Invoke(ctx, URLService_GetURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) ListURLs(ctx context.Context, in *ListURLsRequest, opts ...grpc.CallOption) (*ListURLsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListURLsResponse)
+	err := c.cc.Invoke(ctx, URLService_ListURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) DeleteURL(ctx context.Context, in *DeleteURLRequest, opts ...grpc.CallOption) (*DeleteURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteURLResponse)
+	err := c.cc.Invoke(ctx, URLService_DeleteURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) Resolve(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ResolveRequest, ResolveResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &URLService_ServiceDesc.Streams[0], URLService_Resolve_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ResolveRequest, ResolveResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLService_ResolveClient = grpc.BidiStreamingClient[ResolveRequest, ResolveResponse]
+
+// URLServiceServer is the server API for URLService service.
+// All implementations must embed UnimplementedURLServiceServer
+// for forward compatibility.
+//
+// URLService serves the links to internal services, sharing the application layer
+// and the error codes of the REST API. Failed calls carry the error code of the REST
+// API as the reason of a google.rpc.ErrorInfo detail.
+type URLServiceServer interface {
+	// CreateShortURL shortens a url
+	CreateShortURL(context.Context, *CreateShortURLRequest) (*URL, error)
+	// GetURL returns a link without counting a click, the destination of single use,
+	// inactive and password protected links is left empty
+	GetURL(context.Context, *GetURLRequest) (*URLInfo, error)
+	// ListURLs pages the links, newest first
+	ListURLs(context.Context, *ListURLsRequest) (*ListURLsResponse, error)
+	// DeleteURL removes a link with its rules, variants, clicks and tags
+	DeleteURL(context.Context, *DeleteURLRequest) (*DeleteURLResponse, error)
+	// Resolve looks links up like visits of the redirect endpoint, counting a click for each.
+	// Every request gets one response in the same order, a failed lookup is reported in its
+	// response without ending the stream.
+	Resolve(grpc.BidiStreamingServer[ResolveRequest, ResolveResponse]) error
+	mustEmbedUnimplementedURLServiceServer()
+}
+
+// UnimplementedURLServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedURLServiceServer struct{}
+
+func (UnimplementedURLServiceServer) CreateShortURL(context.Context, *CreateShortURLRequest) (*URL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShortURL not implemented")
+}
+func (UnimplementedURLServiceServer) GetURL(context.Context, *GetURLRequest) (*URLInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURL not implemented")
+}
+func (UnimplementedURLServiceServer) ListURLs(context.Context, *ListURLsRequest) (*ListURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListURLs not implemented")
+}
+func (UnimplementedURLServiceServer) DeleteURL(context.Context, *DeleteURLRequest) (*DeleteURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteURL not implemented")
+}
+func (UnimplementedURLServiceServer) Resolve(grpc.BidiStreamingServer[ResolveRequest, ResolveResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedURLServiceServer) mustEmbedUnimplementedURLServiceServer() {}
+func (UnimplementedURLServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeURLServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to URLServiceServer will
+// result in compilation errors.
+type UnsafeURLServiceServer interface {
+	mustEmbedUnimplementedURLServiceServer()
+}
+
+func RegisterURLServiceServer(s grpc.ServiceRegistrar, srv URLServiceServer) {
+	// If the following call pancis, it indicates UnimplementedURLServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&URLService_ServiceDesc, srv)
+}
+
+func _URLService_CreateShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).CreateShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_CreateShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).CreateShortURL(ctx, req.(*CreateShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_GetURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).GetURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_GetURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).GetURL(ctx, req.(*GetURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_ListURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).ListURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_ListURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).ListURLs(ctx, req.(*ListURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_DeleteURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).DeleteURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_DeleteURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).DeleteURL(ctx, req.(*DeleteURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_Resolve_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(URLServiceServer).Resolve(&grpc.GenericServerStream[ResolveRequest, ResolveResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLService_ResolveServer = grpc.BidiStreamingServer[ResolveRequest, ResolveResponse]
+
+// URLService_ServiceDesc is the grpc.ServiceDesc for URLService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var URLService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "url.v1.URLService",
+	HandlerType: (*URLServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateShortURL",
+			Handler:    _URLService_CreateShortURL_Handler,
+		},
+		{
+			MethodName: "GetURL",
+			Handler:    _URLService_GetURL_Handler,
+		},
+		{
+			MethodName: "ListURLs",
+			Handler:    _URLService_ListURLs_Handler,
+		},
+		{
+			MethodName: "DeleteURL",
+			Handler:    _URLService_DeleteURL_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Resolve",
+			Handler:       _URLService_Resolve_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "url/v1/url.proto",
+}
@@ -0,0 +1,124 @@
+package campaign
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/outbox"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+type CampaignRepository interface {
+	Create(ctx context.Context, req *model.CampaignEntity) (*model.CampaignEntity, error)
+	Update(ctx context.Context, req *model.CampaignEntity) (*model.CampaignEntity, error)
+	Delete(ctx context.Context, id uint64) error
+	Get(ctx context.Context, filter *model.CampaignFilter) (*model.CampaignEntity, error)
+	List(ctx context.Context) ([]*model.CampaignEntity, error)
+	Stats(ctx context.Context, id uint64) (*model.CampaignStats, error)
+}
+
+func NewCampaignRepository(conn *sqlx.DB) CampaignRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertCampaignQuery   = `INSERT INTO campaign (name, description, created_at) VALUES (?, ?, NOW())`
+	updateCampaignQuery   = `UPDATE campaign SET name = ?, description = ?, updated_at = NOW() WHERE id = ?`
+	deleteCampaignQuery   = `DELETE FROM campaign WHERE id = ?`
+	detachCampaignQuery   = `UPDATE url SET campaign_id = 0, updated_at = NOW() WHERE campaign_id = ?`
+	listCampaignURLsQuery = `SELECT id FROM url WHERE campaign_id = ?`
+	getCampaignBase       = `SELECT id, name, description, created_at, updated_at FROM campaign WHERE true`
+	listCampaignQuery     = getCampaignBase + ` ORDER BY name`
+	campaignStatsQuery    = `SELECT COUNT(*) AS links, COALESCE(SUM(click_count), 0) AS total_clicks FROM url WHERE campaign_id = ?`
+)
+
+func (s *SQL) Create(ctx context.Context, data *model.CampaignEntity) (*model.CampaignEntity, error) {
+	id, err := sqldb.InsertID(ctx, s.conn, insertCampaignQuery, data.Name, data.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ID = id
+
+	return data, nil
+}
+
+func (s *SQL) Update(ctx context.Context, data *model.CampaignEntity) (*model.CampaignEntity, error) {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(updateCampaignQuery), data.Name, data.Description, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Delete removes the campaign, its links are kept outside of any campaign and announced as updated
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var urlIDs []uint64
+	if err := tx.SelectContext(ctx, &urlIDs, tx.Rebind(listCampaignURLsQuery), id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(detachCampaignQuery), id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteCampaignQuery), id); err != nil {
+		return err
+	}
+	if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkUpdated, urlIDs, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQL) Get(ctx context.Context, filter *model.CampaignFilter) (*model.CampaignEntity, error) {
+	query := getCampaignBase
+	args := make([]any, 0, 2)
+
+	if filter.ID != 0 {
+		query += " AND id = ?"
+		args = append(args, filter.ID)
+	}
+	if filter.Name != "" {
+		query += " AND name = ?"
+		args = append(args, filter.Name)
+	}
+
+	var entity model.CampaignEntity
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(query), args...).StructScan(&entity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *SQL) List(ctx context.Context) ([]*model.CampaignEntity, error) {
+	var entities []*model.CampaignEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listCampaignQuery)); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (s *SQL) Stats(ctx context.Context, id uint64) (*model.CampaignStats, error) {
+	var stats model.CampaignStats
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(campaignStatsQuery), id).StructScan(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,115 @@
+package campaign
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Links is the part of the memory link repository keeping the campaign of every link
+type Links interface {
+	DetachCampaign(ctx context.Context, campaignID uint64) error
+	CampaignStats(ctx context.Context, campaignID uint64) (*model.CampaignStats, error)
+}
+
+// Memory keeps the campaigns in memory for running the service without a database,
+// no events are written since there is no outbox
+type Memory struct {
+	mu        sync.RWMutex
+	nextID    uint64
+	campaigns map[uint64]*model.CampaignEntity
+	links     Links
+}
+
+// NewMemoryCampaignRepository keeps the campaigns in memory, links holds the links of the campaigns
+func NewMemoryCampaignRepository(links Links) *Memory {
+	return &Memory{
+		campaigns: make(map[uint64]*model.CampaignEntity),
+		links:     links,
+	}
+}
+
+func (m *Memory) Create(ctx context.Context, data *model.CampaignEntity) (*model.CampaignEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	data.ID = m.nextID
+
+	entity := *data
+	entity.CreatedAt = time.Now()
+	entity.UpdatedAt = nil
+	m.campaigns[entity.ID] = &entity
+
+	return data, nil
+}
+
+func (m *Memory) Update(ctx context.Context, data *model.CampaignEntity) (*model.CampaignEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entity, ok := m.campaigns[data.ID]; ok {
+		now := time.Now()
+		entity.Name = data.Name
+		entity.Description = data.Description
+		entity.UpdatedAt = &now
+	}
+	return data, nil
+}
+
+// Delete removes the campaign, its links are kept outside of any campaign
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	if err := m.links.DetachCampaign(ctx, id); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.campaigns, id)
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, filter *model.CampaignFilter) (*model.CampaignEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entity := range m.sorted() {
+		if filter.ID != 0 && entity.ID != filter.ID {
+			continue
+		}
+		if filter.Name != "" && entity.Name != filter.Name {
+			continue
+		}
+		return entity, nil
+	}
+	return nil, nil
+}
+
+// List returns the campaigns ordered by name
+func (m *Memory) List(ctx context.Context) ([]*model.CampaignEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entities := m.sorted()
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
+	return entities, nil
+}
+
+func (m *Memory) Stats(ctx context.Context, id uint64) (*model.CampaignStats, error) {
+	return m.links.CampaignStats(ctx, id)
+}
+
+// sorted returns copies of the stored campaigns by id, the caller must hold the lock
+func (m *Memory) sorted() []*model.CampaignEntity {
+	entities := make([]*model.CampaignEntity, 0, len(m.campaigns))
+	for _, stored := range m.campaigns {
+		entity := *stored
+		entities = append(entities, &entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	return entities
+}
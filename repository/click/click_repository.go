@@ -0,0 +1,33 @@
+package click
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+// ClickRepository reads the clicks, they are saved with their count by URLRepository.RecordClick
+type ClickRepository interface {
+	CountByVariant(ctx context.Context, urlID uint64) ([]*model.VariantClickCount, error)
+}
+
+func NewClickRepository(conn *sqlx.DB) ClickRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	countClickByVariantQuery = `SELECT variant_id, COUNT(*) AS clicks FROM click WHERE url_id = ? AND variant_id IS NOT NULL GROUP BY variant_id`
+)
+
+func (s *SQL) CountByVariant(ctx context.Context, urlID uint64) ([]*model.VariantClickCount, error) {
+	var counts []*model.VariantClickCount
+	if err := s.conn.SelectContext(ctx, &counts, s.conn.Rebind(countClickByVariantQuery), urlID); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
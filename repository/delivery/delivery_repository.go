@@ -0,0 +1,182 @@
+package delivery
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+// DeliveryRepository stores the webhook events waiting to be sent and the dead letters
+type DeliveryRepository interface {
+	CreateBatch(ctx context.Context, req []*model.WebhookDeliveryEntity) error
+	Claim(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*model.WebhookDeliveryEntity, error)
+	Delete(ctx context.Context, id uint64) error
+	Retry(ctx context.Context, req *model.WebhookDeliveryEntity) error
+	DeadLetter(ctx context.Context, req *model.WebhookDeliveryEntity) error
+	GetDeadLetter(ctx context.Context, filter *model.WebhookDeadLetterFilter) (*model.WebhookDeadLetterEntity, error)
+	ListDeadLetters(ctx context.Context, webhookID uint64) ([]*model.WebhookDeadLetterEntity, error)
+	Replay(ctx context.Context, id uint64, now time.Time) error
+}
+
+func NewDeliveryRepository(conn *sqlx.DB) DeliveryRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertDeliveryBase   = `INSERT INTO webhook_delivery (webhook_id, event_id, event, payload, attempts, next_attempt_at, created_at) VALUES `
+	insertDeliveryValues = `(?, ?, ?, ?, 0, ?, NOW())`
+	// claimDeliveryQuery locks the due deliveries for this caller so several instances don't send them twice
+	claimDeliveryQuery    = `UPDATE webhook_delivery SET lock_token = ?, locked_until = ? WHERE next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?) ORDER BY next_attempt_at LIMIT ?`
+	getDeliveryBase       = `SELECT id, webhook_id, event_id, event, payload, attempts, next_attempt_at, last_error, created_at FROM webhook_delivery WHERE true`
+	getClaimedQuery       = getDeliveryBase + ` AND lock_token = ? ORDER BY next_attempt_at`
+	deleteDeliveryQuery   = `DELETE FROM webhook_delivery WHERE id = ?`
+	retryDeliveryQuery    = `UPDATE webhook_delivery SET attempts = ?, next_attempt_at = ?, last_error = ?, lock_token = NULL, locked_until = NULL WHERE id = ?`
+	insertDeadLetterQuery = `INSERT INTO webhook_dead_letter (webhook_id, event_id, event, payload, attempts, last_error, created_at, failed_at) VALUES (?, ?, ?, ?, ?, ?, ?, NOW())`
+	getDeadLetterBase     = `SELECT id, webhook_id, event_id, event, payload, attempts, last_error, created_at, failed_at FROM webhook_dead_letter WHERE true`
+	listDeadLetterQuery   = getDeadLetterBase + ` AND webhook_id = ? ORDER BY id DESC`
+	replayDeadLetterQuery = `INSERT INTO webhook_delivery (webhook_id, event_id, event, payload, attempts, next_attempt_at, created_at) SELECT webhook_id, event_id, event, payload, 0, ?, created_at FROM webhook_dead_letter WHERE id = ?`
+	deleteDeadLetterQuery = `DELETE FROM webhook_dead_letter WHERE id = ?`
+)
+
+// claimDeliveryQuery for Postgres and SQLite, which have no ORDER BY and LIMIT in UPDATE. SQLite
+// needs no row locks as it runs one write transaction at a time.
+const (
+	claimDeliveryPostgresQuery = `UPDATE webhook_delivery SET lock_token = ?, locked_until = ? WHERE id IN (SELECT id FROM webhook_delivery WHERE next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?) ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED)`
+	claimDeliverySQLiteQuery   = `UPDATE webhook_delivery SET lock_token = ?, locked_until = ? WHERE id IN (SELECT id FROM webhook_delivery WHERE next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?) ORDER BY next_attempt_at LIMIT ?)`
+)
+
+func (s *SQL) CreateBatch(ctx context.Context, data []*model.WebhookDeliveryEntity) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(data))
+	args := make([]any, 0, len(data)*5)
+	for i, delivery := range data {
+		values[i] = insertDeliveryValues
+		args = append(args, delivery.WebhookID, delivery.EventID, delivery.Event, delivery.Payload, delivery.NextAttemptAt)
+	}
+
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(insertDeliveryBase+strings.Join(values, ", ")), sqldb.Args(s.conn, args...)...)
+	return err
+}
+
+// Claim locks up to limit deliveries due at now until lockUntil and returns them
+func (s *SQL) Claim(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*model.WebhookDeliveryEntity, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	lockToken := hex.EncodeToString(token)
+
+	query := claimDeliveryQuery
+	switch {
+	case sqldb.IsPostgres(s.conn):
+		query = claimDeliveryPostgresQuery
+	case sqldb.IsSQLite(s.conn):
+		query = claimDeliverySQLiteQuery
+	}
+
+	result, err := s.conn.ExecContext(ctx, s.conn.Rebind(query), sqldb.Args(s.conn, lockToken, lockUntil, now, now, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, err
+	}
+
+	var entities []*model.WebhookDeliveryEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(getClaimedQuery), lockToken); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(deleteDeliveryQuery), id)
+	return err
+}
+
+// Retry releases the delivery until its next attempt
+func (s *SQL) Retry(ctx context.Context, data *model.WebhookDeliveryEntity) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(retryDeliveryQuery), sqldb.Args(s.conn, data.Attempts, data.NextAttemptAt, data.LastError, data.ID)...)
+	return err
+}
+
+// DeadLetter moves the delivery to the dead letters
+func (s *SQL) DeadLetter(ctx context.Context, data *model.WebhookDeliveryEntity) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(insertDeadLetterQuery), sqldb.Args(tx, data.WebhookID, data.EventID, data.Event, data.Payload, data.Attempts, data.LastError, data.CreatedAt)...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteDeliveryQuery), data.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQL) GetDeadLetter(ctx context.Context, filter *model.WebhookDeadLetterFilter) (*model.WebhookDeadLetterEntity, error) {
+	query := getDeadLetterBase
+	args := make([]any, 0, 2)
+
+	if filter.ID != 0 {
+		query += " AND id = ?"
+		args = append(args, filter.ID)
+	}
+	if filter.WebhookID != 0 {
+		query += " AND webhook_id = ?"
+		args = append(args, filter.WebhookID)
+	}
+
+	var entity model.WebhookDeadLetterEntity
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(query), args...).StructScan(&entity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *SQL) ListDeadLetters(ctx context.Context, webhookID uint64) ([]*model.WebhookDeadLetterEntity, error) {
+	var entities []*model.WebhookDeadLetterEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listDeadLetterQuery), webhookID); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Replay moves the dead letter back to the deliveries, sent again from now with a fresh attempt count
+func (s *SQL) Replay(ctx context.Context, id uint64, now time.Time) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(replayDeadLetterQuery), sqldb.Args(tx, now, id)...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteDeadLetterQuery), id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,48 @@
+package delivery
+
+import (
+	"context"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Memory is the delivery repository of memory storage. Without an outbox no events
+// are written, so there is never a delivery to send, retry or replay.
+type Memory struct{}
+
+func NewMemoryDeliveryRepository() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) CreateBatch(ctx context.Context, data []*model.WebhookDeliveryEntity) error {
+	return nil
+}
+
+func (m *Memory) Claim(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*model.WebhookDeliveryEntity, error) {
+	return nil, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	return nil
+}
+
+func (m *Memory) Retry(ctx context.Context, data *model.WebhookDeliveryEntity) error {
+	return nil
+}
+
+func (m *Memory) DeadLetter(ctx context.Context, data *model.WebhookDeliveryEntity) error {
+	return nil
+}
+
+func (m *Memory) GetDeadLetter(ctx context.Context, filter *model.WebhookDeadLetterFilter) (*model.WebhookDeadLetterEntity, error) {
+	return nil, nil
+}
+
+func (m *Memory) ListDeadLetters(ctx context.Context, webhookID uint64) ([]*model.WebhookDeadLetterEntity, error) {
+	return nil, nil
+}
+
+func (m *Memory) Replay(ctx context.Context, id uint64, now time.Time) error {
+	return nil
+}
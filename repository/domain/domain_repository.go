@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+type DomainRepository interface {
+	Create(ctx context.Context, req *model.DomainEntity) (*model.DomainEntity, error)
+	Get(ctx context.Context, filter *model.DomainFilter) (*model.DomainEntity, error)
+	List(ctx context.Context) ([]*model.DomainEntity, error)
+	Verify(ctx context.Context, id uint64) error
+	Delete(ctx context.Context, id uint64) error
+}
+
+func NewDomainRepository(conn *sqlx.DB) DomainRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertDomainQuery = `INSERT INTO domain (user_id, host, verification_token, created_at) VALUES (?, ?, ?, NOW())`
+	getDomainBase     = `SELECT id, user_id, host, verification_token, verified_at, created_at, updated_at FROM domain WHERE true`
+	listDomainQuery   = getDomainBase + ` ORDER BY host`
+	verifyDomainQuery = `UPDATE domain SET verified_at = NOW(), updated_at = NOW() WHERE id = ?`
+	deleteDomainQuery = `DELETE FROM domain WHERE id = ? AND NOT EXISTS (SELECT 1 FROM url WHERE domain_id = ?)`
+)
+
+func (s *SQL) Create(ctx context.Context, data *model.DomainEntity) (*model.DomainEntity, error) {
+	id, err := sqldb.InsertID(ctx, s.conn, insertDomainQuery, data.UserID, data.Host, data.VerificationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ID = id
+
+	return data, nil
+}
+
+func (s *SQL) Get(ctx context.Context, filter *model.DomainFilter) (*model.DomainEntity, error) {
+	query := getDomainBase
+	args := make([]any, 0, 2)
+
+	if filter.ID != 0 {
+		query += " AND id = ?"
+		args = append(args, filter.ID)
+	}
+	if filter.Host != "" {
+		query += " AND host = ?"
+		args = append(args, filter.Host)
+	}
+
+	var entity model.DomainEntity
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(query), args...).StructScan(&entity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *SQL) List(ctx context.Context) ([]*model.DomainEntity, error) {
+	var entities []*model.DomainEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listDomainQuery)); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (s *SQL) Verify(ctx context.Context, id uint64) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(verifyDomainQuery), id)
+	return err
+}
+
+// Delete deletes the domain unless a link still uses it, that is reported as ErrConflict
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	result, err := s.conn.ExecContext(ctx, s.conn.Rebind(deleteDomainQuery), id, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		var exists bool
+		if err := s.conn.GetContext(ctx, &exists, s.conn.Rebind(`SELECT EXISTS (SELECT 1 FROM domain WHERE id = ?)`), id); err != nil {
+			return err
+		}
+		if exists {
+			return errors.SetCustomError(constant.ErrConflict)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Memory keeps the domains in memory for running the service without a database
+type Memory struct {
+	mu      sync.RWMutex
+	nextID  uint64
+	domains map[uint64]*model.DomainEntity
+}
+
+func NewMemoryDomainRepository() *Memory {
+	return &Memory{domains: make(map[uint64]*model.DomainEntity)}
+}
+
+func (m *Memory) Create(ctx context.Context, data *model.DomainEntity) (*model.DomainEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	data.ID = m.nextID
+
+	entity := *data
+	entity.VerifiedAt = nil
+	entity.CreatedAt = time.Now()
+	entity.UpdatedAt = nil
+	m.domains[entity.ID] = &entity
+
+	return data, nil
+}
+
+func (m *Memory) Get(ctx context.Context, filter *model.DomainFilter) (*model.DomainEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entity := range m.sorted() {
+		if filter.ID != 0 && entity.ID != filter.ID {
+			continue
+		}
+		if filter.Host != "" && entity.Host != filter.Host {
+			continue
+		}
+		return entity, nil
+	}
+	return nil, nil
+}
+
+// List returns the domains ordered by host
+func (m *Memory) List(ctx context.Context) ([]*model.DomainEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entities := m.sorted()
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Host < entities[j].Host })
+	return entities, nil
+}
+
+func (m *Memory) Verify(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entity, ok := m.domains[id]; ok {
+		now := time.Now()
+		entity.VerifiedAt = &now
+		entity.UpdatedAt = &now
+	}
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.domains, id)
+	return nil
+}
+
+// sorted returns copies of the stored domains by id, the caller must hold the lock
+func (m *Memory) sorted() []*model.DomainEntity {
+	entities := make([]*model.DomainEntity, 0, len(m.domains))
+	for _, stored := range m.domains {
+		entity := *stored
+		entities = append(entities, &entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	return entities
+}
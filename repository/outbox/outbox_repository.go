@@ -0,0 +1,191 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+// OutboxRepository reads the domain events waiting to be published, the events themselves
+// are written by WriteLinkEvents in the transaction of the change they describe
+type OutboxRepository interface {
+	Claim(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*model.OutboxEntity, error)
+	Delete(ctx context.Context, id uint64) error
+	Retry(ctx context.Context, req *model.OutboxEntity, retryAt time.Time) error
+	Fail(ctx context.Context, req *model.OutboxEntity) error
+}
+
+func NewOutboxRepository(conn *sqlx.DB) OutboxRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertOutboxBase   = `INSERT INTO outbox (event_id, event, url_id, payload, created_at) VALUES `
+	insertOutboxValues = `(?, ?, ?, ?, NOW())`
+	// claimOutboxQuery locks the oldest events for this caller so several instances don't publish them twice
+	claimOutboxQuery  = `UPDATE outbox SET lock_token = ?, locked_until = ? WHERE failed_at IS NULL AND (locked_until IS NULL OR locked_until <= ?) ORDER BY id LIMIT ?`
+	getClaimedQuery   = `SELECT id, event_id, event, url_id, payload, attempts, COALESCE(last_error, '') AS last_error, created_at FROM outbox WHERE lock_token = ? ORDER BY id`
+	deleteOutboxQuery = `DELETE FROM outbox WHERE id = ?`
+	// failed events keep their lock until retryAt, events failed for good are left aside with failed_at
+	retryOutboxQuery = `UPDATE outbox SET attempts = ?, last_error = ?, lock_token = NULL, locked_until = ? WHERE id = ?`
+	failOutboxQuery  = `UPDATE outbox SET attempts = ?, last_error = ?, lock_token = NULL, failed_at = NOW() WHERE id = ?`
+
+	// links without a short url are still being created, their changes are part of link.created
+	listLinksQuery = `SELECT url.id, url.short_url, url.original_url, url.campaign_id, url.click_count, COALESCE(domain.host, '') AS domain FROM url LEFT JOIN domain ON domain.id = url.domain_id WHERE url.short_url != '' AND url.id IN (?) ORDER BY url.id`
+	listTagsQuery  = `SELECT url_tag.url_id, tag.name FROM url_tag JOIN tag ON tag.id = url_tag.tag_id WHERE url_tag.url_id IN (?) ORDER BY tag.name`
+
+	// chunkSize keeps the IN lists and multi-row inserts well below the placeholder limit
+	chunkSize = 500
+)
+
+// claimOutboxQuery for Postgres and SQLite, which have no ORDER BY and LIMIT in UPDATE. SQLite
+// needs no row locks as it runs one write transaction at a time.
+const (
+	claimOutboxPostgresQuery = `UPDATE outbox SET lock_token = ?, locked_until = ? WHERE id IN (SELECT id FROM outbox WHERE failed_at IS NULL AND (locked_until IS NULL OR locked_until <= ?) ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED)`
+	claimOutboxSQLiteQuery   = `UPDATE outbox SET lock_token = ?, locked_until = ? WHERE id IN (SELECT id FROM outbox WHERE failed_at IS NULL AND (locked_until IS NULL OR locked_until <= ?) ORDER BY id LIMIT ?)`
+)
+
+// Claim locks up to limit events, oldest first, until lockUntil and returns them
+func (s *SQL) Claim(ctx context.Context, now time.Time, lockUntil time.Time, limit int) ([]*model.OutboxEntity, error) {
+	lockToken, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	query := claimOutboxQuery
+	switch {
+	case sqldb.IsPostgres(s.conn):
+		query = claimOutboxPostgresQuery
+	case sqldb.IsSQLite(s.conn):
+		query = claimOutboxSQLiteQuery
+	}
+
+	result, err := s.conn.ExecContext(ctx, s.conn.Rebind(query), sqldb.Args(s.conn, lockToken, lockUntil, now, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return nil, err
+	}
+
+	var entities []*model.OutboxEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(getClaimedQuery), lockToken); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(deleteOutboxQuery), id)
+	return err
+}
+
+// Retry records the failed attempt and keeps the event from being claimed until retryAt
+func (s *SQL) Retry(ctx context.Context, data *model.OutboxEntity, retryAt time.Time) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(retryOutboxQuery), sqldb.Args(s.conn, data.Attempts, data.LastError, retryAt, data.ID)...)
+	return err
+}
+
+// Fail records the last attempt and leaves the event aside, it is not claimed anymore
+func (s *SQL) Fail(ctx context.Context, data *model.OutboxEntity) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(failOutboxQuery), data.Attempts, data.LastError, data.ID)
+	return err
+}
+
+// linkRow is the state of a link written in its events
+type linkRow struct {
+	ID          uint64 `db:"id"`
+	ShortURL    string `db:"short_url"`
+	OriginalURL string `db:"original_url"`
+	Domain      string `db:"domain"`
+	CampaignID  uint64 `db:"campaign_id"`
+	ClickCount  uint64 `db:"click_count"`
+}
+
+// WriteLinkEvents records the event for every link with tx, describing the links as they
+// are in tx. fill may complete the data of each event, it can be nil.
+func WriteLinkEvents(ctx context.Context, tx *sqlx.Tx, event string, urlIDs []uint64, fill func(data *model.LinkEventData)) error {
+	for start := 0; start < len(urlIDs); start += chunkSize {
+		if err := writeLinkEvents(ctx, tx, event, urlIDs[start:min(start+chunkSize, len(urlIDs))], fill); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeLinkEvents(ctx context.Context, tx *sqlx.Tx, event string, urlIDs []uint64, fill func(data *model.LinkEventData)) error {
+	query, args, err := sqlx.In(listLinksQuery, urlIDs)
+	if err != nil {
+		return err
+	}
+	var links []*linkRow
+	if err := tx.SelectContext(ctx, &links, tx.Rebind(query), args...); err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	query, args, err = sqlx.In(listTagsQuery, urlIDs)
+	if err != nil {
+		return err
+	}
+	var urlTags []*model.URLTag
+	if err := tx.SelectContext(ctx, &urlTags, tx.Rebind(query), args...); err != nil {
+		return err
+	}
+	tags := make(map[uint64][]string, len(links))
+	for _, urlTag := range urlTags {
+		tags[urlTag.URLID] = append(tags[urlTag.URLID], urlTag.Name)
+	}
+
+	values := make([]string, len(links))
+	args = make([]any, 0, len(links)*4)
+	for i, link := range links {
+		data := &model.LinkEventData{
+			ShortURL:    link.ShortURL,
+			OriginalURL: link.OriginalURL,
+			Domain:      link.Domain,
+			CampaignID:  link.CampaignID,
+			Tags:        tags[link.ID],
+			ClickCount:  link.ClickCount,
+		}
+		if fill != nil {
+			fill(data)
+		}
+
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		eventID, err := newID()
+		if err != nil {
+			return err
+		}
+
+		values[i] = insertOutboxValues
+		args = append(args, eventID, event, link.ID, string(payload))
+	}
+
+	_, err = tx.ExecContext(ctx, tx.Rebind(insertOutboxBase+strings.Join(values, ", ")), args...)
+	return err
+}
+
+func newID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
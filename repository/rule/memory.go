@@ -0,0 +1,115 @@
+package rule
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Memory keeps the rules in memory for running the service without a database
+type Memory struct {
+	mu     sync.RWMutex
+	nextID uint64
+	rules  map[uint64]*model.RuleEntity
+}
+
+func NewMemoryRuleRepository() *Memory {
+	return &Memory{rules: make(map[uint64]*model.RuleEntity)}
+}
+
+func (m *Memory) Create(ctx context.Context, data *model.RuleEntity) (*model.RuleEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	data.ID = m.nextID
+
+	entity := *data
+	entity.CreatedAt = time.Now()
+	entity.UpdatedAt = nil
+	m.rules[entity.ID] = &entity
+
+	return data, nil
+}
+
+func (m *Memory) Update(ctx context.Context, data *model.RuleEntity) (*model.RuleEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entity, ok := m.rules[data.ID]; ok {
+		now := time.Now()
+		entity.Priority = data.Priority
+		entity.Country = data.Country
+		entity.DeviceType = data.DeviceType
+		entity.OS = data.OS
+		entity.Language = data.Language
+		entity.DestinationURL = data.DestinationURL
+		entity.UpdatedAt = &now
+	}
+	return data, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.rules, id)
+	return nil
+}
+
+// DeleteByURL removes the rules of a deleted link
+func (m *Memory) DeleteByURL(urlID uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, entity := range m.rules {
+		if entity.URLID == urlID {
+			delete(m.rules, id)
+		}
+	}
+}
+
+func (m *Memory) Get(ctx context.Context, filter *model.RuleFilter) (*model.RuleEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entity := range m.sorted() {
+		if filter.ID != 0 && entity.ID != filter.ID {
+			continue
+		}
+		if filter.URLID != 0 && entity.URLID != filter.URLID {
+			continue
+		}
+		return entity, nil
+	}
+	return nil, nil
+}
+
+// List returns the rules of a link in evaluation order, by priority then id
+func (m *Memory) List(ctx context.Context, urlID uint64) ([]*model.RuleEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entities []*model.RuleEntity
+	for _, entity := range m.sorted() {
+		if entity.URLID == urlID {
+			entities = append(entities, entity)
+		}
+	}
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Priority < entities[j].Priority })
+	return entities, nil
+}
+
+// sorted returns copies of the stored rules by id, the caller must hold the lock
+func (m *Memory) sorted() []*model.RuleEntity {
+	entities := make([]*model.RuleEntity, 0, len(m.rules))
+	for _, stored := range m.rules {
+		entity := *stored
+		entities = append(entities, &entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	return entities
+}
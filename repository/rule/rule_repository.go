@@ -0,0 +1,90 @@
+package rule
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+type RuleRepository interface {
+	Create(ctx context.Context, req *model.RuleEntity) (*model.RuleEntity, error)
+	Update(ctx context.Context, req *model.RuleEntity) (*model.RuleEntity, error)
+	Delete(ctx context.Context, id uint64) error
+	Get(ctx context.Context, filter *model.RuleFilter) (*model.RuleEntity, error)
+	List(ctx context.Context, urlID uint64) ([]*model.RuleEntity, error)
+}
+
+func NewRuleRepository(conn *sqlx.DB) RuleRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertRuleQuery = `INSERT INTO url_rule (url_id, priority, country, device_type, os, language, destination_url, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, NOW())`
+	updateRuleQuery = `UPDATE url_rule SET priority = ?, country = ?, device_type = ?, os = ?, language = ?, destination_url = ?, updated_at = NOW() WHERE id = ?`
+	deleteRuleQuery = `DELETE FROM url_rule WHERE id = ?`
+	getRuleBase     = `SELECT id, url_id, priority, country, device_type, os, language, destination_url, created_at, updated_at FROM url_rule WHERE true`
+	listRuleQuery   = getRuleBase + ` AND url_id = ? ORDER BY priority, id`
+)
+
+func (s *SQL) Create(ctx context.Context, data *model.RuleEntity) (*model.RuleEntity, error) {
+	id, err := sqldb.InsertID(ctx, s.conn, insertRuleQuery, data.URLID, data.Priority, data.Country, data.DeviceType, data.OS, data.Language, data.DestinationURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ID = id
+
+	return data, nil
+}
+
+func (s *SQL) Update(ctx context.Context, data *model.RuleEntity) (*model.RuleEntity, error) {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(updateRuleQuery), data.Priority, data.Country, data.DeviceType, data.OS, data.Language, data.DestinationURL, data.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	_, err := s.conn.ExecContext(ctx, s.conn.Rebind(deleteRuleQuery), id)
+	return err
+}
+
+func (s *SQL) Get(ctx context.Context, filter *model.RuleFilter) (*model.RuleEntity, error) {
+	query := getRuleBase
+	args := make([]any, 0, 2)
+
+	if filter.ID != 0 {
+		query += " AND id = ?"
+		args = append(args, filter.ID)
+	}
+	if filter.URLID != 0 {
+		query += " AND url_id = ?"
+		args = append(args, filter.URLID)
+	}
+
+	var entity model.RuleEntity
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(query), args...).StructScan(&entity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *SQL) List(ctx context.Context, urlID uint64) ([]*model.RuleEntity, error) {
+	var entities []*model.RuleEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listRuleQuery), urlID); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
@@ -0,0 +1,130 @@
+package tag
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Memory keeps the tags and the tags of the links in memory for running the service
+// without a database, no events are written since there is no outbox
+type Memory struct {
+	mu     sync.RWMutex
+	nextID uint64
+	tags   map[uint64]*model.TagEntity
+	// urlTags holds the tag ids of every link
+	urlTags map[uint64][]uint64
+}
+
+func NewMemoryTagRepository() *Memory {
+	return &Memory{
+		tags:    make(map[uint64]*model.TagEntity),
+		urlTags: make(map[uint64][]uint64),
+	}
+}
+
+func (m *Memory) Create(ctx context.Context, data *model.TagEntity) (*model.TagEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	data.ID = m.nextID
+	data.CreatedAt = time.Now()
+
+	entity := *data
+	m.tags[entity.ID] = &entity
+
+	return data, nil
+}
+
+// Delete removes the tag from every link then the tag itself
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for urlID, tagIDs := range m.urlTags {
+		kept := make([]uint64, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			if tagID != id {
+				kept = append(kept, tagID)
+			}
+		}
+		m.urlTags[urlID] = kept
+	}
+	delete(m.tags, id)
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, filter *model.TagFilter) (*model.TagEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entity := range m.sorted() {
+		if filter.ID != 0 && entity.ID != filter.ID {
+			continue
+		}
+		if filter.Name != "" && entity.Name != filter.Name {
+			continue
+		}
+		return entity, nil
+	}
+	return nil, nil
+}
+
+// List returns the tags ordered by name
+func (m *Memory) List(ctx context.Context) ([]*model.TagEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entities := m.sorted()
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].Name < entities[j].Name })
+	return entities, nil
+}
+
+// SetURLTags replaces the tags of a link
+func (m *Memory) SetURLTags(ctx context.Context, urlID uint64, tagIDs []uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(tagIDs) == 0 {
+		delete(m.urlTags, urlID)
+		return nil
+	}
+	m.urlTags[urlID] = append([]uint64(nil), tagIDs...)
+	return nil
+}
+
+// ListByURLs returns the tags of the links ordered by tag name
+func (m *Memory) ListByURLs(ctx context.Context, urlIDs []uint64) ([]*model.URLTag, error) {
+	if len(urlIDs) == 0 {
+		return nil, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tags []*model.URLTag
+	for _, urlID := range urlIDs {
+		for _, tagID := range m.urlTags[urlID] {
+			if entity, ok := m.tags[tagID]; ok {
+				tags = append(tags, &model.URLTag{URLID: urlID, Name: entity.Name})
+			}
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// sorted returns copies of the stored tags by id, the caller must hold the lock
+func (m *Memory) sorted() []*model.TagEntity {
+	entities := make([]*model.TagEntity, 0, len(m.tags))
+	for _, stored := range m.tags {
+		entity := *stored
+		entities = append(entities, &entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].ID < entities[j].ID })
+	return entities
+}
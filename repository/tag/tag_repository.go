@@ -0,0 +1,156 @@
+package tag
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/outbox"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+type TagRepository interface {
+	Create(ctx context.Context, req *model.TagEntity) (*model.TagEntity, error)
+	Delete(ctx context.Context, id uint64) error
+	Get(ctx context.Context, filter *model.TagFilter) (*model.TagEntity, error)
+	List(ctx context.Context) ([]*model.TagEntity, error)
+	SetURLTags(ctx context.Context, urlID uint64, tagIDs []uint64) error
+	ListByURLs(ctx context.Context, urlIDs []uint64) ([]*model.URLTag, error)
+}
+
+func NewTagRepository(conn *sqlx.DB) TagRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertTagQuery      = `INSERT INTO tag (name, created_at) VALUES (?, NOW())`
+	deleteTagQuery      = `DELETE FROM tag WHERE id = ?`
+	deleteTagURLsQuery  = `DELETE FROM url_tag WHERE tag_id = ?`
+	listTagURLIDsQuery  = `SELECT url_id FROM url_tag WHERE tag_id = ?`
+	getTagBase          = `SELECT id, name, created_at FROM tag WHERE true`
+	listTagQuery        = getTagBase + ` ORDER BY name`
+	deleteURLTagsQuery  = `DELETE FROM url_tag WHERE url_id = ?`
+	insertURLTagBase    = `INSERT INTO url_tag (url_id, tag_id) VALUES `
+	listTagsByURLsQuery = `SELECT url_tag.url_id, tag.name FROM url_tag JOIN tag ON tag.id = url_tag.tag_id WHERE url_tag.url_id IN (?) ORDER BY tag.name`
+)
+
+func (s *SQL) Create(ctx context.Context, data *model.TagEntity) (*model.TagEntity, error) {
+	id, err := sqldb.InsertID(ctx, s.conn, insertTagQuery, data.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ID = id
+
+	return data, nil
+}
+
+// Delete removes the tag from every link then the tag itself
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var urlIDs []uint64
+	if err := tx.SelectContext(ctx, &urlIDs, tx.Rebind(listTagURLIDsQuery), id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteTagURLsQuery), id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteTagQuery), id); err != nil {
+		return err
+	}
+	if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkUpdated, urlIDs, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQL) Get(ctx context.Context, filter *model.TagFilter) (*model.TagEntity, error) {
+	query := getTagBase
+	args := make([]any, 0, 2)
+
+	if filter.ID != 0 {
+		query += " AND id = ?"
+		args = append(args, filter.ID)
+	}
+	if filter.Name != "" {
+		query += " AND name = ?"
+		args = append(args, filter.Name)
+	}
+
+	var entity model.TagEntity
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(query), args...).StructScan(&entity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *SQL) List(ctx context.Context) ([]*model.TagEntity, error) {
+	var entities []*model.TagEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listTagQuery)); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// SetURLTags replaces the tags of a link, announcing it as updated
+func (s *SQL) SetURLTags(ctx context.Context, urlID uint64, tagIDs []uint64) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(deleteURLTagsQuery), urlID); err != nil {
+		return err
+	}
+
+	if len(tagIDs) > 0 {
+		values := make([]string, len(tagIDs))
+		args := make([]any, 0, len(tagIDs)*2)
+		for i, tagID := range tagIDs {
+			values[i] = "(?, ?)"
+			args = append(args, urlID, tagID)
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(insertURLTagBase+strings.Join(values, ", ")), args...); err != nil {
+			return err
+		}
+	}
+	if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkUpdated, []uint64{urlID}, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQL) ListByURLs(ctx context.Context, urlIDs []uint64) ([]*model.URLTag, error) {
+	if len(urlIDs) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := sqlx.In(listTagsByURLsQuery, urlIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []*model.URLTag
+	if err := s.conn.SelectContext(ctx, &tags, s.conn.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
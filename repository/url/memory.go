@@ -0,0 +1,442 @@
+package url
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/rule"
+	"github.com/muhammadheryan/url-shortner-base62/repository/tag"
+	"github.com/muhammadheryan/url-shortner-base62/repository/variant"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// Memory keeps the links in memory for running the service without a database. It
+// behaves like SQL except that no events are written since there is no outbox.
+// It also counts the clicks it records, so it can be used as click.ClickRepository.
+type Memory struct {
+	mu     sync.RWMutex
+	nextID uint64
+	urls   map[uint64]*memoryURL
+	// shortURLs indexes the links by domain and short url like the unique index of the url table
+	shortURLs map[memoryShortURL]uint64
+	clicks    []*model.ClickEntity
+	// tags resolves the tag filter of List and drops the tags of deleted links
+	tags tag.TagRepository
+	// rules and variants drop the rules and variants of deleted links
+	rules    *rule.Memory
+	variants *variant.Memory
+	now      func() time.Time
+}
+
+// memoryURL is a stored link with the columns not exposed by model.URLEntity
+type memoryURL struct {
+	entity           model.URLEntity
+	expiryNotifiedAt *time.Time
+}
+
+// NewMemoryURLRepository keeps the links in memory, tags, rules and variants hold the
+// tags, rules and variants of the links and may be nil when they are not kept
+func NewMemoryURLRepository(tags tag.TagRepository, rules *rule.Memory, variants *variant.Memory) *Memory {
+	return &Memory{
+		urls:      make(map[uint64]*memoryURL),
+		shortURLs: make(map[memoryShortURL]uint64),
+		tags:      tags,
+		rules:     rules,
+		variants:  variants,
+		now:       time.Now,
+	}
+}
+
+func (m *Memory) Create(ctx context.Context, data *model.URLEntity) (*model.URLEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.insert(data)
+	return data, nil
+}
+
+func (m *Memory) CreateBatch(ctx context.Context, data []*model.URLEntity) ([]*model.URLEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, item := range data {
+		m.insert(item)
+	}
+	return data, nil
+}
+
+// insert stores a copy of the link with the defaults of the url table, the short url
+// is set by Update once the id is known
+func (m *Memory) insert(data *model.URLEntity) {
+	m.nextID++
+	data.ID = m.nextID
+
+	entity := *data
+	entity.ShortURL = ""
+	entity.Status = constant.URLStatusActive
+	entity.ClickCount = 0
+	entity.ConsumedAt = nil
+	entity.CreatedAt = m.now()
+	entity.UpdatedAt = nil
+	m.urls[entity.ID] = &memoryURL{entity: entity}
+}
+
+func (m *Memory) Update(ctx context.Context, data *model.URLEntity) (*model.URLEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.urls[data.ID]; ok && m.shortURLTaken(stored.entity.DomainID, data.ShortURL, data.ID) {
+		return nil, errors.SetCustomError(constant.ErrConflict)
+	}
+
+	if stored, ok := m.urls[data.ID]; ok {
+		now := m.now()
+		m.setShortURL(stored, data.ShortURL)
+		stored.entity.OriginalURL = data.OriginalURL
+		stored.entity.RedirectType = data.RedirectType
+		stored.entity.UpdatedAt = &now
+	}
+	return data, nil
+}
+
+// UpdateBatch sets the short url of every link or none of them
+func (m *Memory) UpdateBatch(ctx context.Context, data []*model.URLEntity) ([]*model.URLEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shortURLs := make(map[memoryShortURL]uint64, len(data))
+	for _, item := range data {
+		stored, ok := m.urls[item.ID]
+		if !ok {
+			continue
+		}
+		key := memoryShortURL{domainID: stored.entity.DomainID, shortURL: item.ShortURL}
+		if id, ok := shortURLs[key]; (ok && id != item.ID) || m.shortURLTaken(key.domainID, item.ShortURL, item.ID) {
+			return nil, errors.SetCustomError(constant.ErrConflict)
+		}
+		if item.ShortURL != "" {
+			shortURLs[key] = item.ID
+		}
+	}
+
+	now := m.now()
+	for _, item := range data {
+		if stored, ok := m.urls[item.ID]; ok {
+			m.setShortURL(stored, item.ShortURL)
+			stored.entity.UpdatedAt = &now
+		}
+	}
+	return data, nil
+}
+
+// memoryShortURL is the key of the unique index on domain_id and short_url
+type memoryShortURL struct {
+	domainID uint64
+	shortURL string
+}
+
+// shortURLTaken checks the unique index on domain_id and short_url, links without a short url don't count
+func (m *Memory) shortURLTaken(domainID uint64, shortURL string, id uint64) bool {
+	if shortURL == "" {
+		return false
+	}
+	takenBy, ok := m.shortURLs[memoryShortURL{domainID: domainID, shortURL: shortURL}]
+	return ok && takenBy != id
+}
+
+// setShortURL sets the short url of a stored link and keeps the index up to date, the caller must hold the lock
+func (m *Memory) setShortURL(stored *memoryURL, shortURL string) {
+	key := memoryShortURL{domainID: stored.entity.DomainID, shortURL: stored.entity.ShortURL}
+	if id, ok := m.shortURLs[key]; ok && id == stored.entity.ID {
+		delete(m.shortURLs, key)
+	}
+	stored.entity.ShortURL = shortURL
+	if shortURL != "" {
+		m.shortURLs[memoryShortURL{domainID: stored.entity.DomainID, shortURL: shortURL}] = stored.entity.ID
+	}
+}
+
+// Get looks the link up by id or through the short url index, other filters fall back
+// to the link with the lowest id matching them
+func (m *Memory) Get(ctx context.Context, filter *model.URLFilter) (*model.URLEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found *memoryURL
+	switch {
+	case filter.ID != 0:
+		found = m.urls[filter.ID]
+	case filter.ShortURL != "" && filter.DomainID != nil:
+		if id, ok := m.shortURLs[memoryShortURL{domainID: *filter.DomainID, shortURL: filter.ShortURL}]; ok {
+			found = m.urls[id]
+		}
+	default:
+		for _, stored := range m.urls {
+			if matchesURLFilter(&stored.entity, filter) && (found == nil || stored.entity.ID < found.entity.ID) {
+				found = stored
+			}
+		}
+	}
+
+	if found == nil || !matchesURLFilter(&found.entity, filter) {
+		return nil, nil
+	}
+	entity := found.entity
+	return &entity, nil
+}
+
+// matchesURLFilter checks the link against the lookup filters of Get
+func matchesURLFilter(entity *model.URLEntity, filter *model.URLFilter) bool {
+	if filter.ID != 0 && entity.ID != filter.ID {
+		return false
+	}
+	if filter.ShortURL != "" && entity.ShortURL != filter.ShortURL {
+		return false
+	}
+	if filter.DomainID != nil && entity.DomainID != *filter.DomainID {
+		return false
+	}
+	return true
+}
+
+func (m *Memory) RecordClick(ctx context.Context, click *model.ClickEntity, writeEvent bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.urls[click.URLID]; ok {
+		stored.entity.ClickCount++
+	}
+
+	recorded := *click
+	recorded.ID = uint64(len(m.clicks) + 1)
+	recorded.CreatedAt = m.now()
+	m.clicks = append(m.clicks, &recorded)
+	return nil
+}
+
+// CountByVariant counts the recorded clicks of a link per variant served
+func (m *Memory) CountByVariant(ctx context.Context, urlID uint64) ([]*model.VariantClickCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[uint64]uint64)
+	for _, click := range m.clicks {
+		if click.URLID == urlID && click.VariantID != nil {
+			counts[*click.VariantID]++
+		}
+	}
+
+	result := make([]*model.VariantClickCount, 0, len(counts))
+	for variantID, clicks := range counts {
+		result = append(result, &model.VariantClickCount{VariantID: variantID, Clicks: clicks})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].VariantID < result[j].VariantID })
+	return result, nil
+}
+
+// Consume marks a single use url as consumed, only the first caller gets true
+func (m *Memory) Consume(ctx context.Context, id uint64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.urls[id]
+	if !ok || stored.entity.Status != constant.URLStatusActive {
+		return false, nil
+	}
+
+	now := m.now()
+	stored.entity.Status = constant.URLStatusConsumed
+	stored.entity.ConsumedAt = &now
+	return true, nil
+}
+
+// List returns the links matching the filter, newest first
+func (m *Memory) List(ctx context.Context, filter *model.URLFilter) ([]*model.URLEntity, error) {
+	var tagged map[uint64]bool
+	if filter.Tag != "" {
+		var err error
+		if tagged, err = m.taggedURLs(ctx, filter.Tag); err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entities []*model.URLEntity
+	skipped := 0
+	for _, stored := range m.sorted(true) {
+		entity := stored.entity
+		if filter.DomainID != nil && entity.DomainID != *filter.DomainID {
+			continue
+		}
+		if filter.CampaignID != 0 && entity.CampaignID != filter.CampaignID {
+			continue
+		}
+		if tagged != nil && !tagged[entity.ID] {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if len(entities) >= filter.Limit {
+			break
+		}
+		entities = append(entities, &entity)
+	}
+	return entities, nil
+}
+
+// taggedURLs returns the ids of the links having the tag
+func (m *Memory) taggedURLs(ctx context.Context, name string) (map[uint64]bool, error) {
+	tagged := make(map[uint64]bool)
+	if m.tags == nil {
+		return tagged, nil
+	}
+
+	m.mu.RLock()
+	urlIDs := make([]uint64, 0, len(m.urls))
+	for id := range m.urls {
+		urlIDs = append(urlIDs, id)
+	}
+	m.mu.RUnlock()
+
+	urlTags, err := m.tags.ListByURLs(ctx, urlIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, urlTag := range urlTags {
+		if urlTag.Name == name {
+			tagged[urlTag.URLID] = true
+		}
+	}
+	return tagged, nil
+}
+
+func (m *Memory) SetCampaign(ctx context.Context, id uint64, campaignID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.urls[id]; ok {
+		now := m.now()
+		stored.entity.CampaignID = campaignID
+		stored.entity.UpdatedAt = &now
+	}
+	return nil
+}
+
+// DetachCampaign moves the links of a deleted campaign out of any campaign
+func (m *Memory) DetachCampaign(ctx context.Context, campaignID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for _, stored := range m.urls {
+		if stored.entity.CampaignID == campaignID {
+			stored.entity.CampaignID = 0
+			stored.entity.UpdatedAt = &now
+		}
+	}
+	return nil
+}
+
+// CampaignStats counts the links of a campaign and their clicks
+func (m *Memory) CampaignStats(ctx context.Context, campaignID uint64) (*model.CampaignStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats model.CampaignStats
+	for _, stored := range m.urls {
+		if stored.entity.CampaignID == campaignID {
+			stats.Links++
+			stats.TotalClicks += stored.entity.ClickCount
+		}
+	}
+	return &stats, nil
+}
+
+// Delete removes the link with its rules, variants, clicks and tags
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	if m.tags != nil {
+		if err := m.tags.SetURLTags(ctx, id, nil); err != nil {
+			return err
+		}
+	}
+	if m.rules != nil {
+		m.rules.DeleteByURL(id)
+	}
+	if m.variants != nil {
+		m.variants.DeleteByURL(id)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.urls[id]; ok {
+		m.setShortURL(stored, "")
+	}
+	delete(m.urls, id)
+	clicks := m.clicks[:0]
+	for _, click := range m.clicks {
+		if click.URLID != id {
+			clicks = append(clicks, click)
+		}
+	}
+	m.clicks = clicks
+	return nil
+}
+
+// ListExpired returns the links whose activation window ended by now and were not announced yet
+func (m *Memory) ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.URLEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entities []*model.URLEntity
+	for _, stored := range m.sorted(false) {
+		entity := stored.entity
+		if entity.ActiveUntil != nil && !entity.ActiveUntil.After(now) && stored.expiryNotifiedAt == nil {
+			entities = append(entities, &entity)
+		}
+	}
+	sort.SliceStable(entities, func(i, j int) bool { return entities[i].ActiveUntil.Before(*entities[j].ActiveUntil) })
+
+	if len(entities) > limit {
+		entities = entities[:limit]
+	}
+	return entities, nil
+}
+
+// MarkExpiryNotified records the link as announced expired, only the first caller gets true
+func (m *Memory) MarkExpiryNotified(ctx context.Context, id uint64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.urls[id]
+	if !ok || stored.expiryNotifiedAt != nil {
+		return false, nil
+	}
+
+	now := m.now()
+	stored.expiryNotifiedAt = &now
+	return true, nil
+}
+
+// sorted returns the stored links by id, the caller must hold the lock
+func (m *Memory) sorted(newestFirst bool) []*memoryURL {
+	result := make([]*memoryURL, 0, len(m.urls))
+	for _, stored := range m.urls {
+		result = append(result, stored)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if newestFirst {
+			return result[i].entity.ID > result[j].entity.ID
+		}
+		return result[i].entity.ID < result[j].entity.ID
+	})
+	return result
+}
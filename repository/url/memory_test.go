@@ -0,0 +1,87 @@
+package url_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/campaign"
+	"github.com/muhammadheryan/url-shortner-base62/repository/rule"
+	"github.com/muhammadheryan/url-shortner-base62/repository/tag"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url/urltest"
+	"github.com/muhammadheryan/url-shortner-base62/repository/variant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	urltest.RunContract(t, func(t *testing.T) (url.URLRepository, tag.TagRepository) {
+		tags := tag.NewMemoryTagRepository()
+		return url.NewMemoryURLRepository(tags, nil, nil), tags
+	})
+}
+
+func TestMemory_DeleteDropsRulesAndVariants(t *testing.T) {
+	ctx := context.Background()
+	rules := rule.NewMemoryRuleRepository()
+	variants := variant.NewMemoryVariantRepository()
+	urls := url.NewMemoryURLRepository(tag.NewMemoryTagRepository(), rules, variants)
+
+	deleted, err := urls.Create(ctx, &model.URLEntity{OriginalURL: "https://example.com/a"})
+	require.NoError(t, err)
+	kept, err := urls.Create(ctx, &model.URLEntity{OriginalURL: "https://example.com/b"})
+	require.NoError(t, err)
+	for _, urlID := range []uint64{deleted.ID, kept.ID} {
+		_, err := rules.Create(ctx, &model.RuleEntity{URLID: urlID, Country: "ID", DestinationURL: "https://example.com/id"})
+		require.NoError(t, err)
+		_, err = variants.CreateBatch(ctx, []*model.VariantEntity{{URLID: urlID, DestinationURL: "https://example.com/v", Weight: 1}})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, urls.Delete(ctx, deleted.ID))
+
+	deletedRules, err := rules.List(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deletedRules)
+	deletedVariants, err := variants.List(ctx, deleted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deletedVariants)
+
+	keptRules, err := rules.List(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Len(t, keptRules, 1)
+	keptVariants, err := variants.List(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Len(t, keptVariants, 1)
+}
+
+func TestMemory_Campaigns(t *testing.T) {
+	ctx := context.Background()
+	urls := url.NewMemoryURLRepository(tag.NewMemoryTagRepository(), nil, nil)
+	campaigns := campaign.NewMemoryCampaignRepository(urls)
+
+	launch, err := campaigns.Create(ctx, &model.CampaignEntity{Name: "launch"})
+	require.NoError(t, err)
+	for _, original := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+		created, err := urls.Create(ctx, &model.URLEntity{OriginalURL: original})
+		require.NoError(t, err)
+		if original != "https://example.com/c" {
+			require.NoError(t, urls.SetCampaign(ctx, created.ID, launch.ID))
+		}
+		require.NoError(t, urls.RecordClick(ctx, &model.ClickEntity{URLID: created.ID}, true))
+	}
+
+	stats, err := campaigns.Stats(ctx, launch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &model.CampaignStats{Links: 2, TotalClicks: 2}, stats)
+
+	require.NoError(t, campaigns.Delete(ctx, launch.ID))
+
+	deleted, err := campaigns.Get(ctx, &model.CampaignFilter{ID: launch.ID})
+	require.NoError(t, err)
+	assert.Nil(t, deleted)
+	stats, err = campaigns.Stats(ctx, launch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &model.CampaignStats{}, stats)
+}
@@ -0,0 +1,30 @@
+package url_test
+
+import (
+	"os"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url/urltest"
+)
+
+// TestSQL runs the contract on the MySQL database of TEST_MYSQL_DSN, which must allow
+// multiple statements and parse times (multiStatements=true&parseTime=true). Its data is
+// deleted, never point it to a database in use.
+func TestSQL(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN is not set")
+	}
+
+	db, err := sqlx.Connect("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	urltest.Migrate(t, db)
+	urltest.RunSQL(t, db, url.NewURLRepository, urltest.DeleteTables)
+}
@@ -0,0 +1,31 @@
+package url_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url/urltest"
+)
+
+// TestSQLite runs the contract on a database file of the test, opened like the service does.
+// The process runs in a zone ahead of UTC, the repository has to write its times in UTC for
+// them to compare with the ones of NOW().
+func TestSQLite(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+7", 7*60*60)
+	defer func() { time.Local = local }()
+
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_time_format=sqlite&_txlock=immediate"
+	db, err := sqlx.Connect(constant.DriverSQLite, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	urltest.Migrate(t, db)
+	urltest.RunSQL(t, db, url.NewSQLiteURLRepository, urltest.DeleteTables)
+}
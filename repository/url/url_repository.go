@@ -2,60 +2,292 @@ package url
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
 	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/outbox"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
+// SQL is the URLRepository of the SQL databases, its queries are written for MySQL and
+// go through sqldb to run on Postgres and SQLite
 type SQL struct {
 	conn *sqlx.DB
 }
 
+// URLRepository stores the links, every change announced to the outside also writes
+// its event to the outbox in the same transaction
 type URLRepository interface {
 	Create(ctx context.Context, req *model.URLEntity) (*model.URLEntity, error)
+	CreateBatch(ctx context.Context, req []*model.URLEntity) ([]*model.URLEntity, error)
 	Update(ctx context.Context, req *model.URLEntity) (*model.URLEntity, error)
+	UpdateBatch(ctx context.Context, req []*model.URLEntity) ([]*model.URLEntity, error)
 	Get(ctx context.Context, filter *model.URLFilter) (*model.URLEntity, error)
+	RecordClick(ctx context.Context, click *model.ClickEntity, writeEvent bool) error
+	Consume(ctx context.Context, id uint64) (bool, error)
+	List(ctx context.Context, filter *model.URLFilter) ([]*model.URLEntity, error)
+	SetCampaign(ctx context.Context, id uint64, campaignID uint64) error
+	Delete(ctx context.Context, id uint64) error
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.URLEntity, error)
+	MarkExpiryNotified(ctx context.Context, id uint64) (bool, error)
 }
 
 func NewURLRepository(conn *sqlx.DB) URLRepository {
 	return &SQL{conn: conn}
 }
 
+// NewPostgresURLRepository is the URLRepository of the Postgres schema (db/migrations/postgres)
+func NewPostgresURLRepository(conn *sqlx.DB) URLRepository {
+	return &SQL{conn: conn}
+}
+
+// NewSQLiteURLRepository is the URLRepository of the SQLite schema (db/migrations/sqlite)
+func NewSQLiteURLRepository(conn *sqlx.DB) URLRepository {
+	return &SQL{conn: conn}
+}
+
 const (
-	insertURLQuery = `INSERT INTO url (user_id, original_url, created_at) VALUES (?, ?, NOW())`
-	updateURLQuery = `UPDATE url SET short_url = ?, original_url = ?, updated_at = NOW() WHERE id = ?`
-	getURLBase     = `SELECT id, user_id, short_url, original_url, created_at, updated_at FROM url WHERE true`
+	insertURLBase          = `INSERT INTO url (user_id, domain_id, campaign_id, original_url, redirect_type, password_hash, single_use, active_from, active_until, schedule, fallback_url, sticky_variant, forward_query, query_conflict, forward_path, created_at) VALUES `
+	insertURLValues        = `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())`
+	insertURLQuery         = insertURLBase + insertURLValues
+	updateURLQuery         = `UPDATE url SET short_url = ?, original_url = ?, redirect_type = ?, updated_at = NOW() WHERE id = ?`
+	getURLBase             = `SELECT id, user_id, domain_id, campaign_id, COALESCE(short_url, '') AS short_url, original_url, redirect_type, status, click_count, password_hash, single_use, consumed_at, active_from, active_until, schedule, fallback_url, sticky_variant, forward_query, query_conflict, forward_path, created_at, updated_at FROM url WHERE true`
+	incrementClickCountURL = `UPDATE url SET click_count = click_count + 1 WHERE id = ?`
+	insertClickQuery       = `INSERT INTO click (url_id, variant_id, created_at) VALUES (?, ?, NOW())`
+	setURLCampaignQuery    = `UPDATE url SET campaign_id = ?, updated_at = NOW() WHERE id = ?`
+	consumeURLQuery        = `UPDATE url SET status = 'consumed', consumed_at = NOW() WHERE id = ? AND status = 'active'`
+	listExpiredURLQuery    = getURLBase + ` AND active_until <= ? AND expiry_notified_at IS NULL ORDER BY active_until LIMIT ?`
+	markExpiryNotifiedURL  = `UPDATE url SET expiry_notified_at = NOW() WHERE id = ? AND expiry_notified_at IS NULL`
+	deleteURLQuery         = `DELETE FROM url WHERE id = ?`
+
+	// the rows belonging to a link are removed before the link itself
+	deleteURLRulesQuery    = `DELETE FROM url_rule WHERE url_id = ?`
+	deleteURLVariantsQuery = `DELETE FROM url_variant WHERE url_id = ?`
+	deleteURLClicksQuery   = `DELETE FROM click WHERE url_id = ?`
+	deleteURLTagsQuery     = `DELETE FROM url_tag WHERE url_id = ?`
+
+	updateShortURLBatchQuery = `UPDATE url SET short_url = CASE id %s END, updated_at = NOW() WHERE id IN (%s)`
+
+	// a batch is inserted with a pending short url per row, its ids are read back by it
+	insertURLBatchBase    = `INSERT INTO url (short_url, user_id, domain_id, campaign_id, original_url, redirect_type, password_hash, single_use, active_from, active_until, schedule, fallback_url, sticky_variant, forward_query, query_conflict, forward_path, created_at) VALUES `
+	insertURLBatchValues  = `(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())`
+	getPendingURLIDsQuery = `SELECT id, short_url FROM url WHERE short_url IN (%s)`
+	clearPendingURLsQuery = `UPDATE url SET short_url = NULL WHERE id IN (%s)`
+	// pendingShortURLPrefix is outside of the base62 alphabet, no real short url starts with it
+	pendingShortURLPrefix = "~"
+
+	// batchChunkSize keeps multi-row statements well below the placeholder limit
+	batchChunkSize = 500
+
+	// mysqlDuplicateEntry is the MySQL error number of a unique key violation
+	mysqlDuplicateEntry = 1062
+	// postgresUniqueViolation is the Postgres error code of a unique key violation
+	postgresUniqueViolation = "23505"
 )
 
+// conflictError reports a unique key violation, such as a short url already taken,
+// as ErrConflict and returns other errors as they are
+func conflictError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if stderrors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return errors.SetCustomError(constant.ErrConflict)
+	}
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) && pqErr.Code == postgresUniqueViolation {
+		return errors.SetCustomError(constant.ErrConflict)
+	}
+	var sqliteErr *sqlite.Error
+	if stderrors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return errors.SetCustomError(constant.ErrConflict)
+	}
+	return err
+}
+
 func (s *SQL) Create(ctx context.Context, data *model.URLEntity) (*model.URLEntity, error) {
-	result, err := s.conn.ExecContext(ctx, insertURLQuery, data.UserID, data.OriginalURL)
+	id, err := sqldb.InsertID(ctx, s.conn, insertURLQuery, sqldb.Args(s.conn, insertURLArgs(data)...)...)
+	if err != nil {
+		return nil, conflictError(err)
+	}
+
+	data.ID = id
+
+	return data, nil
+}
+
+// insertURLArgs returns the arguments matching insertURLValues
+func insertURLArgs(data *model.URLEntity) []any {
+	return []any{
+		data.UserID,
+		data.DomainID,
+		data.CampaignID,
+		data.OriginalURL,
+		data.RedirectType,
+		data.PasswordHash,
+		data.SingleUse,
+		data.ActiveFrom,
+		data.ActiveUntil,
+		data.Schedule,
+		data.FallbackURL,
+		data.StickyVariant,
+		data.ForwardQuery,
+		data.QueryConflict,
+		data.ForwardPath,
+	}
+}
+
+// CreateBatch inserts the urls with multi-row inserts. Each row gets a random pending short
+// url, the ids are read back by it and the pending short urls cleared in the same transaction,
+// so nothing is assumed about how the database hands out ids
+func (s *SQL) CreateBatch(ctx context.Context, data []*model.URLEntity) ([]*model.URLEntity, error) {
+	tx, err := s.conn.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	lastID, err := result.LastInsertId()
+	for start := 0; start < len(data); start += batchChunkSize {
+		chunk := data[start:min(start+batchChunkSize, len(data))]
+
+		pending := make(map[string]*model.URLEntity, len(chunk))
+		codes := make([]any, 0, len(chunk))
+		values := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*16)
+		for _, item := range chunk {
+			code, err := pendingShortURL()
+			if err != nil {
+				return nil, err
+			}
+			pending[code] = item
+			codes = append(codes, code)
+			values = append(values, insertURLBatchValues)
+			args = append(args, code)
+			args = append(args, insertURLArgs(item)...)
+		}
+
+		if _, err := tx.ExecContext(ctx, tx.Rebind(insertURLBatchBase+strings.Join(values, ", ")), sqldb.Args(tx, args...)...); err != nil {
+			return nil, conflictError(err)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(codes)), ", ")
+		var rows []struct {
+			ID       uint64 `db:"id"`
+			ShortURL string `db:"short_url"`
+		}
+		if err := tx.SelectContext(ctx, &rows, tx.Rebind(fmt.Sprintf(getPendingURLIDsQuery, placeholders)), codes...); err != nil {
+			return nil, err
+		}
+		if len(rows) != len(chunk) {
+			return nil, stderrors.New("batch insert read back fewer rows than inserted")
+		}
+
+		ids := make([]any, 0, len(rows))
+		for _, row := range rows {
+			pending[row.ShortURL].ID = row.ID
+			ids = append(ids, row.ID)
+		}
+		if _, err := tx.ExecContext(ctx, tx.Rebind(fmt.Sprintf(clearPendingURLsQuery, placeholders)), ids...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// pendingShortURL returns a random short url marking a row of a batch until its id is known
+func pendingShortURL() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return pendingShortURLPrefix + hex.EncodeToString(b), nil
+}
+
+// UpdateBatch sets the short url of many urls at once, announcing them as created
+func (s *SQL) UpdateBatch(ctx context.Context, data []*model.URLEntity) ([]*model.URLEntity, error) {
+	tx, err := s.conn.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	for start := 0; start < len(data); start += batchChunkSize {
+		chunk := data[start:min(start+batchChunkSize, len(data))]
 
-	data.ID = uint64(lastID)
+		cases := make([]string, 0, len(chunk))
+		ids := make([]string, 0, len(chunk))
+		args := make([]any, 0, len(chunk)*3)
+		for _, item := range chunk {
+			cases = append(cases, "WHEN ? THEN ?")
+			args = append(args, item.ID, item.ShortURL)
+		}
+		for _, item := range chunk {
+			ids = append(ids, "?")
+			args = append(args, item.ID)
+		}
+
+		query := fmt.Sprintf(updateShortURLBatchQuery, strings.Join(cases, " "), strings.Join(ids, ", "))
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+			return nil, conflictError(err)
+		}
+
+		urlIDs := make([]uint64, len(chunk))
+		for i, item := range chunk {
+			urlIDs[i] = item.ID
+		}
+		if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkCreated, urlIDs, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return data, nil
 }
 
+// Update saves the short url of a new link, announcing it as created
 func (s *SQL) Update(ctx context.Context, data *model.URLEntity) (*model.URLEntity, error) {
-	_, err := s.conn.ExecContext(ctx, updateURLQuery, data.ShortURL, data.OriginalURL, data.ID)
+	tx, err := s.conn.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(updateURLQuery), data.ShortURL, data.OriginalURL, data.RedirectType, data.ID); err != nil {
+		return nil, conflictError(err)
+	}
+	if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkCreated, []uint64{data.ID}, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 
 	return data, nil
 }
 
 func (s *SQL) Get(ctx context.Context, filter *model.URLFilter) (*model.URLEntity, error) {
 	query := getURLBase
-	args := make([]any, 0, 2)
+	args := make([]any, 0, 3)
 
 	if filter.ID != 0 {
 		query += " AND id = ?"
@@ -65,9 +297,13 @@ func (s *SQL) Get(ctx context.Context, filter *model.URLFilter) (*model.URLEntit
 		query += " AND short_url = ?"
 		args = append(args, filter.ShortURL)
 	}
+	if filter.DomainID != nil {
+		query += " AND domain_id = ?"
+		args = append(args, *filter.DomainID)
+	}
 
 	var entity model.URLEntity
-	if err := s.conn.QueryRowxContext(ctx, query, args...).StructScan(&entity); err != nil {
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(query), args...).StructScan(&entity); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
@@ -75,3 +311,155 @@ func (s *SQL) Get(ctx context.Context, filter *model.URLFilter) (*model.URLEntit
 	}
 	return &entity, nil
 }
+
+// RecordClick saves the click and counts it on its link. The event is only written with
+// writeEvent and carries the new click count as the row stays locked until the commit.
+func (s *SQL) RecordClick(ctx context.Context, click *model.ClickEntity, writeEvent bool) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(incrementClickCountURL), click.URLID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(insertClickQuery), click.URLID, click.VariantID); err != nil {
+		return err
+	}
+	if !writeEvent {
+		return tx.Commit()
+	}
+	err = outbox.WriteLinkEvents(ctx, tx, constant.EventLinkClicked, []uint64{click.URLID}, func(data *model.LinkEventData) {
+		if click.VariantID != nil {
+			data.VariantID = *click.VariantID
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Consume marks a single use url as consumed, only the first caller gets true
+// and announces the link as expired
+func (s *SQL) Consume(ctx context.Context, id uint64) (bool, error) {
+	return s.expire(ctx, consumeURLQuery, id, constant.ExpiredReasonConsumed)
+}
+
+// expire runs the query marking the link as expired, only the first caller gets true
+// and writes link.expired with the reason
+func (s *SQL) expire(ctx context.Context, query string, id uint64, reason string) (bool, error) {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, nil
+	}
+
+	err = outbox.WriteLinkEvents(ctx, tx, constant.EventLinkExpired, []uint64{id}, func(data *model.LinkEventData) {
+		data.Reason = reason
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// List returns the links matching the filter, newest first
+func (s *SQL) List(ctx context.Context, filter *model.URLFilter) ([]*model.URLEntity, error) {
+	query := getURLBase
+	args := make([]any, 0, 5)
+
+	if filter.DomainID != nil {
+		query += " AND domain_id = ?"
+		args = append(args, *filter.DomainID)
+	}
+	if filter.CampaignID != 0 {
+		query += " AND campaign_id = ?"
+		args = append(args, filter.CampaignID)
+	}
+	if filter.Tag != "" {
+		query += " AND id IN (SELECT url_tag.url_id FROM url_tag JOIN tag ON tag.id = url_tag.tag_id WHERE tag.name = ?)"
+		args = append(args, filter.Tag)
+	}
+
+	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	var entities []*model.URLEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (s *SQL) SetCampaign(ctx context.Context, id uint64, campaignID uint64) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(setURLCampaignQuery), campaignID, id); err != nil {
+		return err
+	}
+	if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkUpdated, []uint64{id}, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the link with its rules, variants, clicks and tags
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the event describes the link as it was before being deleted
+	if err := outbox.WriteLinkEvents(ctx, tx, constant.EventLinkDeleted, []uint64{id}, nil); err != nil {
+		return err
+	}
+
+	for _, query := range []string{deleteURLRulesQuery, deleteURLVariantsQuery, deleteURLClicksQuery, deleteURLTagsQuery, deleteURLQuery} {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListExpired returns the links whose activation window ended by now and were not announced yet
+func (s *SQL) ListExpired(ctx context.Context, now time.Time, limit int) ([]*model.URLEntity, error) {
+	var entities []*model.URLEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listExpiredURLQuery), sqldb.Args(s.conn, now, limit)...); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// MarkExpiryNotified announces the link as expired, only the first caller gets true
+func (s *SQL) MarkExpiryNotified(ctx context.Context, id uint64) (bool, error) {
+	return s.expire(ctx, markExpiryNotifiedURL, id, constant.ExpiredReasonActiveUntil)
+}
@@ -0,0 +1,53 @@
+package urltest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/db/migrations"
+	"github.com/muhammadheryan/url-shortner-base62/repository/tag"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/utils/migrate"
+)
+
+// Tables are the tables written by the contract, emptied before every test of RunSQL
+var Tables = []string{"url", "click", "url_tag", "tag", "url_rule", "url_variant", "outbox"}
+
+// Migrate applies the migrations of the driver of db
+func Migrate(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	fsys, err := migrations.ForDriver(db.DriverName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrate.NewMigrator(db, fsys, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// DeleteTables empties Tables, the reset of the databases that have nothing faster
+func DeleteTables(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	for _, table := range Tables {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// RunSQL runs the contract on db, opened and migrated by the caller. reset empties Tables
+// before every test and newURLRepository returns the url repository of the driver of db.
+func RunSQL(t *testing.T, db *sqlx.DB, newURLRepository func(*sqlx.DB) url.URLRepository, reset func(t *testing.T, db *sqlx.DB)) {
+	RunContract(t, func(t *testing.T) (url.URLRepository, tag.TagRepository) {
+		reset(t, db)
+		return newURLRepository(db), tag.NewTagRepository(db)
+	})
+}
@@ -0,0 +1,436 @@
+// Package urltest is the contract every url.URLRepository implementation has to pass,
+// run it from the tests of an implementation with RunContract.
+package urltest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/repository/tag"
+	"github.com/muhammadheryan/url-shortner-base62/repository/url"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns empty repositories for one test, the tag repository has to share
+// the storage of the url repository so List can filter by tag
+type Factory func(t *testing.T) (url.URLRepository, tag.TagRepository)
+
+// RunContract checks that the repositories returned by newRepositories behave like
+// the SQL implementation
+func RunContract(t *testing.T, newRepositories Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, urls url.URLRepository, tags tag.TagRepository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"GetNotFound", testGetNotFound},
+		{"Update", testUpdate},
+		{"UpdateConflict", testUpdateConflict},
+		{"ShortURLPerDomain", testShortURLPerDomain},
+		{"Batch", testBatch},
+		{"UpdateBatchConflict", testUpdateBatchConflict},
+		{"RecordClick", testRecordClick},
+		{"Consume", testConsume},
+		{"ConsumeConcurrently", testConsumeConcurrently},
+		{"List", testList},
+		{"SetCampaign", testSetCampaign},
+		{"Delete", testDelete},
+		{"Expired", testExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, tags := newRepositories(t)
+			tt.run(t, urls, tags)
+		})
+	}
+}
+
+// create stores a link and gives it a short url derived from its id
+func create(t *testing.T, urls url.URLRepository, data *model.URLEntity) *model.URLEntity {
+	t.Helper()
+	ctx := context.Background()
+
+	created, err := urls.Create(ctx, data)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	created.ShortURL = shortURL(created.ID)
+	_, err = urls.Update(ctx, created)
+	require.NoError(t, err)
+	return created
+}
+
+func shortURL(id uint64) string {
+	return "t" + strconv.FormatUint(id, 10)
+}
+
+func get(t *testing.T, urls url.URLRepository, id uint64) *model.URLEntity {
+	t.Helper()
+
+	entity, err := urls.Get(context.Background(), &model.URLFilter{ID: id})
+	require.NoError(t, err)
+	require.NotNil(t, entity)
+	return entity
+}
+
+func testCreateAndGet(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+
+	created, err := urls.Create(ctx, &model.URLEntity{
+		UserID:       7,
+		OriginalURL:  "https://example.com/a",
+		RedirectType: 301,
+		SingleUse:    true,
+		FallbackURL:  "https://example.com/fallback",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	entity := get(t, urls, created.ID)
+	assert.Equal(t, created.ID, entity.ID)
+	assert.Equal(t, uint64(7), entity.UserID)
+	assert.Equal(t, "https://example.com/a", entity.OriginalURL)
+	assert.Equal(t, 301, entity.RedirectType)
+	assert.True(t, entity.SingleUse)
+	assert.Equal(t, "https://example.com/fallback", entity.FallbackURL)
+	assert.Equal(t, "", entity.ShortURL)
+	assert.Equal(t, constant.URLStatusActive, entity.Status)
+	assert.Zero(t, entity.ClickCount)
+	assert.False(t, entity.CreatedAt.IsZero())
+	assert.Nil(t, entity.UpdatedAt)
+
+	second, err := urls.Create(ctx, &model.URLEntity{OriginalURL: "https://example.com/b"})
+	require.NoError(t, err)
+	assert.Greater(t, second.ID, created.ID)
+}
+
+func testGetNotFound(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+
+	entity, err := urls.Get(ctx, &model.URLFilter{ID: 12345})
+	assert.NoError(t, err)
+	assert.Nil(t, entity)
+
+	entity, err = urls.Get(ctx, &model.URLFilter{ShortURL: "missing"})
+	assert.NoError(t, err)
+	assert.Nil(t, entity)
+}
+
+func testUpdate(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	created := create(t, urls, &model.URLEntity{DomainID: 3, OriginalURL: "https://example.com/a"})
+
+	created.OriginalURL = "https://example.com/b"
+	created.RedirectType = 307
+	_, err := urls.Update(ctx, created)
+	require.NoError(t, err)
+
+	entity, err := urls.Get(ctx, &model.URLFilter{ShortURL: created.ShortURL})
+	require.NoError(t, err)
+	require.NotNil(t, entity)
+	assert.Equal(t, created.ID, entity.ID)
+	assert.Equal(t, "https://example.com/b", entity.OriginalURL)
+	assert.Equal(t, 307, entity.RedirectType)
+	assert.NotNil(t, entity.UpdatedAt)
+
+	domainID := uint64(3)
+	entity, err = urls.Get(ctx, &model.URLFilter{ShortURL: created.ShortURL, DomainID: &domainID})
+	require.NoError(t, err)
+	require.NotNil(t, entity)
+	assert.Equal(t, created.ID, entity.ID)
+
+	otherDomainID := uint64(0)
+	entity, err = urls.Get(ctx, &model.URLFilter{ShortURL: created.ShortURL, DomainID: &otherDomainID})
+	assert.NoError(t, err)
+	assert.Nil(t, entity)
+}
+
+func testUpdateConflict(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	first := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a"})
+	second := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/b"})
+
+	taken := *second
+	taken.ShortURL = first.ShortURL
+	_, err := urls.Update(ctx, &taken)
+	assert.True(t, errors.Is(err, constant.ErrConflict), "got %v", err)
+
+	assert.Equal(t, second.ShortURL, get(t, urls, second.ID).ShortURL)
+
+	// updating a link with its own short url is no conflict
+	_, err = urls.Update(ctx, first)
+	assert.NoError(t, err)
+}
+
+func testShortURLPerDomain(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	first := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a"})
+	second := create(t, urls, &model.URLEntity{DomainID: 7, OriginalURL: "https://example.com/b"})
+
+	// the same code is free on another domain
+	second.ShortURL = first.ShortURL
+	_, err := urls.Update(ctx, second)
+	require.NoError(t, err)
+
+	defaultDomainID, customDomainID := uint64(0), uint64(7)
+	entity, err := urls.Get(ctx, &model.URLFilter{ShortURL: first.ShortURL, DomainID: &defaultDomainID})
+	require.NoError(t, err)
+	require.NotNil(t, entity)
+	assert.Equal(t, first.ID, entity.ID)
+
+	entity, err = urls.Get(ctx, &model.URLFilter{ShortURL: first.ShortURL, DomainID: &customDomainID})
+	require.NoError(t, err)
+	require.NotNil(t, entity)
+	assert.Equal(t, second.ID, entity.ID)
+
+	// but still taken on the same domain
+	third := create(t, urls, &model.URLEntity{DomainID: 7, OriginalURL: "https://example.com/c"})
+	third.ShortURL = first.ShortURL
+	_, err = urls.Update(ctx, third)
+	assert.True(t, errors.Is(err, constant.ErrConflict), "got %v", err)
+}
+
+func testBatch(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+
+	created, err := urls.CreateBatch(ctx, []*model.URLEntity{
+		{OriginalURL: "https://example.com/a"},
+		{OriginalURL: "https://example.com/b"},
+		{OriginalURL: "https://example.com/c"},
+	})
+	require.NoError(t, err)
+	require.Len(t, created, 3)
+	seen := map[uint64]bool{}
+	for _, item := range created {
+		require.NotZero(t, item.ID)
+		assert.False(t, seen[item.ID], "id %d given twice", item.ID)
+		seen[item.ID] = true
+		// the id points at the row of the item, whatever ids the database hands out
+		entity := get(t, urls, item.ID)
+		assert.Equal(t, item.OriginalURL, entity.OriginalURL)
+		assert.Empty(t, entity.ShortURL)
+		item.ShortURL = shortURL(item.ID)
+	}
+
+	_, err = urls.UpdateBatch(ctx, created)
+	require.NoError(t, err)
+
+	for _, item := range created {
+		entity, err := urls.Get(ctx, &model.URLFilter{ShortURL: item.ShortURL})
+		require.NoError(t, err)
+		require.NotNil(t, entity)
+		assert.Equal(t, item.ID, entity.ID)
+		assert.Equal(t, item.OriginalURL, entity.OriginalURL)
+	}
+}
+
+func testUpdateBatchConflict(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	existing := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a"})
+
+	created, err := urls.CreateBatch(ctx, []*model.URLEntity{
+		{OriginalURL: "https://example.com/b"},
+		{OriginalURL: "https://example.com/c"},
+	})
+	require.NoError(t, err)
+	created[0].ShortURL = shortURL(created[0].ID)
+	created[1].ShortURL = existing.ShortURL
+
+	_, err = urls.UpdateBatch(ctx, created)
+	assert.True(t, errors.Is(err, constant.ErrConflict), "got %v", err)
+
+	// none of the links got its short url
+	assert.Equal(t, "", get(t, urls, created[0].ID).ShortURL)
+	assert.Equal(t, "", get(t, urls, created[1].ID).ShortURL)
+}
+
+func testRecordClick(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	created := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a"})
+
+	require.NoError(t, urls.RecordClick(ctx, &model.ClickEntity{URLID: created.ID}, true))
+	require.NoError(t, urls.RecordClick(ctx, &model.ClickEntity{URLID: created.ID}, true))
+
+	assert.Equal(t, uint64(2), get(t, urls, created.ID).ClickCount)
+}
+
+func testConsume(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	created := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a", SingleUse: true})
+
+	consumed, err := urls.Consume(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, consumed)
+
+	consumed, err = urls.Consume(ctx, created.ID)
+	require.NoError(t, err)
+	assert.False(t, consumed)
+
+	entity := get(t, urls, created.ID)
+	assert.Equal(t, constant.URLStatusConsumed, entity.Status)
+	assert.NotNil(t, entity.ConsumedAt)
+
+	consumed, err = urls.Consume(ctx, 12345)
+	require.NoError(t, err)
+	assert.False(t, consumed)
+}
+
+// testConsumeConcurrently checks that a single use link is consumed by one caller only
+func testConsumeConcurrently(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	created := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a", SingleUse: true})
+
+	var wg sync.WaitGroup
+	results := make(chan bool, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			consumed, err := urls.Consume(ctx, created.ID)
+			assert.NoError(t, err)
+			results <- consumed
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	winners := 0
+	for consumed := range results {
+		if consumed {
+			winners++
+		}
+	}
+	assert.Equal(t, 1, winners)
+}
+
+func testList(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	first := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a", CampaignID: 1})
+	second := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/b", CampaignID: 1, DomainID: 2})
+	third := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/c"})
+
+	promo, err := tags.Create(ctx, &model.TagEntity{Name: "promo"})
+	require.NoError(t, err)
+	require.NoError(t, tags.SetURLTags(ctx, first.ID, []uint64{promo.ID}))
+	require.NoError(t, tags.SetURLTags(ctx, third.ID, []uint64{promo.ID}))
+
+	ids := func(entities []*model.URLEntity) []uint64 {
+		result := []uint64{}
+		for _, entity := range entities {
+			result = append(result, entity.ID)
+		}
+		return result
+	}
+
+	entities, err := urls.List(ctx, &model.URLFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{third.ID, second.ID, first.ID}, ids(entities))
+
+	entities, err = urls.List(ctx, &model.URLFilter{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{second.ID}, ids(entities))
+
+	entities, err = urls.List(ctx, &model.URLFilter{CampaignID: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{second.ID, first.ID}, ids(entities))
+
+	entities, err = urls.List(ctx, &model.URLFilter{Tag: "promo", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{third.ID, first.ID}, ids(entities))
+
+	domainID := uint64(2)
+	entities, err = urls.List(ctx, &model.URLFilter{DomainID: &domainID, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{second.ID}, ids(entities))
+
+	entities, err = urls.List(ctx, &model.URLFilter{Tag: "missing", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, entities)
+}
+
+func testSetCampaign(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	created := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a"})
+
+	require.NoError(t, urls.SetCampaign(ctx, created.ID, 5))
+	assert.Equal(t, uint64(5), get(t, urls, created.ID).CampaignID)
+
+	require.NoError(t, urls.SetCampaign(ctx, created.ID, 0))
+	assert.Zero(t, get(t, urls, created.ID).CampaignID)
+}
+
+func testDelete(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	created := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a"})
+	kept := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/b"})
+
+	promo, err := tags.Create(ctx, &model.TagEntity{Name: "promo"})
+	require.NoError(t, err)
+	require.NoError(t, tags.SetURLTags(ctx, created.ID, []uint64{promo.ID}))
+	require.NoError(t, urls.RecordClick(ctx, &model.ClickEntity{URLID: created.ID}, true))
+
+	require.NoError(t, urls.Delete(ctx, created.ID))
+
+	entity, err := urls.Get(ctx, &model.URLFilter{ID: created.ID})
+	assert.NoError(t, err)
+	assert.Nil(t, entity)
+
+	var defaultDomain uint64
+	entity, err = urls.Get(ctx, &model.URLFilter{ShortURL: created.ShortURL, DomainID: &defaultDomain})
+	assert.NoError(t, err)
+	assert.Nil(t, entity)
+
+	urlTags, err := tags.ListByURLs(ctx, []uint64{created.ID})
+	require.NoError(t, err)
+	assert.Empty(t, urlTags)
+
+	assert.NotNil(t, get(t, urls, kept.ID))
+
+	// deleting a missing link is no error
+	assert.NoError(t, urls.Delete(ctx, created.ID))
+}
+
+func testExpired(t *testing.T, urls url.URLRepository, tags tag.TagRepository) {
+	ctx := context.Background()
+	now := time.Now()
+	longAgo := now.Add(-48 * time.Hour)
+	recently := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+
+	recent := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/a", ActiveUntil: &recently})
+	old := create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/b", ActiveUntil: &longAgo})
+	create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/c", ActiveUntil: &later})
+	create(t, urls, &model.URLEntity{OriginalURL: "https://example.com/d"})
+
+	entities, err := urls.ListExpired(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, entities, 2)
+	assert.Equal(t, old.ID, entities[0].ID)
+	assert.Equal(t, recent.ID, entities[1].ID)
+
+	entities, err = urls.ListExpired(ctx, now, 1)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	assert.Equal(t, old.ID, entities[0].ID)
+
+	marked, err := urls.MarkExpiryNotified(ctx, old.ID)
+	require.NoError(t, err)
+	assert.True(t, marked)
+
+	marked, err = urls.MarkExpiryNotified(ctx, old.ID)
+	require.NoError(t, err)
+	assert.False(t, marked)
+
+	entities, err = urls.ListExpired(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+	assert.Equal(t, recent.ID, entities[0].ID)
+}
@@ -0,0 +1,67 @@
+package variant
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Memory keeps the variants in memory for running the service without a database
+type Memory struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	variants []*model.VariantEntity
+}
+
+func NewMemoryVariantRepository() *Memory {
+	return &Memory{}
+}
+
+// CreateBatch stores the variants with consecutive ids
+func (m *Memory) CreateBatch(ctx context.Context, data []*model.VariantEntity) ([]*model.VariantEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, variant := range data {
+		m.nextID++
+		variant.ID = m.nextID
+
+		entity := *variant
+		entity.CreatedAt = now
+		entity.UpdatedAt = nil
+		m.variants = append(m.variants, &entity)
+	}
+	return data, nil
+}
+
+// List returns the variants of a link by id
+func (m *Memory) List(ctx context.Context, urlID uint64) ([]*model.VariantEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var entities []*model.VariantEntity
+	for _, stored := range m.variants {
+		if stored.URLID == urlID {
+			entity := *stored
+			entities = append(entities, &entity)
+		}
+	}
+	return entities, nil
+}
+
+// DeleteByURL removes the variants of a deleted link
+func (m *Memory) DeleteByURL(urlID uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.variants[:0]
+	for _, stored := range m.variants {
+		if stored.URLID != urlID {
+			kept = append(kept, stored)
+		}
+	}
+	m.variants = kept
+}
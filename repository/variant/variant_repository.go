@@ -0,0 +1,64 @@
+package variant
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+type VariantRepository interface {
+	CreateBatch(ctx context.Context, req []*model.VariantEntity) ([]*model.VariantEntity, error)
+	List(ctx context.Context, urlID uint64) ([]*model.VariantEntity, error)
+}
+
+func NewVariantRepository(conn *sqlx.DB) VariantRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertVariantQuery = `INSERT INTO url_variant (url_id, destination_url, weight, created_at) VALUES (?, ?, ?, NOW())`
+	listVariantQuery   = `SELECT id, url_id, destination_url, weight, created_at, updated_at FROM url_variant WHERE url_id = ? ORDER BY id`
+)
+
+// CreateBatch inserts the variants one row at a time in a transaction, so each id is
+// the one the database returned for its row. The variants have no natural key to read
+// the ids of a multi-row insert back by
+func (s *SQL) CreateBatch(ctx context.Context, data []*model.VariantEntity) ([]*model.VariantEntity, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, variant := range data {
+		id, err := sqldb.InsertID(ctx, tx, insertVariantQuery, variant.URLID, variant.DestinationURL, variant.Weight)
+		if err != nil {
+			return nil, err
+		}
+		variant.ID = id
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (s *SQL) List(ctx context.Context, urlID uint64) ([]*model.VariantEntity, error) {
+	var entities []*model.VariantEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listVariantQuery), urlID); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// Memory keeps the webhooks in memory for running the service without a database. No
+// deliveries are made since there is no outbox, the endpoints are only registered.
+type Memory struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	webhooks map[uint64]*model.WebhookEntity
+}
+
+func NewMemoryWebhookRepository() *Memory {
+	return &Memory{webhooks: make(map[uint64]*model.WebhookEntity)}
+}
+
+func (m *Memory) Create(ctx context.Context, data *model.WebhookEntity) (*model.WebhookEntity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	data.ID = m.nextID
+
+	entity := *data
+	entity.CreatedAt = time.Now()
+	m.webhooks[entity.ID] = &entity
+
+	return data, nil
+}
+
+func (m *Memory) Get(ctx context.Context, id uint64) (*model.WebhookEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.webhooks[id]
+	if !ok {
+		return nil, nil
+	}
+	entity := *stored
+	return &entity, nil
+}
+
+// List returns the webhooks by id
+func (m *Memory) List(ctx context.Context) ([]*model.WebhookEntity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entities := make([]*model.WebhookEntity, 0, len(m.webhooks))
+	for id := uint64(1); id <= m.nextID; id++ {
+		if stored, ok := m.webhooks[id]; ok {
+			entity := *stored
+			entities = append(entities, &entity)
+		}
+	}
+	return entities, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.webhooks, id)
+	return nil
+}
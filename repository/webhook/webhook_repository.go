@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/sqldb"
+)
+
+type SQL struct {
+	conn *sqlx.DB
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, req *model.WebhookEntity) (*model.WebhookEntity, error)
+	Get(ctx context.Context, id uint64) (*model.WebhookEntity, error)
+	List(ctx context.Context) ([]*model.WebhookEntity, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+func NewWebhookRepository(conn *sqlx.DB) WebhookRepository {
+	return &SQL{conn: conn}
+}
+
+const (
+	insertWebhookQuery           = `INSERT INTO webhook (url, secret, events, click_thresholds, created_at) VALUES (?, ?, ?, ?, NOW())`
+	getWebhookBase               = `SELECT id, url, secret, events, click_thresholds, created_at FROM webhook WHERE true`
+	getWebhookQuery              = getWebhookBase + ` AND id = ?`
+	listWebhookQuery             = getWebhookBase + ` ORDER BY id`
+	deleteWebhookQuery           = `DELETE FROM webhook WHERE id = ?`
+	deleteWebhookDeliveriesQuery = `DELETE FROM webhook_delivery WHERE webhook_id = ?`
+	deleteWebhookDeadLetterQuery = `DELETE FROM webhook_dead_letter WHERE webhook_id = ?`
+)
+
+func (s *SQL) Create(ctx context.Context, data *model.WebhookEntity) (*model.WebhookEntity, error) {
+	id, err := sqldb.InsertID(ctx, s.conn, insertWebhookQuery, data.URL, data.Secret, data.Events, data.ClickThresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	data.ID = id
+
+	return data, nil
+}
+
+func (s *SQL) Get(ctx context.Context, id uint64) (*model.WebhookEntity, error) {
+	var entity model.WebhookEntity
+	if err := s.conn.QueryRowxContext(ctx, s.conn.Rebind(getWebhookQuery), id).StructScan(&entity); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (s *SQL) List(ctx context.Context) ([]*model.WebhookEntity, error) {
+	var entities []*model.WebhookEntity
+	if err := s.conn.SelectContext(ctx, &entities, s.conn.Rebind(listWebhookQuery)); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Delete removes the webhook with its pending and dead deliveries
+func (s *SQL) Delete(ctx context.Context, id uint64) error {
+	tx, err := s.conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{deleteWebhookDeliveriesQuery, deleteWebhookDeadLetterQuery, deleteWebhookQuery} {
+		if _, err := tx.ExecContext(ctx, tx.Rebind(query), id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
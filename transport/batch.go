@@ -0,0 +1,75 @@
+package transport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/muhammadheryan/url-shortner-base62/model"
+)
+
+// maxBatchBodySize limits the size of a batch request body or CSV upload
+const maxBatchBodySize = 10 << 20
+
+var errInvalidCSV = errors.New("invalid csv row")
+
+// decodeBatchRequest reads the batch items from a JSON array, a CSV body or a multipart CSV upload
+func decodeBatchRequest(r *http.Request) (*model.CreateURLShortnerBatchRequest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "multipart/form-data":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return decodeBatchCSV(file)
+	case "text/csv":
+		return decodeBatchCSV(r.Body)
+	default:
+		var items []model.CreateURLShortnerRequest
+		if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+			return nil, err
+		}
+		return &model.CreateURLShortnerBatchRequest{Items: items}, nil
+	}
+}
+
+// decodeBatchCSV reads rows of original_url and an optional redirect_type, a header row is skipped
+func decodeBatchCSV(r io.Reader) (*model.CreateURLShortnerBatchRequest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	req := &model.CreateURLShortnerBatchRequest{}
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "original_url") {
+			continue
+		}
+
+		item := model.CreateURLShortnerRequest{OriginalURL: strings.TrimSpace(record[0])}
+		if len(record) > 1 && strings.TrimSpace(record[1]) != "" {
+			redirectType, err := strconv.Atoi(strings.TrimSpace(record[1]))
+			if err != nil {
+				return nil, errInvalidCSV
+			}
+			item.RedirectType = redirectType
+		}
+		req.Items = append(req.Items, item)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// @Summary Create campaign
+// @Description Create a campaign grouping short URLs, every link belongs to at most one campaign
+// @Accept json
+// @Produce json
+// @Param request body model.UpsertCampaignRequest true "Campaign"
+// @Success 200 {object} model.GetCampaignResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /campaign [post]
+func (s *RestHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	var req model.UpsertCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.CampaignApp.CreateCampaign(r.Context(), &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary List campaigns
+// @Produce json
+// @Success 200 {array} model.GetCampaignResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /campaign [get]
+func (s *RestHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	data, err := s.CampaignApp.ListCampaigns(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Get campaign
+// @Produce json
+// @Param campaignID path int true "Campaign ID"
+// @Success 200 {object} model.GetCampaignResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /campaign/{campaignID} [get]
+func (s *RestHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(mux.Vars(r)["campaignID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.CampaignApp.GetCampaign(r.Context(), campaignID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Update campaign
+// @Accept json
+// @Produce json
+// @Param campaignID path int true "Campaign ID"
+// @Param request body model.UpsertCampaignRequest true "Campaign"
+// @Success 200 {object} model.GetCampaignResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /campaign/{campaignID} [put]
+func (s *RestHandler) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(mux.Vars(r)["campaignID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	var req model.UpsertCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.CampaignApp.UpdateCampaign(r.Context(), campaignID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Delete campaign
+// @Description Delete a campaign, its short URLs are kept without a campaign
+// @Produce json
+// @Param campaignID path int true "Campaign ID"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /campaign/{campaignID} [delete]
+func (s *RestHandler) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(mux.Vars(r)["campaignID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.CampaignApp.DeleteCampaign(r.Context(), campaignID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
+
+// @Summary Get campaign click stats
+// @Description Get the number of short URLs of a campaign and their total clicks
+// @Produce json
+// @Param campaignID path int true "Campaign ID"
+// @Success 200 {object} model.GetCampaignStatsResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /campaign/{campaignID}/stats [get]
+func (s *RestHandler) GetCampaignStats(w http.ResponseWriter, r *http.Request) {
+	campaignID, err := strconv.ParseUint(mux.Vars(r)["campaignID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.CampaignApp.GetCampaignStats(r.Context(), campaignID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Set short URL campaign
+// @Description Move a short URL to a campaign, campaign_id 0 removes it from its campaign
+// @Accept json
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Param request body model.SetURLCampaignRequest true "Campaign"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/campaign [put]
+func (s *RestHandler) SetURLCampaign(w http.ResponseWriter, r *http.Request) {
+	var req model.SetURLCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.URLApp.SetURLCampaign(r.Context(), s.requestHost(r), mux.Vars(r)["shortURL"], &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
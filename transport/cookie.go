@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unlockCookiePrefix is followed by the short url, one cookie per unlocked link
+const unlockCookiePrefix = "unlock_"
+
+// variantCookiePrefix is followed by the short url, it holds the variant served to the visitor
+const variantCookiePrefix = "variant_"
+
+// setUnlockCookie remembers that the visitor entered the password of the link
+func (s *RestHandler) setUnlockCookie(w http.ResponseWriter, r *http.Request, shortURL string) {
+	expiresAt := time.Now().Add(s.Config.Server.PasswordCookieTTL)
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     unlockCookiePrefix + shortURL,
+		Value:    expiry + "." + s.signUnlock(shortURL, expiry),
+		Path:     "/url/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// isUnlocked checks if the request carries a valid, unexpired unlock cookie for the link
+func (s *RestHandler) isUnlocked(r *http.Request, shortURL string) bool {
+	cookie, err := r.Cookie(unlockCookiePrefix + shortURL)
+	if err != nil {
+		return false
+	}
+
+	expiry, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(s.signUnlock(shortURL, expiry)))
+}
+
+func (s *RestHandler) signUnlock(shortURL, expiry string) string {
+	mac := hmac.New(sha256.New, s.cookieSecret)
+	mac.Write([]byte(shortURL + "|" + expiry))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// setVariantCookie remembers the variant of a sticky split link served to the visitor
+func (s *RestHandler) setVariantCookie(w http.ResponseWriter, r *http.Request, shortURL string, variantID uint64) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     variantCookiePrefix + shortURL,
+		Value:    strconv.FormatUint(variantID, 10),
+		Path:     "/url/",
+		MaxAge:   int(s.Config.Server.VariantCookieTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// stickyVariant returns the variant previously served to the visitor, 0 when unknown
+func stickyVariant(r *http.Request, shortURL string) uint64 {
+	cookie, err := r.Cookie(variantCookiePrefix + shortURL)
+	if err != nil {
+		return 0
+	}
+
+	variantID, err := strconv.ParseUint(cookie.Value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return variantID
+}
+
+// requestHost returns the host the visit was sent to, X-Forwarded-Host is only
+// used when the proxy headers are trusted
+func (s *RestHandler) requestHost(r *http.Request) string {
+	if s.Config.Server.TrustProxyHeaders {
+		if forwarded := s.forwardedValue(r, "X-Forwarded-Host"); forwarded != "" {
+			return forwarded
+		}
+	}
+	return r.Host
+}
+
+// clientIP returns the address of the visitor, X-Forwarded-For is only used
+// when the proxy headers are trusted
+func (s *RestHandler) clientIP(r *http.Request) string {
+	if s.Config.Server.TrustProxyHeaders {
+		if forwarded := s.forwardedValue(r, "X-Forwarded-For"); forwarded != "" {
+			return forwarded
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedValue returns the entry of a forwarded header appended by the outermost trusted
+// proxy. Each proxy appends to the right, the entries on the left are sent by the client
+// and can't be trusted, so the entry is counted from the right by the number of proxies.
+func (s *RestHandler) forwardedValue(r *http.Request, name string) string {
+	var entries []string
+	for _, value := range r.Header.Values(name) {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+
+	index := len(entries) - s.Config.Server.TrustedProxyHops
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(entries) {
+		index = len(entries) - 1
+	}
+	return entries[index]
+}
@@ -0,0 +1,127 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+)
+
+func newCookieTestHandler(trustProxy bool, hops int) *RestHandler {
+	return &RestHandler{
+		Config: &config.Config{Server: config.ServerConfig{
+			TrustProxyHeaders: trustProxy,
+			TrustedProxyHops:  hops,
+		}},
+		cookieSecret: []byte("test-secret"),
+	}
+}
+
+func TestRestHandler_clientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		hops       int
+		forwarded  []string
+		want       string
+	}{
+		{name: "proxy headers not trusted", forwarded: []string{"203.0.113.7"}, want: "192.0.2.1"},
+		{name: "single proxy", trustProxy: true, hops: 1, forwarded: []string{"198.51.100.9"}, want: "198.51.100.9"},
+		{name: "spoofed entry on the left is ignored", trustProxy: true, hops: 1, forwarded: []string{"203.0.113.7, 198.51.100.9"}, want: "198.51.100.9"},
+		{name: "two proxies", trustProxy: true, hops: 2, forwarded: []string{"203.0.113.7, 198.51.100.9, 10.0.0.2"}, want: "198.51.100.9"},
+		{name: "entries over several headers", trustProxy: true, hops: 1, forwarded: []string{"203.0.113.7", "198.51.100.9"}, want: "198.51.100.9"},
+		{name: "fewer entries than proxies", trustProxy: true, hops: 3, forwarded: []string{"198.51.100.9"}, want: "198.51.100.9"},
+		{name: "no header", trustProxy: true, hops: 1, want: "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/url/0000C", nil)
+			r.RemoteAddr = "192.0.2.1:52000"
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := newCookieTestHandler(tt.trustProxy, tt.hops).clientIP(r); got != tt.want {
+				t.Fatalf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestHandler_requestHost(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/url/0000C", nil)
+	r.Host = "internal:8080"
+	r.Header.Set("X-Forwarded-Host", "evil.example, go.example.com")
+
+	if got := newCookieTestHandler(false, 1).requestHost(r); got != "internal:8080" {
+		t.Fatalf("requestHost() = %s, want the host of the request when proxy headers are not trusted", got)
+	}
+	if got := newCookieTestHandler(true, 1).requestHost(r); got != "go.example.com" {
+		t.Fatalf("requestHost() = %s, want the host appended by the proxy", got)
+	}
+}
+
+// unlockCookie returns the unlock cookie the handler sets for shortURL
+func unlockCookie(t *testing.T, s *RestHandler, shortURL string) *http.Cookie {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	s.setUnlockCookie(rec, httptest.NewRequest(http.MethodPost, "/url/"+shortURL+"/unlock", nil), shortURL)
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("setUnlockCookie() set %d cookies, want 1", len(cookies))
+	}
+	return cookies[0]
+}
+
+func TestRestHandler_isUnlocked(t *testing.T) {
+	s := newCookieTestHandler(false, 1)
+	s.Config.Server.PasswordCookieTTL = time.Hour
+	cookie := unlockCookie(t, s, "0000C")
+	if cookie.Name != "unlock_0000C" || !cookie.HttpOnly || cookie.Path != "/url/" {
+		t.Fatalf("unlock cookie = %+v", cookie)
+	}
+	expiry, signature, _ := strings.Cut(cookie.Value, ".")
+	tampered := signature[:len(signature)-1] + "A"
+	if tampered == signature {
+		tampered = signature[:len(signature)-1] + "B"
+	}
+
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10)
+	otherSecret := newCookieTestHandler(false, 1)
+	otherSecret.cookieSecret = []byte("other-secret")
+
+	tests := []struct {
+		name     string
+		shortURL string
+		cookie   *http.Cookie
+		want     bool
+	}{
+		{name: "signed cookie", shortURL: "0000C", cookie: cookie, want: true},
+		{name: "no cookie", shortURL: "0000C"},
+		{name: "cookie of another link", shortURL: "0000D", cookie: &http.Cookie{Name: "unlock_0000D", Value: cookie.Value}},
+		{name: "tampered signature", shortURL: "0000C", cookie: &http.Cookie{Name: cookie.Name, Value: expiry + "." + tampered}},
+		{name: "extended expiry", shortURL: "0000C", cookie: &http.Cookie{Name: cookie.Name, Value: future + "." + signature}},
+		{name: "expired", shortURL: "0000C", cookie: &http.Cookie{Name: cookie.Name, Value: past + "." + s.signUnlock("0000C", past)}},
+		{name: "signed with another secret", shortURL: "0000C", cookie: &http.Cookie{Name: cookie.Name, Value: expiry + "." + otherSecret.signUnlock("0000C", expiry)}},
+		{name: "no signature", shortURL: "0000C", cookie: &http.Cookie{Name: cookie.Name, Value: expiry}},
+		{name: "expiry not a number", shortURL: "0000C", cookie: &http.Cookie{Name: cookie.Name, Value: "soon." + s.signUnlock("0000C", "soon")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/url/"+tt.shortURL, nil)
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+
+			if got := s.isUnlocked(r, tt.shortURL); got != tt.want {
+				t.Fatalf("isUnlocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
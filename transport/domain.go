@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// @Summary Register custom domain
+// @Description Register a short domain, serve the returned verification token at its verification url then verify it
+// @Accept json
+// @Produce json
+// @Param request body model.CreateDomainRequest true "Domain"
+// @Success 200 {object} model.GetDomainResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /domain [post]
+func (s *RestHandler) CreateDomain(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.DomainApp.CreateDomain(r.Context(), &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary List custom domains
+// @Produce json
+// @Success 200 {array} model.GetDomainResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /domain [get]
+func (s *RestHandler) ListDomains(w http.ResponseWriter, r *http.Request) {
+	data, err := s.DomainApp.ListDomains(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Get custom domain
+// @Produce json
+// @Param domainID path int true "Domain ID"
+// @Success 200 {object} model.GetDomainResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /domain/{domainID} [get]
+func (s *RestHandler) GetDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := strconv.ParseUint(mux.Vars(r)["domainID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.DomainApp.GetDomain(r.Context(), domainID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Verify custom domain
+// @Description Fetch the verification token served on the domain, links can only be created on verified domains
+// @Produce json
+// @Param domainID path int true "Domain ID"
+// @Success 200 {object} model.GetDomainResponse
+// @Failure 400 {object} errors.CustomError
+// @Failure 422 {object} errors.CustomError
+// @Router /domain/{domainID}/verify [post]
+func (s *RestHandler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := strconv.ParseUint(mux.Vars(r)["domainID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.DomainApp.VerifyDomain(r.Context(), domainID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Delete custom domain
+// @Produce json
+// @Param domainID path int true "Domain ID"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Failure 409 {object} errors.CustomError
+// @Router /domain/{domainID} [delete]
+func (s *RestHandler) DeleteDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := strconv.ParseUint(mux.Vars(r)["domainID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.DomainApp.DeleteDomain(r.Context(), domainID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
@@ -0,0 +1,324 @@
+package transport
+
+import (
+	"context"
+	"io"
+	"log"
+	neturl "net/url"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	urlv1 "github.com/muhammadheryan/url-shortner-base62/proto/url/v1"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type GRPCHandler struct {
+	urlv1.UnimplementedURLServiceServer
+
+	URLApp url.URLApp
+	Config *config.Config
+}
+
+// NewGRPCServer serves the links over gRPC with the same application layer as the REST API
+func NewGRPCServer(URLApp url.URLApp, cfg *config.Config) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(unaryErrorInterceptor), grpc.StreamInterceptor(streamErrorInterceptor))
+
+	urlv1.RegisterURLServiceServer(server, &GRPCHandler{
+		URLApp: URLApp,
+		Config: cfg,
+	})
+	reflection.Register(server)
+
+	return server
+}
+
+// grpcError turns the errors of the application layer into a status with the error code
+// of the REST API, unknown errors are reported as internal errors
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	customError, ok := err.(errors.CustomError)
+	if !ok {
+		log.Println("[grpc] err", err)
+		customError = errors.SetCustomError(constant.ErrInternal)
+	}
+	return customError.GRPCStatus().Err()
+}
+
+func unaryErrorInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	return resp, grpcError(err)
+}
+
+func streamErrorInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return grpcError(handler(srv, ss))
+}
+
+func (s *GRPCHandler) CreateShortURL(ctx context.Context, req *urlv1.CreateShortURLRequest) (*urlv1.URL, error) {
+	data, err := s.URLApp.CreateURLShortner(ctx, fromCreateShortURLRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return toURL(data), nil
+}
+
+// GetURL returns the link on the requested domain without counting a click, gRPC clients
+// can't unlock a password protected link so its destination is never shown
+func (s *GRPCHandler) GetURL(ctx context.Context, req *urlv1.GetURLRequest) (*urlv1.URLInfo, error) {
+	if req.GetShortUrl() == "" {
+		return nil, errors.SetCustomError(constant.ErrInvalidRequest)
+	}
+
+	data, err := s.URLApp.GetURLInfo(ctx, req.GetDomain(), req.GetShortUrl())
+	if err != nil {
+		return nil, err
+	}
+
+	return toURLInfo(data), nil
+}
+
+func (s *GRPCHandler) ListURLs(ctx context.Context, req *urlv1.ListURLsRequest) (*urlv1.ListURLsResponse, error) {
+	data, err := s.URLApp.ListURLs(ctx, &model.ListURLRequest{
+		CampaignID: req.GetCampaignId(),
+		Tag:        req.GetTag(),
+		Limit:      int(req.GetLimit()),
+		Offset:     int(req.GetOffset()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &urlv1.ListURLsResponse{
+		Items:  make([]*urlv1.URLInfo, 0, len(data.Items)),
+		Limit:  int32(data.Limit),
+		Offset: int32(data.Offset),
+	}
+	for _, item := range data.Items {
+		resp.Items = append(resp.Items, toURLInfo(item))
+	}
+
+	return resp, nil
+}
+
+// DeleteURL deletes the link on the requested domain
+func (s *GRPCHandler) DeleteURL(ctx context.Context, req *urlv1.DeleteURLRequest) (*urlv1.DeleteURLResponse, error) {
+	if err := s.URLApp.DeleteURL(ctx, req.GetDomain(), req.GetShortUrl()); err != nil {
+		return nil, err
+	}
+
+	return &urlv1.DeleteURLResponse{}, nil
+}
+
+// Resolve answers every request in order until the client closes its side of the stream,
+// the errors of a lookup are sent in its response so one bad link doesn't end the batch
+func (s *GRPCHandler) Resolve(stream urlv1.URLService_ResolveServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(s.resolve(stream.Context(), req)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *GRPCHandler) resolve(ctx context.Context, req *urlv1.ResolveRequest) *urlv1.ResolveResponse {
+	resp := &urlv1.ResolveResponse{ShortUrl: req.GetShortUrl()}
+
+	query, err := neturl.ParseQuery(req.GetQuery())
+	if err != nil || req.GetShortUrl() == "" {
+		resp.Error = toError(errors.SetCustomError(constant.ErrInvalidRequest))
+		return resp
+	}
+
+	data, err := s.URLApp.GetURLByShortURL(ctx, &model.ResolveURLRequest{
+		ShortURL:       req.GetShortUrl(),
+		Host:           req.GetHost(),
+		ClientIP:       req.GetClientIp(),
+		UserAgent:      req.GetUserAgent(),
+		AcceptLanguage: req.GetAcceptLanguage(),
+		VariantID:      req.GetVariantId(),
+		Query:          query,
+		Path:           req.GetPath(),
+	})
+	if err != nil {
+		resp.Error = toError(err)
+		return resp
+	}
+
+	redirectType := data.RedirectType
+	if !constant.IsValidRedirectType(redirectType) {
+		redirectType = s.Config.Server.DefaultRedirectType
+	}
+
+	resp.OriginalUrl = data.OriginalURL
+	resp.RedirectType = int32(redirectType)
+	resp.VariantId = data.VariantID
+	resp.PreviewRequired = data.PreviewRequired
+	return resp
+}
+
+func toError(err error) *urlv1.Error {
+	customError, ok := err.(errors.CustomError)
+	if !ok {
+		log.Println("[Resolve] err GetURLByShortURL", err)
+		customError = errors.SetCustomError(constant.ErrInternal)
+	}
+
+	return &urlv1.Error{
+		Code:    customError.ErrorCode(),
+		Message: customError.Error(),
+	}
+}
+
+func fromCreateShortURLRequest(req *urlv1.CreateShortURLRequest) *model.CreateURLShortnerRequest {
+	result := &model.CreateURLShortnerRequest{
+		OriginalURL:   req.GetOriginalUrl(),
+		Domain:        req.GetDomain(),
+		CampaignID:    req.GetCampaignId(),
+		Tags:          req.GetTags(),
+		RedirectType:  int(req.GetRedirectType()),
+		Password:      req.GetPassword(),
+		SingleUse:     req.GetSingleUse(),
+		ActiveFrom:    fromTimestamp(req.GetActiveFrom()),
+		ActiveUntil:   fromTimestamp(req.GetActiveUntil()),
+		Schedule:      fromSchedule(req.GetSchedule()),
+		FallbackURL:   req.GetFallbackUrl(),
+		StickyVariant: req.GetStickyVariant(),
+		ForwardQuery:  req.GetForwardQuery(),
+		QueryConflict: req.GetQueryConflict(),
+		ForwardPath:   req.GetForwardPath(),
+	}
+	for _, variant := range req.GetVariants() {
+		result.Variants = append(result.Variants, model.VariantRequest{
+			DestinationURL: variant.GetDestinationUrl(),
+			Weight:         int(variant.GetWeight()),
+		})
+	}
+	if utm := req.GetUtm(); utm != nil {
+		result.UTM = &model.UTMParams{
+			Source:   utm.GetSource(),
+			Medium:   utm.GetMedium(),
+			Campaign: utm.GetCampaign(),
+			Term:     utm.GetTerm(),
+			Content:  utm.GetContent(),
+		}
+	}
+
+	return result
+}
+
+func toURL(data *model.GetURLResponse) *urlv1.URL {
+	result := &urlv1.URL{
+		ShortUrl:          data.ShortURL,
+		Domain:            data.Domain,
+		CampaignId:        data.CampaignID,
+		Tags:              data.Tags,
+		ShortLink:         data.ShortLink,
+		QrUrl:             data.QRURL,
+		InfoUrl:           data.InfoURL,
+		OriginalUrl:       data.OriginalURL,
+		RedirectType:      int32(data.RedirectType),
+		PreviewRequired:   data.PreviewRequired,
+		PasswordProtected: data.PasswordProtected,
+		SingleUse:         data.SingleUse,
+		ActiveFrom:        toTimestamp(data.ActiveFrom),
+		ActiveUntil:       toTimestamp(data.ActiveUntil),
+		Schedule:          toSchedule(data.Schedule),
+		FallbackUrl:       data.FallbackURL,
+		StickyVariant:     data.StickyVariant,
+		ForwardQuery:      data.ForwardQuery,
+		QueryConflict:     data.QueryConflict,
+		ForwardPath:       data.ForwardPath,
+		CreatedAt:         timestamppb.New(data.CreatedAt),
+		UpdatedAt:         toTimestamp(data.UpdatedAt),
+	}
+	for _, variant := range data.Variants {
+		result.Variants = append(result.Variants, &urlv1.Variant{
+			Id:             variant.ID,
+			DestinationUrl: variant.DestinationURL,
+			Weight:         int32(variant.Weight),
+		})
+	}
+
+	return result
+}
+
+// toURLInfo leaves out the destination of the links that must be visited to reveal it,
+// like hideDestination does for REST clients without the unlock cookie
+func toURLInfo(data *model.GetURLInfoResponse) *urlv1.URLInfo {
+	result := &urlv1.URLInfo{
+		Url:        toURL(&data.GetURLResponse),
+		UserId:     data.UserID,
+		Status:     data.Status,
+		ClickCount: data.ClickCount,
+		ConsumedAt: toTimestamp(data.ConsumedAt),
+		Active:     data.Active,
+	}
+	if data.SingleUse || !data.Active || data.PasswordProtected {
+		result.Url.OriginalUrl = ""
+	}
+
+	return result
+}
+
+func fromSchedule(schedule *urlv1.Schedule) *model.URLSchedule {
+	if schedule == nil {
+		return nil
+	}
+
+	result := &model.URLSchedule{TimeZone: schedule.GetTimeZone()}
+	for _, window := range schedule.GetWindows() {
+		result.Windows = append(result.Windows, model.ScheduleWindow{
+			Days:  window.GetDays(),
+			Start: window.GetStart(),
+			End:   window.GetEnd(),
+		})
+	}
+	return result
+}
+
+func toSchedule(schedule *model.URLSchedule) *urlv1.Schedule {
+	if schedule == nil {
+		return nil
+	}
+
+	result := &urlv1.Schedule{TimeZone: schedule.TimeZone}
+	for _, window := range schedule.Windows {
+		result.Windows = append(result.Windows, &urlv1.ScheduleWindow{
+			Days:  window.Days,
+			Start: window.Start,
+			End:   window.End,
+		})
+	}
+	return result
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	value := ts.AsTime()
+	return &value
+}
+
+func toTimestamp(value *time.Time) *timestamppb.Timestamp {
+	if value == nil {
+		return nil
+	}
+	return timestamppb.New(*value)
+}
@@ -0,0 +1,220 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	appurl "github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	campaignmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/campaign"
+	clickmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/click"
+	domainmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/domain"
+	rulemocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/rule"
+	tagmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/tag"
+	urlmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/url"
+	variantmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/variant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	urlv1 "github.com/muhammadheryan/url-shortner-base62/proto/url/v1"
+	"github.com/muhammadheryan/url-shortner-base62/transport"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves the gRPC API of app in memory and returns a client connected to it
+func newGRPCClient(t *testing.T, app appurl.URLApp) urlv1.URLServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := transport.NewGRPCServer(app, testConfig())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return urlv1.NewURLServiceClient(conn)
+}
+
+// assertStatus checks the gRPC code of err and the REST error code kept as its ErrorInfo reason
+func assertStatus(t *testing.T, err error, wantCode codes.Code, wantReason string) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != wantCode {
+		t.Fatalf("error = %v, want code %s", err, wantCode)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			if info.GetReason() != wantReason {
+				t.Fatalf("ErrorInfo reason = %q, want %q", info.GetReason(), wantReason)
+			}
+			return
+		}
+	}
+	t.Fatalf("status %v has no ErrorInfo detail", st)
+}
+
+// failingApp returns an error outside of the application errors from ListURLs
+type failingApp struct {
+	appurl.URLApp
+}
+
+func (failingApp) ListURLs(context.Context, *model.ListURLRequest) (*model.ListURLResponse, error) {
+	return nil, errors.New("connection reset")
+}
+
+func TestGRPCHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupRepo  func(urlRepo *urlmocks.URLRepository)
+		call       func(client urlv1.URLServiceClient) error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name: "unknown link",
+			setupRepo: func(urlRepo *urlmocks.URLRepository) {
+				urlRepo.On("Get", mock.Anything, mock.Anything).Return(nil, nil).Once()
+			},
+			call: func(client urlv1.URLServiceClient) error {
+				_, err := client.GetURL(context.Background(), &urlv1.GetURLRequest{ShortUrl: "0000Z"})
+				return err
+			},
+			wantCode:   codes.NotFound,
+			wantReason: constant.ErrorTypeCode[constant.ErrNotFound],
+		},
+		{
+			name: "missing short url",
+			call: func(client urlv1.URLServiceClient) error {
+				_, err := client.GetURL(context.Background(), &urlv1.GetURLRequest{})
+				return err
+			},
+			wantCode:   codes.InvalidArgument,
+			wantReason: constant.ErrorTypeCode[constant.ErrInvalidRequest],
+		},
+		{
+			name: "repository failure",
+			setupRepo: func(urlRepo *urlmocks.URLRepository) {
+				urlRepo.On("Get", mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Once()
+			},
+			call: func(client urlv1.URLServiceClient) error {
+				_, err := client.DeleteURL(context.Background(), &urlv1.DeleteURLRequest{ShortUrl: "0000Z"})
+				return err
+			},
+			wantCode:   codes.Internal,
+			wantReason: constant.ErrorTypeCode[constant.ErrInternal],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlRepo := urlmocks.NewURLRepository(t)
+			if tt.setupRepo != nil {
+				tt.setupRepo(urlRepo)
+			}
+			_, app := newTestHandler(t, urlRepo, nil, testConfig())
+
+			assertStatus(t, tt.call(newGRPCClient(t, app)), tt.wantCode, tt.wantReason)
+		})
+	}
+
+	t.Run("errors outside of the application are internal", func(t *testing.T) {
+		_, err := newGRPCClient(t, failingApp{}).ListURLs(context.Background(), &urlv1.ListURLsRequest{})
+		assertStatus(t, err, codes.Internal, constant.ErrorTypeCode[constant.ErrInternal])
+	})
+}
+
+func TestGRPCHandler_Resolve(t *testing.T) {
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, &model.URLFilter{ShortURL: "0000A", DomainID: new(uint64)}).Return(&model.URLEntity{
+		ID:          10,
+		ShortURL:    "0000A",
+		OriginalURL: "https://example.com/a",
+		CreatedAt:   time.Now(),
+	}, nil).Once()
+	urlRepo.On("Get", mock.Anything, &model.URLFilter{ShortURL: "0000B", DomainID: new(uint64)}).Return(nil, nil).Once()
+	urlRepo.On("RecordClick", mock.Anything, mock.Anything, true).Return(nil).Once()
+	_, app := newTestHandler(t, urlRepo, nil, testConfig())
+
+	stream, err := newGRPCClient(t, app).Resolve(context.Background())
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for _, shortURL := range []string{"0000A", "0000B", ""} {
+		if err := stream.Send(&urlv1.ResolveRequest{ShortUrl: shortURL}); err != nil {
+			t.Fatalf("Send(%q) error = %v", shortURL, err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend() error = %v", err)
+	}
+
+	// every request is answered in order, a failed lookup doesn't end the stream
+	want := []struct {
+		shortURL    string
+		originalURL string
+		errorCode   string
+	}{
+		{shortURL: "0000A", originalURL: "https://example.com/a"},
+		{shortURL: "0000B", errorCode: constant.ErrorTypeCode[constant.ErrNotFound]},
+		{shortURL: "", errorCode: constant.ErrorTypeCode[constant.ErrInvalidRequest]},
+	}
+	for _, w := range want {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if resp.GetShortUrl() != w.shortURL || resp.GetOriginalUrl() != w.originalURL || resp.GetError().GetCode() != w.errorCode {
+			t.Fatalf("Recv() = %+v, want %+v", resp, w)
+		}
+		if w.errorCode == "" && resp.GetRedirectType() != http.StatusTemporaryRedirect {
+			t.Fatalf("RedirectType = %d, want the default %d", resp.GetRedirectType(), http.StatusTemporaryRedirect)
+		}
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Recv() after the last answer error = %v, want io.EOF", err)
+	}
+}
+
+func TestGRPCHandler_Domain(t *testing.T) {
+	verifiedAt := time.Now()
+	domainRepo := domainmocks.NewDomainRepository(t)
+	domainRepo.On("Get", mock.Anything, &model.DomainFilter{Host: "go.acme.com"}).Return(&model.DomainEntity{ID: 4, Host: "go.acme.com", VerifiedAt: &verifiedAt}, nil)
+	domainRepo.On("Get", mock.Anything, &model.DomainFilter{ID: 4}).Return(&model.DomainEntity{ID: 4, Host: "go.acme.com", VerifiedAt: &verifiedAt}, nil).Maybe()
+	link := &model.URLEntity{ID: 10, DomainID: 4, ShortURL: "0000A", OriginalURL: "https://example.com/a", Status: constant.URLStatusActive, CreatedAt: time.Now()}
+	domainID := uint64(4)
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, &model.URLFilter{ShortURL: "0000A", DomainID: &domainID}).Return(link, nil).Twice()
+	urlRepo.On("Delete", mock.Anything, uint64(10)).Return(nil).Once()
+	tagRepo := tagmocks.NewTagRepository(t)
+	tagRepo.On("ListByURLs", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	variantRepo := variantmocks.NewVariantRepository(t)
+	variantRepo.On("List", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	app := appurl.NewURLApplication(urlRepo, rulemocks.NewRuleRepository(t), variantRepo, clickmocks.NewClickRepository(t), domainRepo, tagRepo, campaignmocks.NewCampaignRepository(t), stubLocator{}, nil, testConfig())
+	client := newGRPCClient(t, app)
+
+	// the code is looked up on the custom domain, not on the default domain
+	info, err := client.GetURL(context.Background(), &urlv1.GetURLRequest{ShortUrl: "0000A", Domain: "go.acme.com"})
+	if err != nil || info.GetUrl().GetOriginalUrl() != "https://example.com/a" || info.GetUrl().GetDomain() != "go.acme.com" {
+		t.Fatalf("GetURL() = %+v, %v", info, err)
+	}
+	if _, err := client.DeleteURL(context.Background(), &urlv1.DeleteURLRequest{ShortUrl: "0000A", Domain: "go.acme.com"}); err != nil {
+		t.Fatalf("DeleteURL() error = %v", err)
+	}
+}
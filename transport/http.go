@@ -1,34 +1,102 @@
 package transport
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/muhammadheryan/url-shortner-base62/application/campaign"
+	"github.com/muhammadheryan/url-shortner-base62/application/domain"
+	"github.com/muhammadheryan/url-shortner-base62/application/rule"
+	"github.com/muhammadheryan/url-shortner-base62/application/tag"
 	"github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/application/webhook"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
 	"github.com/muhammadheryan/url-shortner-base62/constant"
 	"github.com/muhammadheryan/url-shortner-base62/model"
 	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+	"github.com/muhammadheryan/url-shortner-base62/utils/qr"
+	"github.com/muhammadheryan/url-shortner-base62/utils/useragent"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 type RestHandler struct {
-	URLApp url.URLApp
+	URLApp      url.URLApp
+	RuleApp     rule.RuleApp
+	DomainApp   domain.DomainApp
+	CampaignApp campaign.CampaignApp
+	TagApp      tag.TagApp
+	WebhookApp  webhook.WebhookApp
+	Config      *config.Config
+
+	cookieSecret []byte
 }
 
-func NewTransport(URLApp url.URLApp) http.Handler {
+func NewTransport(URLApp url.URLApp, RuleApp rule.RuleApp, DomainApp domain.DomainApp, CampaignApp campaign.CampaignApp, TagApp tag.TagApp, WebhookApp webhook.WebhookApp, cfg *config.Config) http.Handler {
 	mux := mux.NewRouter()
 
 	rh := &RestHandler{
-		URLApp: URLApp,
+		URLApp:       URLApp,
+		RuleApp:      RuleApp,
+		DomainApp:    DomainApp,
+		CampaignApp:  CampaignApp,
+		TagApp:       TagApp,
+		WebhookApp:   WebhookApp,
+		Config:       cfg,
+		cookieSecret: []byte(cfg.GetCookieSecret()),
 	}
 
 	// Swagger UI - setup sederhana
 	mux.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	// API routes
+	mux.HandleFunc("/domain", rh.CreateDomain).Methods(http.MethodPost)
+	mux.HandleFunc("/domain", rh.ListDomains).Methods(http.MethodGet)
+	mux.HandleFunc("/domain/{domainID:[0-9]+}", rh.GetDomain).Methods(http.MethodGet)
+	mux.HandleFunc("/domain/{domainID:[0-9]+}", rh.DeleteDomain).Methods(http.MethodDelete)
+	mux.HandleFunc("/domain/{domainID:[0-9]+}/verify", rh.VerifyDomain).Methods(http.MethodPost)
+	mux.HandleFunc("/campaign", rh.CreateCampaign).Methods(http.MethodPost)
+	mux.HandleFunc("/campaign", rh.ListCampaigns).Methods(http.MethodGet)
+	mux.HandleFunc("/campaign/{campaignID:[0-9]+}", rh.GetCampaign).Methods(http.MethodGet)
+	mux.HandleFunc("/campaign/{campaignID:[0-9]+}", rh.UpdateCampaign).Methods(http.MethodPut)
+	mux.HandleFunc("/campaign/{campaignID:[0-9]+}", rh.DeleteCampaign).Methods(http.MethodDelete)
+	mux.HandleFunc("/campaign/{campaignID:[0-9]+}/stats", rh.GetCampaignStats).Methods(http.MethodGet)
+	mux.HandleFunc("/tag", rh.CreateTag).Methods(http.MethodPost)
+	mux.HandleFunc("/tag", rh.ListTags).Methods(http.MethodGet)
+	mux.HandleFunc("/tag/{tagID:[0-9]+}", rh.DeleteTag).Methods(http.MethodDelete)
+	mux.HandleFunc("/webhook", rh.CreateWebhook).Methods(http.MethodPost)
+	mux.HandleFunc("/webhook", rh.ListWebhooks).Methods(http.MethodGet)
+	mux.HandleFunc("/webhook/{webhookID:[0-9]+}", rh.GetWebhook).Methods(http.MethodGet)
+	mux.HandleFunc("/webhook/{webhookID:[0-9]+}", rh.DeleteWebhook).Methods(http.MethodDelete)
+	mux.HandleFunc("/webhook/{webhookID:[0-9]+}/dead-letters", rh.ListWebhookDeadLetters).Methods(http.MethodGet)
+	mux.HandleFunc("/webhook/{webhookID:[0-9]+}/dead-letters/{deadLetterID:[0-9]+}/replay", rh.ReplayWebhookDeadLetter).Methods(http.MethodPost)
 	mux.HandleFunc("/url", rh.CreateURLShortner).Methods(http.MethodPost)
+	mux.HandleFunc("/url", rh.ListURLs).Methods(http.MethodGet)
+	mux.HandleFunc("/url/batch", rh.CreateURLShortnerBatch).Methods(http.MethodPost)
+	mux.HandleFunc("/url/{shortURL:[0-9A-Za-z]+}+", rh.GetURLPreview).Methods(http.MethodGet)
+	mux.HandleFunc("/url/{shortURL}/info", rh.GetURLInfo).Methods(http.MethodGet)
+	mux.HandleFunc("/url/{shortURL}/qr", rh.GetURLQRCode).Methods(http.MethodGet)
+	mux.HandleFunc("/url/{shortURL}/stats", rh.GetURLStats).Methods(http.MethodGet)
+	mux.HandleFunc("/url/{shortURL}/tags", rh.SetURLTags).Methods(http.MethodPut)
+	mux.HandleFunc("/url/{shortURL}/campaign", rh.SetURLCampaign).Methods(http.MethodPut)
+	mux.HandleFunc("/url/{shortURL}/rules", rh.CreateRule).Methods(http.MethodPost)
+	mux.HandleFunc("/url/{shortURL}/rules", rh.ListRules).Methods(http.MethodGet)
+	mux.HandleFunc("/url/{shortURL}/rules/{ruleID:[0-9]+}", rh.UpdateRule).Methods(http.MethodPut)
+	mux.HandleFunc("/url/{shortURL}/rules/{ruleID:[0-9]+}", rh.DeleteRule).Methods(http.MethodDelete)
 	mux.HandleFunc("/url/{shortURL}", rh.GetOriginalURL).Methods(http.MethodGet)
+	mux.HandleFunc("/url/{shortURL}", rh.UnlockURL).Methods(http.MethodPost)
+	mux.HandleFunc("/url/{shortURL}", rh.DeleteURL).Methods(http.MethodDelete)
+	// Registered last so the routes above take precedence over forwarded paths, the GET
+	// paths info, qr, stats and rules of a link are never forwarded
+	mux.HandleFunc("/url/{shortURL}/{rest:.*}", rh.GetOriginalURL).Methods(http.MethodGet)
 
 	return mux
 }
@@ -55,17 +123,60 @@ func (s *RestHandler) CreateURLShortner(w http.ResponseWriter, r *http.Request)
 		writeError(w, err)
 		return
 	}
+	s.fillLinks(r, data)
+
+	writeSuccess(w, data)
+}
+
+// @Summary Create short URLs in batch
+// @Description Create many short URLs at once from a JSON array or a CSV upload (columns original_url and optional redirect_type), returns a result per item
+// @Accept json
+// @Accept mpfd
+// @Accept text/csv
+// @Produce json
+// @Param request body []model.CreateURLShortnerRequest false "Create URL Requests"
+// @Param file formData file false "CSV file"
+// @Success 200 {object} model.CreateURLShortnerBatchResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url/batch [post]
+func (s *RestHandler) CreateURLShortnerBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodySize)
+
+	req, err := decodeBatchRequest(r)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.URLApp.CreateURLShortnerBatch(ctx, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, item := range data.Items {
+		if item.GetURLResponse != nil {
+			s.fillLinks(r, item.GetURLResponse)
+		}
+	}
 
 	writeSuccess(w, data)
 }
 
 // @Summary Redirect to original URL
-// @Description Redirect to original URL using short URL
+// @Description Redirect to original URL using short URL, returns the link metadata instead when the client accepts application/json.
+// @Description Links forwarding the path also answer on /url/{shortURL}/rest/of/path and append rest/of/path to the destination.
 // @Accept json
 // @Produce json
 // @Param shortURL path string true "Short URL"
-// @Success 308 {string} string "Redirect to original URL"
+// @Success 301 {string} string "Permanent redirect to original URL"
+// @Success 302 {string} string "Redirect to original URL"
+// @Success 307 {string} string "Temporary redirect to original URL"
+// @Success 308 {string} string "Permanent redirect to original URL"
 // @Failure 404 {object} errors.CustomError
+// @Failure 403 {string} string "Not available page"
+// @Failure 410 {object} errors.CustomError
 // @Router /url/{shortURL} [get]
 func (s *RestHandler) GetOriginalURL(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -79,14 +190,576 @@ func (s *RestHandler) GetOriginalURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Serve metadata instead of redirecting when the client asks for JSON
+	w.Header().Set("Vary", "Accept")
+	if acceptsJSON(r) {
+		s.writeURLInfo(w, r, shortURL)
+		return
+	}
+
+	// Link preview bots get metadata only so they don't burn single use links
+	if useragent.IsLinkPreviewBot(r.UserAgent()) {
+		info, err := s.URLApp.GetURLInfo(ctx, s.requestHost(r), shortURL)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if info.SingleUse {
+			w.Header().Set("Cache-Control", "private, no-cache, no-store, must-revalidate")
+			writeHTML(w, http.StatusOK, "metadata.html", info)
+			return
+		}
+	}
+
 	// Get original URL from database
-	data, err := s.URLApp.GetURLByShortURL(ctx, shortURL)
+	data, err := s.URLApp.GetURLByShortURL(ctx, &model.ResolveURLRequest{
+		ShortURL:       shortURL,
+		Host:           s.requestHost(r),
+		Unlocked:       s.isUnlocked(r, shortURL),
+		ClientIP:       s.clientIP(r),
+		UserAgent:      r.UserAgent(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+		VariantID:      stickyVariant(r, shortURL),
+		Query:          r.URL.Query(),
+		Path:           vars["rest"],
+	})
 	if err != nil {
+		if errors.Is(err, constant.ErrPasswordRequired) {
+			writePasswordForm(w, http.StatusUnauthorized, shortURL, vars["rest"], r.URL.RawQuery, "")
+			return
+		}
+		if errors.Is(err, constant.ErrNotAvailable) {
+			s.writeUnavailable(w, r, shortURL)
+			return
+		}
 		writeError(w, err)
 		return
 	}
 
-	// Redirect to original URL with HTTP 308 (Permanent Redirect)
+	if data.StickyVariant && data.VariantID != 0 {
+		s.setVariantCookie(w, r, shortURL, data.VariantID)
+	}
+
+	// Links of untrusted users open the preview page of the destination chosen for this visit
+	if data.PreviewRequired {
+		w.Header().Set("Cache-Control", "private, no-cache, no-store, must-revalidate")
+		writeHTML(w, http.StatusOK, "preview.html", s.URLApp.GetResolvedURLPreview(ctx, data))
+		return
+	}
+
+	// Redirect to original URL with the redirect type chosen for this link
+	redirectType := data.RedirectType
+	if !constant.IsValidRedirectType(redirectType) {
+		redirectType = s.Config.Server.DefaultRedirectType
+	}
+
+	// Permanent links may be cached by browsers, editable, protected, single use and split ones must be revalidated.
+	// Shared caches are left out since the destination may be targeted at the visitor.
+	if constant.IsPermanentRedirect(redirectType) && !data.PasswordProtected && !data.SingleUse && data.VariantID == 0 {
+		maxAge := int(s.Config.Server.RedirectCacheMaxAge.Seconds())
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(maxAge))
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	}
+
 	w.Header().Set("Location", data.OriginalURL)
-	w.WriteHeader(http.StatusTemporaryRedirect)
+	w.WriteHeader(redirectType)
+}
+
+// @Summary Unlock password protected short URL
+// @Description Verify the password of a protected short URL, on success a cookie is set and the visitor is sent back to the short URL with the forwarded path and query
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param shortURL path string true "Short URL"
+// @Param password formData string true "Link password"
+// @Param path formData string false "Forwarded path of the visit"
+// @Param query formData string false "Query string of the visit"
+// @Success 303 {string} string "Redirect to short URL"
+// @Failure 401 {string} string "Password form"
+// @Failure 429 {string} string "Password form"
+// @Router /url/{shortURL} [post]
+func (s *RestHandler) UnlockURL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	if shortURL == "" {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	// The path and query the visit was forwarding, kept by the password form
+	path := r.PostFormValue("path")
+	query := r.PostFormValue("query")
+
+	err := s.URLApp.UnlockURL(ctx, &model.UnlockURLRequest{
+		ShortURL:  shortURL,
+		Host:      s.requestHost(r),
+		Password:  r.PostFormValue("password"),
+		ClientKey: s.clientIP(r),
+	})
+	switch {
+	case errors.Is(err, constant.ErrUnauthorize):
+		writePasswordForm(w, http.StatusUnauthorized, shortURL, path, query, "Incorrect password, please try again.")
+		return
+	case errors.Is(err, constant.ErrTooManyRequests):
+		writePasswordForm(w, http.StatusTooManyRequests, shortURL, path, query, "Too many incorrect attempts, please try again later.")
+		return
+	case err != nil:
+		writeError(w, err)
+		return
+	}
+
+	s.setUnlockCookie(w, r, shortURL)
+	http.Redirect(w, r, visitURL(shortURL, path, query), http.StatusSeeOther)
+}
+
+// visitURL is the short link with the forwarded path and query of a visit, the query is
+// dropped when it doesn't parse
+func visitURL(shortURL, path, query string) string {
+	target := neturl.URL{Path: "/url/" + shortURL}
+	if path = strings.TrimPrefix(path, "/"); path != "" {
+		target.Path += "/" + path
+	}
+	if _, err := neturl.ParseQuery(query); err == nil {
+		target.RawQuery = query
+	}
+	return target.String()
+}
+
+// @Summary Get short URL metadata
+// @Description Get metadata of a short URL (owner, status, click count) without redirecting
+// @Accept json
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Success 200 {object} model.GetURLInfoResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/info [get]
+func (s *RestHandler) GetURLInfo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	if shortURL == "" {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	s.writeURLInfo(w, r, shortURL)
+}
+
+func (s *RestHandler) writeURLInfo(w http.ResponseWriter, r *http.Request, shortURL string) {
+	data, err := s.URLApp.GetURLInfo(r.Context(), s.requestHost(r), shortURL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if s.hideDestination(r, data) {
+		data.OriginalURL = ""
+	}
+	s.fillLinks(r, &data.GetURLResponse)
+
+	writeSuccess(w, data)
+}
+
+// @Summary Get short URL click stats
+// @Description Get the total clicks of a short URL and the clicks served by each of its variants
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Success 200 {object} model.GetURLStatsResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/stats [get]
+func (s *RestHandler) GetURLStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	data, err := s.URLApp.GetURLStats(r.Context(), s.requestHost(r), shortURL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The destinations are hidden like on /info, only the clicks are reported
+	if s.isDestinationHidden(r, data.ShortURL, data.PasswordProtected, data.SingleUse, data.Active) {
+		for i := range data.Variants {
+			data.Variants[i].DestinationURL = ""
+		}
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Delete short URL
+// @Description Delete a short URL with its rules, variants, clicks and tags
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL} [delete]
+func (s *RestHandler) DeleteURL(w http.ResponseWriter, r *http.Request) {
+	if err := s.URLApp.DeleteURL(r.Context(), s.requestHost(r), mux.Vars(r)["shortURL"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
+
+// @Summary List short URLs
+// @Description List the short URLs newest first, optionally only those of a campaign or with a tag
+// @Produce json
+// @Param campaign_id query int false "Campaign ID"
+// @Param tag query string false "Tag"
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of links to skip"
+// @Success 200 {object} model.ListURLResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url [get]
+func (s *RestHandler) ListURLs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var req model.ListURLRequest
+	var err error
+	if value := query.Get("campaign_id"); value != "" {
+		if req.CampaignID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+			return
+		}
+	}
+	if value := query.Get("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+			return
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if req.Offset, err = strconv.Atoi(value); err != nil {
+			writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+			return
+		}
+	}
+	req.Tag = query.Get("tag")
+
+	data, err := s.URLApp.ListURLs(r.Context(), &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for _, item := range data.Items {
+		if s.hideDestination(r, item) {
+			item.OriginalURL = ""
+		}
+		s.fillLinks(r, &item.GetURLResponse)
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Preview short URL
+// @Description Render an HTML page showing the destination of a short URL instead of redirecting
+// @Produce html
+// @Param shortURL path string true "Short URL"
+// @Success 200 {string} string "Preview page"
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}+ [get]
+func (s *RestHandler) GetURLPreview(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	if shortURL == "" {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	unlocked := s.isUnlocked(r, shortURL)
+	data, err := s.URLApp.GetURLPreview(r.Context(), s.requestHost(r), shortURL, unlocked)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if data.PasswordProtected && !unlocked {
+		writePasswordForm(w, http.StatusUnauthorized, shortURL, "", "", "")
+		return
+	}
+	// Continue goes through the short link so single use links get consumed
+	if s.hideDestination(r, &data.GetURLInfoResponse) {
+		data.OriginalURL = ""
+		data.Title = ""
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	writeHTML(w, http.StatusOK, "preview.html", data)
+}
+
+// @Summary Get QR code of short URL
+// @Description Render the full short link as a QR code image
+// @Produce image/png
+// @Produce image/svg+xml
+// @Param shortURL path string true "Short URL"
+// @Param format query string false "Image format (png or svg)" default(png)
+// @Param size query int false "Image width and height in pixels (64-2048)" default(256)
+// @Param level query string false "Error correction level (L, M, Q or H)" default(M)
+// @Param margin query int false "Quiet zone in modules (0-16)" default(4)
+// @Param fg query string false "Foreground color as hex" default(000000)
+// @Param bg query string false "Background color as hex" default(ffffff)
+// @Success 200 {file} file "QR code image"
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/qr [get]
+func (s *RestHandler) GetURLQRCode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	if shortURL == "" {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	opts, err := parseQROptions(r.URL.Query())
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	// Make sure the link exists, the lookup does not count as a click
+	data, err := s.URLApp.GetURLInfo(ctx, s.requestHost(r), shortURL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	s.fillLinks(r, &data.GetURLResponse)
+	shortLink := data.ShortLink
+
+	// The image only depends on the link and the options, it is only cached for a short
+	// while so it stops being served once the link is deleted or expires, the ETag makes
+	// revalidating it cheap
+	etag := qrETag(shortLink, opts)
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(qrCacheMaxAge))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := qr.Write(&buf, shortLink, opts); err != nil {
+		log.Println("[GetURLQRCode] err Write", err)
+		writeError(w, errors.SetCustomError(constant.ErrInternal))
+		return
+	}
+
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// parseQROptions reads the QR rendering options from the query string
+func parseQROptions(query neturl.Values) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+
+	if format := query.Get("format"); format != "" {
+		opts.Format = strings.ToLower(format)
+	}
+	if level := query.Get("level"); level != "" {
+		opts.Level = strings.ToUpper(level)
+	}
+	if size := query.Get("size"); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil {
+			return opts, err
+		}
+		opts.Size = value
+	}
+	if margin := query.Get("margin"); margin != "" {
+		value, err := strconv.Atoi(margin)
+		if err != nil {
+			return opts, err
+		}
+		opts.Margin = value
+	}
+	if fg := query.Get("fg"); fg != "" {
+		value, err := qr.ParseColor(fg)
+		if err != nil {
+			return opts, err
+		}
+		opts.Foreground = value
+	}
+	if bg := query.Get("bg"); bg != "" {
+		value, err := qr.ParseColor(bg)
+		if err != nil {
+			return opts, err
+		}
+		opts.Background = value
+	}
+
+	return opts, opts.Validate()
+}
+
+// qrCacheMaxAge is how many seconds clients may reuse a QR code without revalidating it
+const qrCacheMaxAge = 300
+
+func qrETag(content string, opts qr.Options) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%+v", content, opts)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeUnavailable renders the not available page of a link outside its activation window,
+// judged on the clock of the application like the redirect
+func (s *RestHandler) writeUnavailable(w http.ResponseWriter, r *http.Request, shortURL string) {
+	data, err := s.URLApp.GetURLInfo(r.Context(), s.requestHost(r), shortURL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	writeHTML(w, http.StatusForbidden, "unavailable.html", unavailablePage{
+		ShortURL:   data.ShortURL,
+		ActiveFrom: data.ActiveFrom,
+		NotYet:     data.ActiveFrom != nil && data.CheckedAt.Before(*data.ActiveFrom),
+	})
+}
+
+// hideDestination checks if the destination must not be shown without visiting the link,
+// protected links reveal it once the password was entered, single use links and links
+// outside their activation window never do
+func (s *RestHandler) hideDestination(r *http.Request, data *model.GetURLInfoResponse) bool {
+	return s.isDestinationHidden(r, data.ShortURL, data.PasswordProtected, data.SingleUse, data.Active)
+}
+
+func (s *RestHandler) isDestinationHidden(r *http.Request, shortURL string, passwordProtected, singleUse, active bool) bool {
+	if singleUse || !active {
+		return true
+	}
+	return passwordProtected && !s.isUnlocked(r, shortURL)
+}
+
+// @Summary Create targeting rule
+// @Description Create a rule sending visitors matching country, device type, OS or language to another destination
+// @Accept json
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Param request body model.UpsertRuleRequest true "Rule"
+// @Success 200 {object} model.GetRuleResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/rules [post]
+func (s *RestHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	var req model.UpsertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.RuleApp.CreateRule(ctx, s.requestHost(r), shortURL, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary List targeting rules
+// @Description List the rules of a short URL in evaluation order, the destinations are left empty like on /info
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Success 200 {array} model.GetRuleResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/rules [get]
+func (s *RestHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+
+	data, err := s.RuleApp.ListRules(ctx, s.requestHost(r), shortURL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The rules would reveal where a protected, single use or inactive link leads
+	info, err := s.URLApp.GetURLInfo(ctx, s.requestHost(r), shortURL)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if s.hideDestination(r, info) {
+		for _, rule := range data {
+			rule.DestinationURL = ""
+		}
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Update targeting rule
+// @Description Replace the conditions and destination of a rule
+// @Accept json
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Param ruleID path int true "Rule ID"
+// @Param request body model.UpsertRuleRequest true "Rule"
+// @Success 200 {object} model.GetRuleResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/rules/{ruleID} [put]
+func (s *RestHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+	ruleID, err := strconv.ParseUint(vars["ruleID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	var req model.UpsertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.RuleApp.UpdateRule(ctx, s.requestHost(r), shortURL, ruleID, &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Delete targeting rule
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Param ruleID path int true "Rule ID"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/rules/{ruleID} [delete]
+func (s *RestHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	shortURL := vars["shortURL"]
+	ruleID, err := strconv.ParseUint(vars["ruleID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.RuleApp.DeleteRule(r.Context(), s.requestHost(r), shortURL, ruleID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
 }
@@ -0,0 +1,323 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strings"
+	"testing"
+	"time"
+
+	apprule "github.com/muhammadheryan/url-shortner-base62/application/rule"
+	appurl "github.com/muhammadheryan/url-shortner-base62/application/url"
+	"github.com/muhammadheryan/url-shortner-base62/cmd/config"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	campaignmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/campaign"
+	clickmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/click"
+	domainmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/domain"
+	rulemocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/rule"
+	tagmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/tag"
+	urlmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/url"
+	variantmocks "github.com/muhammadheryan/url-shortner-base62/mocks/repository/variant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/transport"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			DefaultRedirectType: http.StatusTemporaryRedirect,
+			CookieSecret:        "test-secret",
+			PasswordCookieTTL:   time.Hour,
+			PasswordMaxAttempts: 2,
+			PasswordLockout:     time.Minute,
+		},
+	}
+}
+
+// stubLocator resolves every address to no country
+type stubLocator struct{}
+
+func (stubLocator) Country(string) string {
+	return ""
+}
+
+// stubFetcher records the pages whose title is fetched
+type stubFetcher struct {
+	fetched []string
+}
+
+func (f *stubFetcher) Fetch(_ context.Context, pageURL string) (string, error) {
+	f.fetched = append(f.fetched, pageURL)
+	return "Title of " + pageURL, nil
+}
+
+// newTestHandler serves the REST API over the url app with no rules, variants nor custom domains
+func newTestHandler(t *testing.T, urlRepo *urlmocks.URLRepository, variants []*model.VariantEntity, cfg *config.Config) (http.Handler, *appurl.URLAppImpl) {
+	ruleRepo := rulemocks.NewRuleRepository(t)
+	ruleRepo.On("List", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	variantRepo := variantmocks.NewVariantRepository(t)
+	variantRepo.On("List", mock.Anything, mock.Anything).Return(variants, nil).Maybe()
+	domainRepo := domainmocks.NewDomainRepository(t)
+	domainRepo.On("Get", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	tagRepo := tagmocks.NewTagRepository(t)
+	tagRepo.On("ListByURLs", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	clickRepo := clickmocks.NewClickRepository(t)
+	clickRepo.On("CountByVariant", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+
+	app := appurl.NewURLApplication(urlRepo, ruleRepo, variantRepo, clickRepo, domainRepo, tagRepo, campaignmocks.NewCampaignRepository(t), stubLocator{}, nil, cfg).(*appurl.URLAppImpl)
+	return transport.NewTransport(app, nil, nil, nil, nil, nil, cfg), app
+}
+
+func TestRestHandler_ForcedPreview(t *testing.T) {
+	launch := time.Now().Add(time.Hour)
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.
+		On("Get", mock.Anything, mock.Anything).
+		Return(&model.URLEntity{
+			ID:          12,
+			UserID:      3,
+			ShortURL:    "0000C",
+			OriginalURL: "https://example.com/launch",
+			Status:      constant.URLStatusActive,
+			ActiveFrom:  &launch,
+			FallbackURL: "https://example.com/soon",
+			CreatedAt:   time.Now(),
+		}, nil)
+
+	cfg := testConfig()
+	cfg.Server.ForcePreviewUntrusted = true
+	handler, app := newTestHandler(t, urlRepo, nil, cfg)
+	fetcher := &stubFetcher{}
+	app.TitleFetcher = fetcher
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/url/0000C", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /url/0000C status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "https://example.com/launch") {
+		t.Fatalf("preview of a link not active yet shows its destination:\n%s", body)
+	}
+	if !strings.Contains(body, `href="https://example.com/soon"`) {
+		t.Fatalf("preview of a link not active yet does not continue to the fallback:\n%s", body)
+	}
+	if len(fetcher.fetched) != 1 || fetcher.fetched[0] != "https://example.com/soon" {
+		t.Fatalf("preview fetched the title of %v, want only the fallback", fetcher.fetched)
+	}
+}
+
+func TestRestHandler_ListRules_HidesDestinations(t *testing.T) {
+	launch := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		entity   model.URLEntity
+		wantHide bool
+	}{
+		{name: "public link", entity: model.URLEntity{}},
+		{name: "password protected link", entity: model.URLEntity{PasswordHash: "$2a$10$hash"}, wantHide: true},
+		{name: "single use link", entity: model.URLEntity{SingleUse: true}, wantHide: true},
+		{name: "link not active yet", entity: model.URLEntity{ActiveFrom: &launch}, wantHide: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity := tt.entity
+			entity.ID = 12
+			entity.ShortURL = "0000C"
+			entity.OriginalURL = "https://example.com/launch"
+			entity.Status = constant.URLStatusActive
+
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, mock.Anything).Return(&entity, nil)
+			ruleRepo := rulemocks.NewRuleRepository(t)
+			ruleRepo.On("List", mock.Anything, uint64(12)).Return([]*model.RuleEntity{{ID: 3, URLID: 12, OS: "ios", DestinationURL: "https://example.com/secret-ios"}}, nil).Once()
+			domainRepo := domainmocks.NewDomainRepository(t)
+			domainRepo.On("Get", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+			_, app := newTestHandler(t, urlRepo, nil, testConfig())
+			handler := transport.NewTransport(app, apprule.NewRuleApplication(urlRepo, ruleRepo, domainRepo), nil, nil, nil, nil, testConfig())
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/url/0000C/rules", nil))
+
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"os":"ios"`) {
+				t.Fatalf("GET /url/0000C/rules = %d:\n%s", rec.Code, rec.Body.String())
+			}
+			if hidden := !strings.Contains(rec.Body.String(), "secret-ios"); hidden != tt.wantHide {
+				t.Fatalf("GET /url/0000C/rules destination hidden = %v, want %v:\n%s", hidden, tt.wantHide, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRestHandler_GetURLStats_HidesDestinations(t *testing.T) {
+	launch := time.Now().Add(time.Hour)
+	tests := []struct {
+		name     string
+		entity   model.URLEntity
+		wantHide bool
+	}{
+		{name: "public link", entity: model.URLEntity{}},
+		{name: "password protected link", entity: model.URLEntity{PasswordHash: "$2a$10$hash"}, wantHide: true},
+		{name: "single use link", entity: model.URLEntity{SingleUse: true}, wantHide: true},
+		{name: "link not active yet", entity: model.URLEntity{ActiveFrom: &launch}, wantHide: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity := tt.entity
+			entity.ID = 12
+			entity.ShortURL = "0000C"
+			entity.OriginalURL = "https://example.com/launch"
+			entity.Status = constant.URLStatusActive
+
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, mock.Anything).Return(&entity, nil)
+			variants := []*model.VariantEntity{{ID: 1, URLID: 12, DestinationURL: "https://example.com/secret-a", Weight: 1}}
+			handler, _ := newTestHandler(t, urlRepo, variants, testConfig())
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/url/0000C/stats", nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET /url/0000C/stats status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if hidden := !strings.Contains(rec.Body.String(), "secret-a"); hidden != tt.wantHide {
+				t.Fatalf("GET /url/0000C/stats destination hidden = %v, want %v:\n%s", hidden, tt.wantHide, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRestHandler_ForwardedPath_ReservedSegments(t *testing.T) {
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, mock.Anything).Return(&model.URLEntity{
+		ID:          12,
+		ShortURL:    "0000C",
+		OriginalURL: "https://example.com/docs",
+		Status:      constant.URLStatusActive,
+		ForwardPath: true,
+		CreatedAt:   time.Now(),
+	}, nil)
+	urlRepo.On("RecordClick", mock.Anything, mock.Anything, true).Return(nil).Maybe()
+	handler, _ := newTestHandler(t, urlRepo, nil, testConfig())
+
+	tests := []struct {
+		path         string
+		wantLocation string
+	}{
+		{path: "/url/0000C/guide/intro", wantLocation: "https://example.com/docs/guide/intro"},
+		// only GET routes take a path over forwarding, and only the exact segment
+		{path: "/url/0000C/tags", wantLocation: "https://example.com/docs/tags"},
+		{path: "/url/0000C/rules/7", wantLocation: "https://example.com/docs/rules/7"},
+		{path: "/url/0000C/info/more", wantLocation: "https://example.com/docs/info/more"},
+		{path: "/url/0000C/info"},
+		{path: "/url/0000C/stats"},
+		{path: "/url/0000C/qr"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if got := rec.Header().Get("Location"); got != tt.wantLocation {
+			t.Fatalf("GET %s Location = %q, want %q (status %d)", tt.path, got, tt.wantLocation, rec.Code)
+		}
+	}
+}
+
+func TestRestHandler_Unavailable_UsesAppClock(t *testing.T) {
+	// the launch is in the past of the machine but in the future of the application clock
+	clock := time.Date(2020, 3, 1, 9, 0, 0, 0, time.UTC)
+	launch := clock.Add(time.Hour)
+	tests := []struct {
+		name       string
+		entity     model.URLEntity
+		wantNotYet bool
+	}{
+		{name: "before launch", entity: model.URLEntity{ActiveFrom: &launch}, wantNotYet: true},
+		{name: "after active until", entity: model.URLEntity{ActiveUntil: &clock}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity := tt.entity
+			entity.ID = 12
+			entity.ShortURL = "0000C"
+			entity.OriginalURL = "https://example.com/launch"
+			entity.Status = constant.URLStatusActive
+
+			urlRepo := urlmocks.NewURLRepository(t)
+			urlRepo.On("Get", mock.Anything, mock.Anything).Return(&entity, nil)
+			handler, app := newTestHandler(t, urlRepo, nil, testConfig())
+			app.Now = func() time.Time { return clock }
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/url/0000C", nil))
+
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("GET /url/0000C status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+			if notYet := strings.Contains(rec.Body.String(), "Not yet available"); notYet != tt.wantNotYet {
+				t.Fatalf("GET /url/0000C not yet = %v, want %v:\n%s", notYet, tt.wantNotYet, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRestHandler_Unlock_KeepsForwardedPath(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlRepo := urlmocks.NewURLRepository(t)
+	urlRepo.On("Get", mock.Anything, mock.Anything).Return(&model.URLEntity{
+		ID:           12,
+		ShortURL:     "0000C",
+		OriginalURL:  "https://example.com/docs",
+		Status:       constant.URLStatusActive,
+		ForwardPath:  true,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now(),
+	}, nil)
+	handler, _ := newTestHandler(t, urlRepo, nil, testConfig())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/url/0000C/guide/intro?a=1", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	for _, field := range []string{`name="path" value="guide/intro"`, `name="query" value="a=1"`} {
+		if !strings.Contains(rec.Body.String(), field) {
+			t.Fatalf("password form misses %s:\n%s", field, rec.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name         string
+		form         neturl.Values
+		wantLocation string
+	}{
+		{name: "forwarded path and query", form: neturl.Values{"path": {"guide/intro"}, "query": {"a=1"}}, wantLocation: "/url/0000C/guide/intro?a=1"},
+		{name: "no path", form: neturl.Values{}, wantLocation: "/url/0000C"},
+		{name: "query that doesn't parse", form: neturl.Values{"query": {"a=%zz"}}, wantLocation: "/url/0000C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.form.Set("password", "s3cret")
+			r := httptest.NewRequest(http.MethodPost, "/url/0000C", strings.NewReader(tt.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("POST status = %d, want %d", rec.Code, http.StatusSeeOther)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Fatalf("POST Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@ package transport
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
 	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
 )
 
@@ -33,6 +35,41 @@ func writeError(w http.ResponseWriter, err error) {
 	writeJson(w, customError.ErrorHTTPCode(), data)
 }
 
+// acceptsJSON checks if the client prefers a JSON response over a redirect
+func acceptsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// baseURLFromRequest builds the base url of the host the request was sent to,
+// X-Forwarded-Proto is only used when the proxy headers are trusted
+func (s *RestHandler) baseURLFromRequest(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if s.Config.Server.TrustProxyHeaders {
+		if forwarded := s.forwardedValue(r, "X-Forwarded-Proto"); forwarded == "https" || forwarded == "http" {
+			scheme = forwarded
+		}
+	}
+	return scheme + "://" + s.requestHost(r)
+}
+
+// fillLinks completes the absolute links the application could not build
+// because no public base url is configured
+func (s *RestHandler) fillLinks(r *http.Request, resp *model.GetURLResponse) {
+	base := s.baseURLFromRequest(r)
+	if resp.ShortLink == "" {
+		resp.ShortLink = base + "/url/" + resp.ShortURL
+	}
+	if resp.QRURL == "" {
+		resp.QRURL = base + "/url/" + resp.ShortURL + "/qr"
+	}
+	if resp.InfoURL == "" {
+		resp.InfoURL = base + "/url/" + resp.ShortURL + "/info"
+	}
+}
+
 func writeSuccess(w http.ResponseWriter, data interface{}) {
 	writeJson(w, http.StatusOK, body{
 		Code:    constant.ErrorTypeCode[constant.Successful],
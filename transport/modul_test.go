@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestHandler_baseURLFromRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy bool
+		tls        bool
+		headers    map[string]string
+		want       string
+	}{
+		{name: "plain request", want: "http://internal:8080"},
+		{name: "tls request", tls: true, want: "https://internal:8080"},
+		{
+			name:    "proxy headers not trusted",
+			headers: map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "go.example.com"},
+			want:    "http://internal:8080",
+		},
+		{
+			name:       "proxy headers trusted",
+			trustProxy: true,
+			headers:    map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "go.example.com"},
+			want:       "https://go.example.com",
+		},
+		{
+			name:       "spoofed scheme on the left is ignored",
+			trustProxy: true,
+			headers:    map[string]string{"X-Forwarded-Proto": "https, http"},
+			want:       "http://internal:8080",
+		},
+		{
+			name:       "unknown scheme is ignored",
+			trustProxy: true,
+			headers:    map[string]string{"X-Forwarded-Proto": "javascript"},
+			want:       "http://internal:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/url", nil)
+			r.Host = "internal:8080"
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			if got := newCookieTestHandler(tt.trustProxy, 1).baseURLFromRequest(r); got != tt.want {
+				t.Fatalf("baseURLFromRequest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// @Summary Create tag
+// @Description Create a tag, tags are also created when first put on a short URL
+// @Accept json
+// @Produce json
+// @Param request body model.CreateTagRequest true "Tag"
+// @Success 200 {object} model.GetTagResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /tag [post]
+func (s *RestHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.TagApp.CreateTag(r.Context(), &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary List tags
+// @Produce json
+// @Success 200 {array} model.GetTagResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /tag [get]
+func (s *RestHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	data, err := s.TagApp.ListTags(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Delete tag
+// @Description Delete a tag and remove it from its short URLs
+// @Produce json
+// @Param tagID path int true "Tag ID"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /tag/{tagID} [delete]
+func (s *RestHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.ParseUint(mux.Vars(r)["tagID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.TagApp.DeleteTag(r.Context(), tagID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
+
+// @Summary Set short URL tags
+// @Description Replace the tags of a short URL, missing tags are created
+// @Accept json
+// @Produce json
+// @Param shortURL path string true "Short URL"
+// @Param request body model.SetURLTagsRequest true "Tags"
+// @Success 200 {array} string
+// @Failure 400 {object} errors.CustomError
+// @Router /url/{shortURL}/tags [put]
+func (s *RestHandler) SetURLTags(w http.ResponseWriter, r *http.Request) {
+	var req model.SetURLTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.URLApp.SetURLTags(r.Context(), s.requestHost(r), mux.Vars(r)["shortURL"], &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
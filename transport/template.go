@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+func writeHTML(w http.ResponseWriter, statusCode int, name string, data interface{}) {
+	w.Header().Set("Content-type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		log.Println("[writeHTML] err ExecuteTemplate", name, err)
+	}
+}
+
+// passwordPage keeps the forwarded path and query of the visit so the visitor gets back to them once unlocked
+type passwordPage struct {
+	ShortURL string
+	Path     string
+	Query    string
+	Message  string
+}
+
+func writePasswordForm(w http.ResponseWriter, statusCode int, shortURL, path, query, message string) {
+	w.Header().Set("Cache-Control", "private, no-cache, no-store, must-revalidate")
+	writeHTML(w, statusCode, "password.html", passwordPage{
+		ShortURL: shortURL,
+		Path:     path,
+		Query:    query,
+		Message:  message,
+	})
+}
+
+type unavailablePage struct {
+	ShortURL   string
+	ActiveFrom *time.Time
+	NotYet     bool
+}
@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/model"
+	"github.com/muhammadheryan/url-shortner-base62/utils/errors"
+)
+
+// @Summary Register webhook
+// @Description Register an endpoint receiving link events as JSON signed with HMAC-SHA256 in the X-Webhook-Signature header, the secret is only returned here
+// @Accept json
+// @Produce json
+// @Param request body model.CreateWebhookRequest true "Webhook"
+// @Success 200 {object} model.GetWebhookResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /webhook [post]
+func (s *RestHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.WebhookApp.CreateWebhook(r.Context(), &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary List webhooks
+// @Produce json
+// @Success 200 {array} model.GetWebhookResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /webhook [get]
+func (s *RestHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	data, err := s.WebhookApp.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Get webhook
+// @Produce json
+// @Param webhookID path int true "Webhook ID"
+// @Success 200 {object} model.GetWebhookResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /webhook/{webhookID} [get]
+func (s *RestHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.WebhookApp.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Delete webhook
+// @Description Delete a webhook with its pending deliveries and dead letters
+// @Produce json
+// @Param webhookID path int true "Webhook ID"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /webhook/{webhookID} [delete]
+func (s *RestHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.WebhookApp.DeleteWebhook(r.Context(), webhookID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
+
+// @Summary List webhook dead letters
+// @Description List the events not delivered after the last retry, newest first
+// @Produce json
+// @Param webhookID path int true "Webhook ID"
+// @Success 200 {array} model.GetWebhookDeadLetterResponse
+// @Failure 400 {object} errors.CustomError
+// @Router /webhook/{webhookID}/dead-letters [get]
+func (s *RestHandler) ListWebhookDeadLetters(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	data, err := s.WebhookApp.ListDeadLetters(r.Context(), webhookID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, data)
+}
+
+// @Summary Replay webhook dead letter
+// @Description Queue a dead letter again, it is sent with the same event id so receivers can drop duplicates
+// @Produce json
+// @Param webhookID path int true "Webhook ID"
+// @Param deadLetterID path int true "Dead letter ID"
+// @Success 200 {object} transport.body
+// @Failure 400 {object} errors.CustomError
+// @Router /webhook/{webhookID}/dead-letters/{deadLetterID}/replay [post]
+func (s *RestHandler) ReplayWebhookDeadLetter(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.ParseUint(vars["webhookID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+	deadLetterID, err := strconv.ParseUint(vars["deadLetterID"], 10, 64)
+	if err != nil {
+		writeError(w, errors.SetCustomError(constant.ErrInvalidRequest))
+		return
+	}
+
+	if err := s.WebhookApp.ReplayDeadLetter(r.Context(), webhookID, deadLetterID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeSuccess(w, nil)
+}
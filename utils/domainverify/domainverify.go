@@ -0,0 +1,76 @@
+package domainverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+)
+
+// WellKnownPath is where a tenant serves the verification token of its domain
+const WellKnownPath = "/.well-known/url-shortner-verification"
+
+// maxBodySize limits how much of the served token is read
+const maxBodySize = 1024
+
+var ErrTokenMismatch = errors.New("served token does not match")
+
+// Verifier checks a tenant controls a domain
+type Verifier interface {
+	Verify(ctx context.Context, host, token string) error
+}
+
+type HTTPVerifier struct {
+	client *http.Client
+	scheme string
+}
+
+// NewVerifier fetches the token over scheme, https in production while tests may use http.
+// Hosts are supplied by tenants, internal addresses are refused unless allowPrivate is set
+func NewVerifier(scheme string, timeout time.Duration, allowPrivate bool) Verifier {
+	return &HTTPVerifier{
+		client: netguard.NewClient(timeout, allowPrivate),
+		scheme: scheme,
+	}
+}
+
+// VerificationURL returns the url the token of host is fetched from
+func VerificationURL(scheme, host string) string {
+	return scheme + "://" + host + WellKnownPath
+}
+
+func (v *HTTPVerifier) Verify(ctx context.Context, host, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, VerificationURL(v.scheme, host), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	served, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(served)) != token {
+		return ErrTokenMismatch
+	}
+	return nil
+}
+
+// NormalizeHost lowercases host and drops a trailing dot so lookups match the registered domain
+func NormalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
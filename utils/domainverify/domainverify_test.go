@@ -0,0 +1,67 @@
+package domainverify_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/domainverify"
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+)
+
+func TestHTTPVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		served  string
+		wantErr bool
+	}{
+		{name: "token served", status: http.StatusOK, served: "token-123"},
+		{name: "token served with trailing newline", status: http.StatusOK, served: "token-123\n"},
+		{name: "other token", status: http.StatusOK, served: "token-456", wantErr: true},
+		{name: "not served", status: http.StatusNotFound, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != domainverify.WellKnownPath {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.served))
+			}))
+			defer server.Close()
+
+			verifier := domainverify.NewVerifier("http", time.Second, true)
+			host := strings.TrimPrefix(server.URL, "http://")
+
+			err := verifier.Verify(context.Background(), host, "token-123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPVerifier_Verify_ReservedAddress(t *testing.T) {
+	var received bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+		_, _ = w.Write([]byte("token-123"))
+	}))
+	defer server.Close()
+
+	verifier := domainverify.NewVerifier("http", time.Second, false)
+	err := verifier.Verify(context.Background(), strings.TrimPrefix(server.URL, "http://"), "token-123")
+	if !errors.Is(err, netguard.ErrReservedAddress) {
+		t.Fatalf("Verify() error = %v, want ErrReservedAddress", err)
+	}
+	if received {
+		t.Fatalf("Verify() reached the loopback host")
+	}
+}
@@ -1,6 +1,29 @@
 package errors
 
-import "github.com/muhammadheryan/url-shortner-base62/constant"
+import (
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifies the service in the details of gRPC errors
+const errorDomain = "url-shortner"
+
+// grpcCodes maps the error types to the gRPC code closest to their HTTP status
+var grpcCodes = map[constant.ErrorType]codes.Code{
+	constant.Successful:            codes.OK,
+	constant.ErrInternal:           codes.Internal,
+	constant.ErrNotFound:           codes.NotFound,
+	constant.ErrInvalidRequest:     codes.InvalidArgument,
+	constant.ErrUnauthorize:        codes.Unauthenticated,
+	constant.ErrPasswordRequired:   codes.Unauthenticated,
+	constant.ErrTooManyRequests:    codes.ResourceExhausted,
+	constant.ErrGone:               codes.NotFound,
+	constant.ErrNotAvailable:       codes.FailedPrecondition,
+	constant.ErrVerificationFailed: codes.FailedPrecondition,
+	constant.ErrConflict:           codes.AlreadyExists,
+}
 
 type CustomError struct {
 	errType constant.ErrorType
@@ -18,8 +41,28 @@ func (c CustomError) ErrorHTTPCode() int {
 	return constant.ErrorTypeHTTPCode[c.errType]
 }
 
+// GRPCStatus converts the error to a gRPC status, the error code is kept as the reason
+// of an ErrorInfo detail so gRPC clients can tell the errors apart like REST clients
+func (c CustomError) GRPCStatus() *status.Status {
+	st := status.New(grpcCodes[c.errType], c.Error())
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: c.ErrorCode(),
+		Domain: errorDomain,
+	})
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
 func SetCustomError(errorType constant.ErrorType) CustomError {
 	return CustomError{
 		errType: errorType,
 	}
 }
+
+// Is checks if err is a CustomError of the given type
+func Is(err error, errorType constant.ErrorType) bool {
+	customError, ok := err.(CustomError)
+	return ok && customError.errType == errorType
+}
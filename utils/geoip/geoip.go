@@ -0,0 +1,70 @@
+package geoip
+
+import (
+	"io"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Locator resolves the ISO 3166-1 alpha-2 country code of an IP address
+type Locator interface {
+	Country(ip string) string
+}
+
+// Close releases the database of locator when it has one, to be called once it is no longer used
+func Close(locator Locator) error {
+	if closer, ok := locator.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// MaxMind reads countries from a local MaxMind-format (mmdb) database such as GeoLite2-Country
+type MaxMind struct {
+	reader *maxminddb.Reader
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// NewLocator opens the database at path, an empty path gives a locator that knows no country
+func NewLocator(path string) (Locator, error) {
+	if path == "" {
+		return noopLocator{}, nil
+	}
+
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaxMind{reader: reader}, nil
+}
+
+func (m *MaxMind) Country(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	var record countryRecord
+	if err := m.reader.Lookup(parsed, &record); err != nil {
+		return ""
+	}
+	return record.Country.ISOCode
+}
+
+// Close unmaps the database file
+func (m *MaxMind) Close() error {
+	return m.reader.Close()
+}
+
+type noopLocator struct{}
+
+func (noopLocator) Country(string) string {
+	return ""
+}
@@ -0,0 +1,101 @@
+package geoip_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/geoip"
+)
+
+// countryDatabase builds an IPv4 mmdb file with a single node: the addresses whose first
+// bit is 0 (0.0.0.0/1) are in GB and the others in no country
+func countryDatabase(t *testing.T) string {
+	t.Helper()
+
+	var db []byte
+	// search tree, 24 bit records: left points to the first data record (node count + 16),
+	// right is the node count meaning no data
+	db = append(db, 0x00, 0x00, 0x11, 0x00, 0x00, 0x01)
+	// data section separator
+	db = append(db, make([]byte, 16)...)
+	// {"country": {"iso_code": "GB"}}
+	db = append(db, 0xe1, 0x47)
+	db = append(db, "country"...)
+	db = append(db, 0xe1, 0x48)
+	db = append(db, "iso_code"...)
+	db = append(db, 0x42)
+	db = append(db, "GB"...)
+	// metadata
+	db = append(db, "\xab\xcd\xefMaxMind.com"...)
+	db = append(db, 0xe6)
+	db = append(db, 0x4a)
+	db = append(db, "node_count"...)
+	db = append(db, 0xc1, 0x01)
+	db = append(db, 0x4b)
+	db = append(db, "record_size"...)
+	db = append(db, 0xa1, 0x18)
+	db = append(db, 0x4a)
+	db = append(db, "ip_version"...)
+	db = append(db, 0xa1, 0x04)
+	db = append(db, 0x4d)
+	db = append(db, "database_type"...)
+	db = append(db, 0x44)
+	db = append(db, "Test"...)
+	db = append(db, 0x5b)
+	db = append(db, "binary_format_major_version"...)
+	db = append(db, 0xa1, 0x02)
+	db = append(db, 0x5b)
+	db = append(db, "binary_format_minor_version"...)
+	db = append(db, 0xa0)
+
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	if err := os.WriteFile(path, db, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestMaxMind_Country(t *testing.T) {
+	locator, err := geoip.NewLocator(countryDatabase(t))
+	if err != nil {
+		t.Fatalf("NewLocator() error = %v", err)
+	}
+	defer geoip.Close(locator)
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "81.2.69.160", want: "GB"},
+		{ip: "127.0.0.1", want: "GB"},
+		{ip: "203.0.113.7", want: ""},
+		{ip: "2001:db8::1", want: ""},
+		{ip: "not an ip", want: ""},
+		{ip: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := locator.Country(tt.ip); got != tt.want {
+				t.Fatalf("Country(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLocator(t *testing.T) {
+	locator, err := geoip.NewLocator("")
+	if err != nil {
+		t.Fatalf("NewLocator(\"\") error = %v", err)
+	}
+	if got := locator.Country("81.2.69.160"); got != "" {
+		t.Fatalf("Country() without database = %q, want no country", got)
+	}
+	if err := geoip.Close(locator); err != nil {
+		t.Fatalf("Close() without database error = %v", err)
+	}
+
+	if _, err := geoip.NewLocator(filepath.Join(t.TempDir(), "missing.mmdb")); err == nil {
+		t.Fatalf("NewLocator() of a missing file error = nil")
+	}
+}
@@ -0,0 +1,61 @@
+package language
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the lower cased language tags of an Accept-Language
+// header ordered by preference, tags with q=0 are left out
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, tag.tag)
+	}
+	return result
+}
+
+// Matches checks if the wanted tag matches one of the accepted tags, a primary
+// tag such as "en" matches any region of it such as "en-us"
+func Matches(wanted string, accepted []string) bool {
+	wanted = strings.ToLower(wanted)
+	for _, tag := range accepted {
+		if tag == wanted || strings.HasPrefix(tag, wanted+"-") {
+			return true
+		}
+	}
+	return false
+}
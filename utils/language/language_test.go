@@ -0,0 +1,55 @@
+package language_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/language"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "empty", header: "", want: []string{}},
+		{name: "single tag", header: "id-ID", want: []string{"id-id"}},
+		{name: "ordered by quality", header: "en;q=0.5, fr-CH, de;q=0.9", want: []string{"fr-ch", "de", "en"}},
+		{name: "same quality keeps the order", header: "nl, en-GB, en;q=0.8", want: []string{"nl", "en-gb", "en"}},
+		{name: "refused tags left out", header: "fr;q=0, en", want: []string{"en"}},
+		{name: "wildcard left out", header: "*, es;q=0.7", want: []string{"es"}},
+		{name: "invalid quality left out", header: "ja;q=high, ko", want: []string{"ko"}},
+		{name: "blank entries", header: " , en ,", want: []string{"en"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := language.ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	accepted := []string{"en-us", "id"}
+	tests := []struct {
+		wanted string
+		want   bool
+	}{
+		{wanted: "en", want: true},
+		{wanted: "EN-US", want: true},
+		{wanted: "id", want: true},
+		{wanted: "en-gb", want: false},
+		{wanted: "e", want: false},
+		{wanted: "id-id", want: false},
+		{wanted: "fr", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wanted, func(t *testing.T) {
+			if got := language.Matches(tt.wanted, accepted); got != tt.want {
+				t.Fatalf("Matches(%q, %v) = %v, want %v", tt.wanted, accepted, got, tt.want)
+			}
+		})
+	}
+}
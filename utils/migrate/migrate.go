@@ -0,0 +1,292 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+)
+
+// Markers splitting a migration file in its up and down parts, the format of dbmate
+const (
+	markerUp   = "-- migrate:up"
+	markerDown = "-- migrate:down"
+)
+
+// lockName is the advisory lock held while migrating so replicas starting together
+// apply every migration once
+const lockName = "url-shortner:migrate"
+
+// lockPollInterval is how often Postgres is asked for the lock, it has no lock with timeout
+const lockPollInterval = 500 * time.Millisecond
+
+// createVersionTableQuery keeps the table of dbmate so both tools can be used on a database
+const createVersionTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(128) PRIMARY KEY)`
+
+var (
+	ErrLockTimeout   = errors.New("timeout waiting for the migration lock")
+	ErrNothingToUndo = errors.New("no migration to roll back")
+)
+
+// Migration is one file of the migrations directory, named {version}_{description}.sql
+type Migration struct {
+	Version string
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells if a migration was applied
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// Load reads the migrations of fsys ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, err := parse(name, string(content))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration)
+	}
+
+	return migrations, nil
+}
+
+func parse(name string, content string) (Migration, error) {
+	version, _, ok := strings.Cut(strings.TrimSuffix(path.Base(name), ".sql"), "_")
+	if !ok || version == "" {
+		return Migration{}, fmt.Errorf("migration %s: name must start with a version", name)
+	}
+
+	upIndex := strings.Index(content, markerUp)
+	if upIndex < 0 {
+		return Migration{}, fmt.Errorf("migration %s: missing %q", name, markerUp)
+	}
+	up := content[upIndex+len(markerUp):]
+
+	var down string
+	if downIndex := strings.Index(up, markerDown); downIndex >= 0 {
+		down = up[downIndex+len(markerDown):]
+		up = up[:downIndex]
+	}
+
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      strings.TrimSpace(up),
+		Down:    strings.TrimSpace(down),
+	}, nil
+}
+
+// Migrator applies the migrations to a MySQL, Postgres or SQLite database, MySQL connections
+// must allow multiple statements per query (multiStatements=true)
+type Migrator struct {
+	db          *sqlx.DB
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+func NewMigrator(db *sqlx.DB, fsys fs.FS, lockTimeout time.Duration) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		lockTimeout: lockTimeout,
+	}, nil
+}
+
+// Up applies the pending migrations in order and returns them
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if versions[migration.Version] {
+				continue
+			}
+			if err := run(ctx, conn, migration.Up, "INSERT INTO schema_migrations (version) VALUES (?)", migration.Version); err != nil {
+				return fmt.Errorf("migration %s: %w", migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls the latest applied migration back and returns it
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var rolledBack *Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if !versions[migration.Version] {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %s: no down migration", migration.Name)
+			}
+			if err := run(ctx, conn, migration.Down, "DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+				return fmt.Errorf("migration %s: %w", migration.Name, err)
+			}
+			rolledBack = &migration
+			return nil
+		}
+		return ErrNothingToUndo
+	})
+
+	return rolledBack, err
+}
+
+// Status lists every migration and whether it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	versions, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, Status{Migration: migration, Applied: versions[migration.Version]})
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on one connection holding the advisory lock, MySQL releases the
+// lock by itself when the connection is lost. SQLite has no advisory lock, its file
+// belongs to a single instance.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	switch m.db.DriverName() {
+	case constant.DriverSQLite:
+		return fn(conn)
+	case constant.DriverPostgres:
+		if err := m.lockPostgres(ctx, conn); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+		return fn(conn)
+	}
+
+	var locked sql.NullInt64
+	if err := conn.GetContext(ctx, &locked, "SELECT GET_LOCK(?, ?)", lockName, int(m.lockTimeout.Seconds())); err != nil {
+		return err
+	}
+	if locked.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	defer conn.ExecContext(context.Background(), "DO RELEASE_LOCK(?)", lockName)
+
+	return fn(conn)
+}
+
+// lockPostgres waits for the advisory lock, Postgres releases it by itself when the
+// connection is lost too
+func (m *Migrator) lockPostgres(ctx context.Context, conn *sqlx.Conn) error {
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		var locked bool
+		if err := conn.GetContext(ctx, &locked, "SELECT pg_try_advisory_lock(hashtext($1))", lockName); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[string]bool, error) {
+	if _, err := conn.ExecContext(ctx, createVersionTableQuery); err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	if err := conn.SelectContext(ctx, &versions, "SELECT version FROM schema_migrations"); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		applied[version] = true
+	}
+	return applied, nil
+}
+
+// run executes the statements of a migration and records its version in one transaction,
+// MySQL still commits schema changes right away so a failing migration may be half applied
+// there while Postgres and SQLite roll it back
+func run(ctx context.Context, conn *sqlx.Conn, statements string, versionQuery string, version string) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if statements != "" {
+		if _, err := tx.ExecContext(ctx, statements); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(versionQuery), version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
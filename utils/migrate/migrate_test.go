@@ -0,0 +1,77 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"github.com/muhammadheryan/url-shortner-base62/db/migrations"
+	"github.com/muhammadheryan/url-shortner-base62/utils/migrate"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20261018091000_add_status.sql": {Data: []byte("-- migrate:up\nALTER TABLE url ADD status VARCHAR(16);\n\n-- migrate:down\nALTER TABLE url DROP COLUMN status;\n")},
+		"20250827113104_init.sql":       {Data: []byte("-- migrate:up\nCREATE TABLE url (id BIGINT);\nCREATE INDEX idx ON url (id);\n")},
+		"README.md":                     {Data: []byte("not a migration")},
+	}
+
+	got, err := migrate.Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Load() = %d migrations, want 2", len(got))
+	}
+
+	// ordered by version whatever the order of the files
+	if got[0].Version != "20250827113104" || got[0].Up != "CREATE TABLE url (id BIGINT);\nCREATE INDEX idx ON url (id);" || got[0].Down != "" {
+		t.Fatalf("first migration = %+v", got[0])
+	}
+	if got[1].Version != "20261018091000" || got[1].Name != "20261018091000_add_status.sql" ||
+		got[1].Up != "ALTER TABLE url ADD status VARCHAR(16);" || got[1].Down != "ALTER TABLE url DROP COLUMN status;" {
+		t.Fatalf("second migration = %+v", got[1])
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{name: "missing up marker", fsys: fstest.MapFS{"20250827113104_init.sql": {Data: []byte("CREATE TABLE url (id BIGINT);")}}},
+		{name: "missing version", fsys: fstest.MapFS{"init.sql": {Data: []byte("-- migrate:up\nCREATE TABLE url (id BIGINT);")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := migrate.Load(tt.fsys); err == nil {
+				t.Fatalf("Load() error = nil, want an error")
+			}
+		})
+	}
+}
+
+// the embedded migrations must all be reversible so `migrate down` works on any of them
+func TestLoad_Embedded(t *testing.T) {
+	for driver := range constant.Drivers {
+		t.Run(driver, func(t *testing.T) {
+			fsys, err := migrations.ForDriver(driver)
+			if err != nil {
+				t.Fatalf("ForDriver() error = %v", err)
+			}
+
+			got, err := migrate.Load(fsys)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(got) == 0 {
+				t.Fatalf("Load() found no embedded migration")
+			}
+			for _, migration := range got {
+				if migration.Up == "" || migration.Down == "" {
+					t.Errorf("migration %s has an empty up or down part", migration.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	gonats "github.com/nats-io/nats.go"
+)
+
+// clientName identifies the service in the connections listed by the NATS server
+const clientName = "url-shortner"
+
+// Publisher sends messages to a NATS server
+type Publisher interface {
+	Publish(ctx context.Context, subject string, msgID string, data []byte) error
+}
+
+type Client struct {
+	conn    *gonats.Conn
+	timeout time.Duration
+}
+
+// NewClient connects to the NATS server at url, the connection is restored in the
+// background when the server goes away
+func NewClient(url string, timeout time.Duration) (*Client, error) {
+	conn, err := gonats.Connect(url,
+		gonats.Name(clientName),
+		gonats.Timeout(timeout),
+		gonats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{conn: conn, timeout: timeout}, nil
+}
+
+// Publish sends data on subject and waits until the server received it. The message id
+// lets JetStream streams drop the copies of a message published twice.
+func (c *Client) Publish(ctx context.Context, subject string, msgID string, data []byte) error {
+	msg := gonats.NewMsg(subject)
+	msg.Header.Set(gonats.MsgIdHdr, msgID)
+	msg.Data = data
+
+	if err := c.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.conn.FlushWithContext(ctx)
+}
+
+// Close sends the buffered messages and closes the connection
+func (c *Client) Close() error {
+	return c.conn.Drain()
+}
@@ -0,0 +1,84 @@
+package nats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/nats"
+	"github.com/nats-io/nats-server/v2/server"
+	gonats "github.com/nats-io/nats.go"
+)
+
+// runServer starts an embedded NATS server on a random port
+func runServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, NoLog: true, NoSigs: true})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatalf("NATS server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+func TestClient_Publish(t *testing.T) {
+	srv := runServer(t)
+
+	subscriber, err := gonats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer subscriber.Close()
+	sub, err := subscriber.SubscribeSync("links.>")
+	if err != nil {
+		t.Fatalf("SubscribeSync() error = %v", err)
+	}
+	if err := subscriber.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	client, err := nats.NewClient(srv.ClientURL(), time.Second)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Publish(context.Background(), "links.link.created", "evt-1", []byte(`{"event":"link.created"}`)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	msg, err := sub.NextMsg(time.Second)
+	if err != nil {
+		t.Fatalf("NextMsg() error = %v", err)
+	}
+	if msg.Subject != "links.link.created" || string(msg.Data) != `{"event":"link.created"}` {
+		t.Fatalf("message = %s %s", msg.Subject, msg.Data)
+	}
+	if msg.Header.Get(gonats.MsgIdHdr) != "evt-1" {
+		t.Fatalf("message id = %q, want evt-1", msg.Header.Get(gonats.MsgIdHdr))
+	}
+}
+
+func TestClient_PublishServerDown(t *testing.T) {
+	srv := runServer(t)
+
+	client, err := nats.NewClient(srv.ClientURL(), 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	defer client.Close()
+
+	srv.Shutdown()
+	srv.WaitForShutdown()
+
+	// the message is buffered for the reconnect but never confirmed by a server
+	if err := client.Publish(context.Background(), "links.link.created", "evt-1", []byte(`{}`)); err == nil {
+		t.Fatalf("Publish() error = nil, want an error while the server is down")
+	}
+}
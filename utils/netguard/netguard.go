@@ -0,0 +1,109 @@
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is how many redirects a guarded client follows before giving up
+const maxRedirects = 10
+
+var ErrReservedAddress = errors.New("destination is a reserved address")
+
+// reservedPrefixes are the special purpose ranges not covered by the net.IP helpers
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsReserved checks if ip is a loopback, private, link-local, multicast or other
+// special purpose address that user supplied urls must not reach
+func IsReserved(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Control is a net.Dialer Control refusing connections to reserved addresses, it runs
+// after name resolution so hosts resolving to an internal address are refused too
+func Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsReserved(ip) {
+		return fmt.Errorf("%w: %s", ErrReservedAddress, host)
+	}
+	return nil
+}
+
+// CheckRedirect is an http.Client CheckRedirect checking every hop before it is followed,
+// only http and https destinations outside the reserved ranges are followed
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("unsupported redirect scheme %q", req.URL.Scheme)
+	}
+
+	host := req.URL.Hostname()
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%w: %s", ErrReservedAddress, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && IsReserved(ip) {
+		return fmt.Errorf("%w: %s", ErrReservedAddress, host)
+	}
+	return nil
+}
+
+// NewClient returns a client for user supplied urls, it refuses reserved addresses on the
+// first request and every redirect unless allowPrivate is set for local development
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return &http.Client{Timeout: timeout}
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the destination and bypass the guard
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: CheckRedirect,
+	}
+}
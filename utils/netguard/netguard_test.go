@@ -0,0 +1,116 @@
+package netguard_test
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+)
+
+func TestIsReserved(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "127.0.0.1", want: true},
+		{ip: "10.1.2.3", want: true},
+		{ip: "172.16.0.1", want: true},
+		{ip: "192.168.1.1", want: true},
+		{ip: "169.254.169.254", want: true},
+		{ip: "100.64.0.1", want: true},
+		{ip: "0.0.0.0", want: true},
+		{ip: "224.0.0.1", want: true},
+		{ip: "::1", want: true},
+		{ip: "fe80::1", want: true},
+		{ip: "fd00::1", want: true},
+		{ip: "::ffff:127.0.0.1", want: true},
+		{ip: "::ffff:169.254.169.254", want: true},
+		{ip: "93.184.216.34", want: false},
+		{ip: "8.8.8.8", want: false},
+		{ip: "2606:4700:4700::1111", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := netguard.IsReserved(net.ParseIP(tt.ip)); got != tt.want {
+				t.Fatalf("IsReserved(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://example.com/", nil)}
+	tests := []struct {
+		name     string
+		location string
+		wantErr  bool
+		reserved bool
+	}{
+		{name: "public host", location: "https://example.org/page"},
+		{name: "metadata address", location: "http://169.254.169.254/latest/meta-data/", wantErr: true, reserved: true},
+		{name: "loopback address", location: "http://127.0.0.1:8080/admin", wantErr: true, reserved: true},
+		{name: "ipv6 loopback", location: "http://[::1]/", wantErr: true, reserved: true},
+		{name: "localhost", location: "http://localhost/", wantErr: true, reserved: true},
+		{name: "other scheme", location: "file:///etc/passwd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := url.Parse(tt.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = netguard.CheckRedirect(&http.Request{URL: location}, via)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckRedirect(%s) error = %v, wantErr %v", tt.location, err, tt.wantErr)
+			}
+			if tt.reserved && !errors.Is(err, netguard.ErrReservedAddress) {
+				t.Fatalf("CheckRedirect(%s) error = %v, want ErrReservedAddress", tt.location, err)
+			}
+		})
+	}
+
+	location, _ := url.Parse("https://example.org/")
+	tooMany := make([]*http.Request, 10)
+	if err := netguard.CheckRedirect(&http.Request{URL: location}, tooMany); err == nil {
+		t.Fatalf("CheckRedirect() followed more than 10 redirects")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := netguard.NewClient(time.Second, false).Get(server.URL)
+	if !errors.Is(err, netguard.ErrReservedAddress) {
+		t.Fatalf("guarded client error = %v, want ErrReservedAddress", err)
+	}
+
+	resp, err := netguard.NewClient(time.Second, true).Get(server.URL)
+	if err != nil {
+		t.Fatalf("client allowing private destinations error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewClient_RedirectToReservedAddress(t *testing.T) {
+	// The dial guard is left out to reach the loopback test server, the redirect
+	// must still be refused by CheckRedirect
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := netguard.NewClient(time.Second, false)
+	client.Transport = http.DefaultTransport
+	_, err := client.Get(server.URL)
+	if !errors.Is(err, netguard.ErrReservedAddress) {
+		t.Fatalf("redirect to metadata address error = %v, want ErrReservedAddress", err)
+	}
+}
@@ -0,0 +1,85 @@
+package pagetitle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+	"golang.org/x/net/html"
+)
+
+// maxBodySize limits how much of the destination page is read to find the title
+const maxBodySize = 512 * 1024
+
+var ErrNotHTML = errors.New("destination is not an html page")
+
+// Fetcher fetches the <title> of a web page
+type Fetcher interface {
+	Fetch(ctx context.Context, pageURL string) (string, error)
+}
+
+type HTTPFetcher struct {
+	client *http.Client
+}
+
+// NewFetcher fetches titles of user supplied destinations, internal addresses are
+// refused unless allowPrivate is set
+func NewFetcher(timeout time.Duration, allowPrivate bool) Fetcher {
+	return &HTTPFetcher{
+		client: netguard.NewClient(timeout, allowPrivate),
+	}
+}
+
+func (f *HTTPFetcher) Fetch(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", ErrNotHTML
+	}
+
+	return parseTitle(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// parseTitle returns the text of the first <title> element
+func parseTitle(r io.Reader) (string, error) {
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if tokenizer.Err() == io.EOF {
+				return "", nil
+			}
+			return "", tokenizer.Err()
+		case html.StartTagToken:
+			name, _ := tokenizer.TagName()
+			inTitle = string(name) == "title"
+		case html.TextToken:
+			if inTitle {
+				return strings.Join(strings.Fields(string(tokenizer.Text())), " "), nil
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if string(name) == "head" {
+				return "", nil
+			}
+			inTitle = false
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package pagetitle_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+	"github.com/muhammadheryan/url-shortner-base62/utils/pagetitle"
+)
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte("<html><head><title>Internal Admin</title></head></html>"))
+	}))
+	defer server.Close()
+
+	// The server is on loopback like internal services and cloud metadata endpoints
+	_, err := pagetitle.NewFetcher(time.Second, false).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, netguard.ErrReservedAddress) {
+		t.Fatalf("Fetch() error = %v, want ErrReservedAddress", err)
+	}
+
+	title, err := pagetitle.NewFetcher(time.Second, true).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch() with private destinations allowed error = %v", err)
+	}
+	if title != "Internal Admin" {
+		t.Fatalf("Fetch() = %q, want %q", title, "Internal Admin")
+	}
+}
@@ -0,0 +1,182 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	MinSize   = 64
+	MaxSize   = 2048
+	MaxMargin = 16
+)
+
+var ErrInvalidOption = errors.New("invalid qr option")
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Options controls how a QR code is rendered
+type Options struct {
+	Format     string
+	Size       int
+	Level      string
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+// DefaultOptions returns a black on white 256px PNG with medium error correction
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       256,
+		Level:      "M",
+		Margin:     4,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// Validate checks the options are in the supported ranges
+func (o Options) Validate() error {
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return fmt.Errorf("%w: format %q", ErrInvalidOption, o.Format)
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("%w: size %d", ErrInvalidOption, o.Size)
+	}
+	if _, ok := levels[o.Level]; !ok {
+		return fmt.Errorf("%w: level %q", ErrInvalidOption, o.Level)
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("%w: margin %d", ErrInvalidOption, o.Margin)
+	}
+	return nil
+}
+
+// ContentType returns the MIME type of the rendered format
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ParseColor parses a hex color in the form RGB, RRGGBB or RRGGBBAA with an optional leading #
+func ParseColor(value string) (color.RGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	if len(value) == 6 {
+		value += "ff"
+	}
+	if len(value) != 8 {
+		return color.RGBA{}, fmt.Errorf("%w: color %q", ErrInvalidOption, value)
+	}
+
+	rgba, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("%w: color %q", ErrInvalidOption, value)
+	}
+	return color.RGBA{
+		R: uint8(rgba >> 24),
+		G: uint8(rgba >> 16),
+		B: uint8(rgba >> 8),
+		A: uint8(rgba),
+	}, nil
+}
+
+// Write encodes content as a QR code and renders it to w
+func Write(w io.Writer, content string, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	code, err := qrcode.New(content, levels[opts.Level])
+	if err != nil {
+		return err
+	}
+	// The quiet zone is drawn by us so the margin is configurable
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	if opts.Format == FormatSVG {
+		return writeSVG(w, modules, opts)
+	}
+	return writePNG(w, modules, opts)
+}
+
+func writePNG(w io.Writer, modules [][]bool, opts Options) error {
+	total := len(modules) + 2*opts.Margin
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+
+	// Map every pixel back to the module it covers so any size is rendered evenly
+	for y := 0; y < opts.Size; y++ {
+		row := y*total/opts.Size - opts.Margin
+		if row < 0 || row >= len(modules) {
+			continue
+		}
+		for x := 0; x < opts.Size; x++ {
+			col := x*total/opts.Size - opts.Margin
+			if col >= 0 && col < len(modules) && modules[row][col] {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+func writeSVG(w io.Writer, modules [][]bool, opts Options) error {
+	total := len(modules) + 2*opts.Margin
+
+	var path strings.Builder
+	for y, row := range modules {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			// Merge horizontal runs of dark modules into one rectangle
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+opts.Margin, y+opts.Margin, x-start, x-start)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">
+<rect width="100%%" height="100%%" fill="%s" fill-opacity="%s"/>
+<path d="%s" fill="%s" fill-opacity="%s"/>
+</svg>
+`, opts.Size, opts.Size, total, total,
+		hexColor(opts.Background), opacity(opts.Background),
+		path.String(), hexColor(opts.Foreground), opacity(opts.Foreground))
+	return err
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func opacity(c color.RGBA) string {
+	return strconv.FormatFloat(float64(c.A)/0xff, 'f', 2, 64)
+}
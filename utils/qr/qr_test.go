@@ -0,0 +1,68 @@
+package qr_test
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/qr"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    color.RGBA
+		wantErr bool
+	}{
+		{name: "short form", value: "f00", want: color.RGBA{R: 0xff, A: 0xff}},
+		{name: "long form with hash", value: "#336699", want: color.RGBA{R: 0x33, G: 0x66, B: 0x99, A: 0xff}},
+		{name: "with alpha", value: "00000080", want: color.RGBA{A: 0x80}},
+		{name: "invalid length", value: "12345", wantErr: true},
+		{name: "invalid hex", value: "zzzzzz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qr.ParseColor(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Fatalf("ParseColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	opts := qr.DefaultOptions()
+	opts.Size = 300
+
+	var buf bytes.Buffer
+	if err := qr.Write(&buf, "https://example.com/url/00001", opts); err != nil {
+		t.Fatalf("Write() png error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if img.Bounds().Dx() != 300 || img.Bounds().Dy() != 300 {
+		t.Fatalf("png size = %v, want 300x300", img.Bounds())
+	}
+
+	opts.Format = qr.FormatSVG
+	buf.Reset()
+	if err := qr.Write(&buf, "https://example.com/url/00001", opts); err != nil {
+		t.Fatalf("Write() svg error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `width="300"`) || !strings.Contains(buf.String(), "<path d=\"M4 4h7") {
+		t.Fatalf("unexpected svg output: %s", buf.String())
+	}
+
+	opts.Margin = qr.MaxMargin + 1
+	if err := qr.Write(&buf, "https://example.com/url/00001", opts); err == nil {
+		t.Fatalf("Write() with invalid margin error = nil, want error")
+	}
+}
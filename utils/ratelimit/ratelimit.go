@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// FailureLimiter blocks a key after too many failures within a window
+type FailureLimiter struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	now         func() time.Time
+	failures    map[string]*failureEntry
+	lastSweep   time.Time
+}
+
+type failureEntry struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewFailureLimiter(maxFailures int, window time.Duration) *FailureLimiter {
+	return &FailureLimiter{
+		maxFailures: maxFailures,
+		window:      window,
+		now:         time.Now,
+		failures:    make(map[string]*failureEntry),
+	}
+}
+
+// Allowed checks if the key may try again
+func (l *FailureLimiter) Allowed(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := l.entry(key)
+	return entry == nil || entry.count < l.maxFailures
+}
+
+// Fail records a failure of the key
+func (l *FailureLimiter) Fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := l.entry(key)
+	if entry == nil {
+		entry = &failureEntry{resetAt: l.now().Add(l.window)}
+		l.failures[key] = entry
+	}
+	entry.count++
+}
+
+// Reset forgets the failures of the key
+func (l *FailureLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
+
+// entry returns the active entry of key, expired entries are dropped once per window
+func (l *FailureLimiter) entry(key string) *failureEntry {
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, entry := range l.failures {
+			if !now.Before(entry.resetAt) {
+				delete(l.failures, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	entry := l.failures[key]
+	if entry != nil && !now.Before(entry.resetAt) {
+		delete(l.failures, key)
+		return nil
+	}
+	return entry
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter(maxFailures int, window time.Duration) (*FailureLimiter, *time.Time) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiter := NewFailureLimiter(maxFailures, window)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestFailureLimiter_Window(t *testing.T) {
+	limiter, now := newTestLimiter(2, time.Minute)
+
+	limiter.Fail("10.0.0.1")
+	if !limiter.Allowed("10.0.0.1") {
+		t.Fatalf("Allowed() = false after 1 of 2 failures")
+	}
+	limiter.Fail("10.0.0.1")
+	if limiter.Allowed("10.0.0.1") {
+		t.Fatalf("Allowed() = true after 2 of 2 failures")
+	}
+	if !limiter.Allowed("10.0.0.2") {
+		t.Fatalf("Allowed() = false for another key")
+	}
+
+	// the window starts at the first failure, later failures don't extend it
+	*now = now.Add(59 * time.Second)
+	limiter.Fail("10.0.0.1")
+	if limiter.Allowed("10.0.0.1") {
+		t.Fatalf("Allowed() = true before the window is over")
+	}
+	*now = now.Add(time.Second)
+	if !limiter.Allowed("10.0.0.1") {
+		t.Fatalf("Allowed() = false once the window is over")
+	}
+
+	// a new window starts with the next failure
+	limiter.Fail("10.0.0.1")
+	if !limiter.Allowed("10.0.0.1") {
+		t.Fatalf("Allowed() = false after 1 failure of a new window")
+	}
+}
+
+func TestFailureLimiter_Reset(t *testing.T) {
+	limiter, _ := newTestLimiter(1, time.Minute)
+
+	limiter.Fail("10.0.0.1")
+	limiter.Fail("10.0.0.2")
+	limiter.Reset("10.0.0.1")
+
+	if !limiter.Allowed("10.0.0.1") {
+		t.Fatalf("Allowed() = false after Reset")
+	}
+	if limiter.Allowed("10.0.0.2") {
+		t.Fatalf("Allowed() = true for a key that was not reset")
+	}
+}
+
+func TestFailureLimiter_Sweep(t *testing.T) {
+	limiter, now := newTestLimiter(1, time.Minute)
+
+	limiter.Fail("10.0.0.1")
+	limiter.Fail("10.0.0.2")
+	*now = now.Add(time.Minute)
+
+	// looking up any key drops the expired entries of the others
+	limiter.Allowed("10.0.0.3")
+	if len(limiter.failures) != 0 {
+		t.Fatalf("failures = %v, want the expired entries dropped", limiter.failures)
+	}
+}
@@ -0,0 +1,75 @@
+// Package sqldb runs the queries of the SQL repositories, written for MySQL with ?
+// placeholders, on the other supported databases. Queries go through Rebind and inserts
+// through InsertID, Postgres having no LastInsertId. It also registers the
+// SQLite driver with a NOW() function like the one of MySQL and Postgres.
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/muhammadheryan/url-shortner-base62/constant"
+	"modernc.org/sqlite"
+)
+
+// SQLiteTimeFormat is how times are written to SQLite (the _time_format=sqlite of the
+// driver). SQLite keeps them as text compared as strings, so they must all be in UTC,
+// the repositories pass their time arguments through Args.
+const SQLiteTimeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+func init() {
+	sqlite.MustRegisterScalarFunction("now", 0, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Format(SQLiteTimeFormat), nil
+	})
+}
+
+// IsPostgres tells if conn talks to Postgres
+func IsPostgres(conn sqlx.ExtContext) bool {
+	return conn.DriverName() == constant.DriverPostgres
+}
+
+// IsSQLite tells if conn talks to SQLite
+func IsSQLite(conn sqlx.ExtContext) bool {
+	return conn.DriverName() == constant.DriverSQLite
+}
+
+// Args puts the times of args in UTC when conn talks to SQLite, which compares times as
+// text, so the times of the process compare with the ones written by NOW()
+func Args(conn sqlx.ExtContext, args ...any) []any {
+	if !IsSQLite(conn) {
+		return args
+	}
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case time.Time:
+			args[i] = value.UTC()
+		case *time.Time:
+			if value != nil {
+				utc := value.UTC()
+				args[i] = &utc
+			}
+		}
+	}
+	return args
+}
+
+// InsertID runs the insert of one row and returns its id
+func InsertID(ctx context.Context, conn sqlx.ExtContext, query string, args ...any) (uint64, error) {
+	if IsPostgres(conn) {
+		var id uint64
+		err := conn.QueryRowxContext(ctx, conn.Rebind(query+" RETURNING id"), args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := conn.ExecContext(ctx, conn.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
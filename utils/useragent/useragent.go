@@ -0,0 +1,96 @@
+package useragent
+
+import "strings"
+
+// linkPreviewBots are user agent fragments of crawlers that unfurl links shared in chats and social networks
+var linkPreviewBots = []string{
+	"slackbot",
+	"slack-imgproxy",
+	"facebookexternalhit",
+	"facebot",
+	"twitterbot",
+	"whatsapp",
+	"telegrambot",
+	"discordbot",
+	"linkedinbot",
+	"skypeuripreview",
+	"microsoftpreview",
+	"teamsbot",
+	"applebot",
+	"pinterestbot",
+	"redditbot",
+	"embedly",
+	"vkshare",
+	"googlebot",
+	"bingbot",
+	"bingpreview",
+}
+
+// IsLinkPreviewBot checks if the user agent belongs to a link preview crawler.
+// iMessage previews identify themselves as facebookexternalhit and Twitterbot.
+func IsLinkPreviewBot(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, bot := range linkPreviewBots {
+		if strings.Contains(userAgent, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// Device types
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// Operating systems
+const (
+	OSIOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+	OSOther    = "other"
+)
+
+// Info is what can be told about a client from its user agent
+type Info struct {
+	DeviceType string
+	OS         string
+}
+
+// Parse detects the device type and operating system of a user agent
+func Parse(userAgent string) Info {
+	ua := strings.ToLower(userAgent)
+
+	info := Info{DeviceType: DeviceDesktop, OS: OSOther}
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipod"):
+		info.OS, info.DeviceType = OSIOS, DeviceMobile
+	case strings.Contains(ua, "ipad"):
+		info.OS, info.DeviceType = OSIOS, DeviceTablet
+	case strings.Contains(ua, "android"):
+		info.OS, info.DeviceType = OSAndroid, DeviceTablet
+		if strings.Contains(ua, "mobile") {
+			info.DeviceType = DeviceMobile
+		}
+	case strings.Contains(ua, "windows"):
+		info.OS = OSWindows
+	case strings.Contains(ua, "cros"):
+		info.OS = OSChromeOS
+	case strings.Contains(ua, "mac os x"), strings.Contains(ua, "macintosh"):
+		info.OS = OSMacOS
+	case strings.Contains(ua, "linux"):
+		info.OS = OSLinux
+	}
+
+	if IsLinkPreviewBot(userAgent) || strings.Contains(ua, "bot") || strings.Contains(ua, "spider") || strings.Contains(ua, "crawler") {
+		info.DeviceType = DeviceBot
+	}
+
+	return info
+}
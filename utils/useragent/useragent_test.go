@@ -0,0 +1,99 @@
+package useragent_test
+
+import (
+	"testing"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/useragent"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      useragent.Info
+	}{
+		{
+			name:      "iphone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			want:      useragent.Info{DeviceType: useragent.DeviceMobile, OS: useragent.OSIOS},
+		},
+		{
+			name:      "ipad",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1",
+			want:      useragent.Info{DeviceType: useragent.DeviceTablet, OS: useragent.OSIOS},
+		},
+		{
+			name:      "android phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36",
+			want:      useragent.Info{DeviceType: useragent.DeviceMobile, OS: useragent.OSAndroid},
+		},
+		{
+			name:      "android tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+			want:      useragent.Info{DeviceType: useragent.DeviceTablet, OS: useragent.OSAndroid},
+		},
+		{
+			name:      "windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+			want:      useragent.Info{DeviceType: useragent.DeviceDesktop, OS: useragent.OSWindows},
+		},
+		{
+			name:      "macos",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15",
+			want:      useragent.Info{DeviceType: useragent.DeviceDesktop, OS: useragent.OSMacOS},
+		},
+		{
+			name:      "chromebook",
+			userAgent: "Mozilla/5.0 (X11; CrOS x86_64 15633.0.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+			want:      useragent.Info{DeviceType: useragent.DeviceDesktop, OS: useragent.OSChromeOS},
+		},
+		{
+			name:      "linux",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			want:      useragent.Info{DeviceType: useragent.DeviceDesktop, OS: useragent.OSLinux},
+		},
+		{
+			name:      "link preview bot",
+			userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			want:      useragent.Info{DeviceType: useragent.DeviceBot, OS: useragent.OSOther},
+		},
+		{
+			name:      "crawler on android",
+			userAgent: "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36 (compatible; Googlebot/2.1)",
+			want:      useragent.Info{DeviceType: useragent.DeviceBot, OS: useragent.OSAndroid},
+		},
+		{
+			name:      "empty",
+			userAgent: "",
+			want:      useragent.Info{DeviceType: useragent.DeviceDesktop, OS: useragent.OSOther},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := useragent.Parse(tt.userAgent); got != tt.want {
+				t.Fatalf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLinkPreviewBot(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      bool
+	}{
+		{userAgent: "facebookexternalhit/1.1 Facebot Twitterbot/1.0", want: true},
+		{userAgent: "WhatsApp/2.23.20.0", want: true},
+		{userAgent: "TelegramBot (like TwitterBot)", want: true},
+		{userAgent: "Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)", want: true},
+		{userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0 Safari/537.36", want: false},
+		{userAgent: "curl/8.4.0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			if got := useragent.IsLinkPreviewBot(tt.userAgent); got != tt.want {
+				t.Fatalf("IsLinkPreviewBot(%q) = %v, want %v", tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+)
+
+// Headers of every webhook request, receivers check the signature against
+// the timestamp and the raw body before trusting the payload
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the algorithm so it can change without breaking receivers
+const signaturePrefix = "sha256="
+
+// maxErrorBodySize limits how much of a failed response is kept as the delivery error
+const maxErrorBodySize = 256
+
+// Message is one signed request to a webhook endpoint
+type Message struct {
+	URL     string
+	Secret  string
+	ID      string
+	Event   string
+	Payload []byte
+}
+
+// Sender posts messages to webhook endpoints
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type HTTPSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a sender refusing endpoints on reserved addresses unless allowPrivate
+// is set for local development. Redirects are not followed, a redirected delivery fails
+// so the signed payload never reaches a host the endpoint was not registered with.
+func NewSender(timeout time.Duration, allowPrivate bool) Sender {
+	client := netguard.NewClient(timeout, allowPrivate)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &HTTPSender{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Send posts the payload, any status outside 2xx is an error
+func (s *HTTPSender) Send(ctx context.Context, msg *Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "url-shortner-webhook")
+	req.Header.Set(HeaderEvent, msg.Event)
+	req.Header.Set(HeaderID, msg.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(msg.Secret, timestamp, msg.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+// Sign returns the HMAC-SHA256 of "timestamp.body" keyed with the secret of the webhook
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a received webhook in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,110 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/muhammadheryan/url-shortner-base62/utils/netguard"
+	"github.com/muhammadheryan/url-shortner-base62/utils/webhook"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusOK},
+		{name: "accepted without content", status: http.StatusNoContent},
+		{name: "redirected", status: http.StatusFound, wantErr: true},
+		{name: "receiver error", status: http.StatusInternalServerError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var verified bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+				verified = webhook.Verify("s3cret", timestamp, body, r.Header.Get(webhook.HeaderSignature)) &&
+					r.Header.Get(webhook.HeaderEvent) == "link.created" &&
+					r.Header.Get(webhook.HeaderID) == "evt-1"
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := webhook.NewSender(time.Second, true).Send(context.Background(), &webhook.Message{
+				URL:     server.URL,
+				Secret:  "s3cret",
+				ID:      "evt-1",
+				Event:   "link.created",
+				Payload: []byte(`{"event":"link.created"}`),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !verified {
+				t.Fatalf("Send() request was not signed with the secret")
+			}
+		})
+	}
+}
+
+func TestHTTPSender_Send_Redirect(t *testing.T) {
+	var followed bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	err := webhook.NewSender(time.Second, true).Send(context.Background(), &webhook.Message{URL: server.URL, Secret: "s3cret", Payload: []byte(`{}`)})
+	if err == nil {
+		t.Fatalf("Send() error = nil, want the redirect status")
+	}
+	if followed {
+		t.Fatalf("Send() followed the redirect with the signed payload")
+	}
+}
+
+func TestHTTPSender_Send_ReservedAddress(t *testing.T) {
+	var received bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer server.Close()
+
+	err := webhook.NewSender(time.Second, false).Send(context.Background(), &webhook.Message{URL: server.URL, Secret: "s3cret", Payload: []byte(`{}`)})
+	if !errors.Is(err, netguard.ErrReservedAddress) {
+		t.Fatalf("Send() error = %v, want ErrReservedAddress", err)
+	}
+	if received {
+		t.Fatalf("Send() reached the loopback receiver")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"link.clicked"}`)
+	signature := webhook.Sign("s3cret", 1700000000, body)
+
+	if !webhook.Verify("s3cret", 1700000000, body, signature) {
+		t.Fatalf("Verify() = false for the signed body")
+	}
+	if webhook.Verify("other", 1700000000, body, signature) {
+		t.Fatalf("Verify() = true with another secret")
+	}
+	if webhook.Verify("s3cret", 1700000001, body, signature) {
+		t.Fatalf("Verify() = true with another timestamp")
+	}
+	if webhook.Verify("s3cret", 1700000000, []byte(`{"event":"link.deleted"}`), signature) {
+		t.Fatalf("Verify() = true with another body")
+	}
+}